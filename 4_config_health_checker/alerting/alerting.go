@@ -0,0 +1,287 @@
+package alerting
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/friofry/config-health-checker/checker"
+	requestsrunner "github.com/friofry/config-health-checker/requests-runner"
+	"github.com/friofry/config-health-checker/rpcprovider"
+)
+
+// EventType defines the condition an alert is raised for
+type EventType string
+
+const (
+	EventProviderEjected  EventType = "provider_ejected"          // Provider failed validation and was removed from the output
+	EventChainBelowMin    EventType = "chain_below_min_providers" // Chain has fewer valid providers than required
+	EventReferenceFailing EventType = "reference_failing"         // Reference provider of a chain failed, its providers cannot be validated
+	EventCycleOverrun     EventType = "cycle_overrun"             // Validation run took longer than the check interval
+)
+
+// ParseEventType converts a configuration value into an EventType
+func ParseEventType(value string) (EventType, error) {
+	switch eventType := EventType(value); eventType {
+	case EventProviderEjected, EventChainBelowMin, EventReferenceFailing, EventCycleOverrun:
+		return eventType, nil
+	default:
+		return "", fmt.Errorf("unknown alert event %q", value)
+	}
+}
+
+// Severity of an alert, matching the PagerDuty Events v2 severities
+type Severity string
+
+const (
+	SeverityCritical Severity = "critical"
+	SeverityWarning  Severity = "warning"
+)
+
+// Event is an alert raised when a condition starts, or resolved when it ends
+type Event struct {
+	Type     EventType `json:"type"`
+	Severity Severity  `json:"severity"`
+	Resolved bool      `json:"resolved"` // The condition ended, e.g. an ejected provider was restored
+	Time     time.Time `json:"time"`
+	Key      string    `json:"key"`                // Identifies the condition, used for deduplication
+	ChainID  int64     `json:"chainId,omitempty"`  // Chain of the condition, if any
+	Chain    string    `json:"chain,omitempty"`    // "name/network" of the chain
+	Provider string    `json:"provider,omitempty"` // Provider of the condition, if any
+	Summary  string    `json:"summary"`            // Human-readable description
+	Reason   string    `json:"reason,omitempty"`   // Failed methods or other details
+}
+
+// Notifier delivers events to an alerting target
+type Notifier interface {
+	Notify(ctx context.Context, event Event) error
+}
+
+// Target is a notifier with the event types it receives
+type Target struct {
+	Name     string             // Used in logs
+	Notifier Notifier           // Delivers the events
+	Events   map[EventType]bool // Event types to send, empty means all
+}
+
+// accepts reports whether the target receives events of the given type
+func (t Target) accepts(eventType EventType) bool {
+	return len(t.Events) == 0 || t.Events[eventType]
+}
+
+// Default alerting settings
+const (
+	DefaultMinValidProviders = 1
+	DefaultCooldown          = 10 * time.Minute
+)
+
+// Config contains the alerting thresholds
+type Config struct {
+	MinValidProviders int           // Alert when a chain has fewer valid providers, zero means DefaultMinValidProviders
+	Cooldown          time.Duration // Minimum time between two alerts for the same condition, zero means DefaultCooldown
+	Interval          time.Duration // Check interval, a longer run raises EventCycleOverrun; zero disables the check
+}
+
+// Alerter raises and resolves alerts from the results of validation runs.
+// Alerts are only sent when a condition starts or ends; a condition that starts again
+// within the cooldown of its previous alert is reported once the cooldown has passed.
+type Alerter struct {
+	mu          sync.Mutex
+	config      Config
+	targets     []Target
+	open        map[string]Event     // Sent and unresolved alerts by key
+	lastTrigger map[string]time.Time // Time the last alert of a condition was sent
+	logger      *slog.Logger
+	now         func() time.Time
+}
+
+// New creates an alerter sending events to the targets
+func New(config Config, targets []Target) *Alerter {
+	if config.MinValidProviders <= 0 {
+		config.MinValidProviders = DefaultMinValidProviders
+	}
+	if config.Cooldown <= 0 {
+		config.Cooldown = DefaultCooldown
+	}
+	return &Alerter{
+		config:      config,
+		targets:     targets,
+		open:        make(map[string]Event),
+		lastTrigger: make(map[string]time.Time),
+		logger:      slog.Default(),
+		now:         time.Now,
+	}
+}
+
+// ObserveCycle implements checker.CycleObserver
+func (a *Alerter) ObserveCycle(ctx context.Context, cycle checker.CycleResult) {
+	for _, event := range a.Evaluate(cycle) {
+		a.send(ctx, event)
+	}
+}
+
+// Evaluate returns the events to send for a validation run and updates the open alerts
+func (a *Alerter) Evaluate(cycle checker.CycleResult) []Event {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	now := a.now()
+	active, unjudged := a.conditions(cycle)
+
+	var events []Event
+	for key, event := range active {
+		if _, isOpen := a.open[key]; isOpen {
+			continue
+		}
+		if last, sent := a.lastTrigger[key]; sent && now.Sub(last) < a.config.Cooldown {
+			continue
+		}
+		event.Key = key
+		event.Time = now
+		a.open[key] = event
+		a.lastTrigger[key] = now
+		events = append(events, event)
+	}
+	for key, resolved := range a.open {
+		if _, isActive := active[key]; isActive || unjudged[key] {
+			continue
+		}
+		// A run of a single chain only judges the conditions of that chain
+		if cycle.ChainID != 0 && resolved.ChainID != cycle.ChainID {
+			continue
+		}
+		resolved.Resolved = true
+		resolved.Time = now
+		resolved.Summary = "resolved: " + resolved.Summary
+		resolved.Reason = ""
+		delete(a.open, key)
+		events = append(events, resolved)
+	}
+
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].Key < events[j].Key
+	})
+	return events
+}
+
+// conditions returns the events of the conditions present in a validation run by key, and the keys
+// of conditions that cannot be judged because the reference of their chain failed
+func (a *Alerter) conditions(cycle checker.CycleResult) (map[string]Event, map[string]bool) {
+	active := make(map[string]Event)
+	unjudged := make(map[string]bool)
+
+	for _, chain := range cycle.Chains {
+		enabled := rpcprovider.EnabledProviders(chain.Chain.Providers)
+		// Chains without reference are reported by lint, chains without enabled providers are off on purpose
+		if !chain.Checked() || len(enabled) == 0 {
+			continue
+		}
+		chainID := int64(chain.Chain.ChainId)
+		label := fmt.Sprintf("%s/%s (%d)", chain.Chain.Name, chain.Chain.Network, chainID)
+		base := Event{ChainID: chainID, Chain: chain.Chain.Name + "/" + chain.Chain.Network}
+
+		if referenceFailing(chain) {
+			event := base
+			event.Type = EventReferenceFailing
+			event.Severity = SeverityWarning
+			event.Summary = fmt.Sprintf("reference provider %s of %s is failing", chain.Reference.Provider.Name, label)
+			active[fmt.Sprintf("reference/%d", chainID)] = event
+		}
+
+		valid := make(map[string]bool, len(chain.ValidProviders))
+		for _, provider := range chain.ValidProviders {
+			valid[provider.Name] = true
+		}
+		for _, provider := range enabled {
+			if valid[provider.Name] {
+				continue
+			}
+			key := fmt.Sprintf("provider/%d/%s", chainID, provider.Name)
+			result := chain.Providers[provider.Name]
+			if onlyReferenceFailures(result) {
+				unjudged[key] = true
+				continue
+			}
+			event := base
+			event.Type = EventProviderEjected
+			event.Severity = SeverityWarning
+			event.Provider = provider.Name
+			event.Summary = fmt.Sprintf("provider %s of %s ejected", provider.Name, label)
+			event.Reason = failureReason(result)
+			active[key] = event
+		}
+
+		if len(valid) < a.config.MinValidProviders {
+			event := base
+			event.Type = EventChainBelowMin
+			event.Severity = SeverityWarning
+			if len(valid) == 0 {
+				event.Severity = SeverityCritical
+			}
+			event.Summary = fmt.Sprintf("%s has %d valid providers, at least %d required",
+				label, len(valid), a.config.MinValidProviders)
+			active[fmt.Sprintf("chain/%d", chainID)] = event
+		}
+	}
+
+	if a.config.Interval > 0 && cycle.ChainID == 0 && cycle.Duration > a.config.Interval {
+		active["cycle"] = Event{
+			Type:     EventCycleOverrun,
+			Severity: SeverityWarning,
+			Summary: fmt.Sprintf("validation run took %s, longer than the %s interval",
+				cycle.Duration.Round(time.Millisecond), a.config.Interval),
+		}
+	}
+	return active, unjudged
+}
+
+// referenceFailing reports whether any method of the chain could not be validated because the reference failed
+func referenceFailing(chain checker.ChainResult) bool {
+	for _, result := range chain.Providers {
+		for _, failed := range result.FailedMethods {
+			if failed.ErrorClass() == requestsrunner.ErrorClassReferenceFailure {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// onlyReferenceFailures reports whether a provider failed only because the reference failed
+func onlyReferenceFailures(result checker.ProviderValidationResult) bool {
+	if len(result.FailedMethods) == 0 {
+		return false
+	}
+	for _, failed := range result.FailedMethods {
+		if failed.ErrorClass() != requestsrunner.ErrorClassReferenceFailure {
+			return false
+		}
+	}
+	return true
+}
+
+// failureReason lists the failed methods of a provider with their error classes
+func failureReason(result checker.ProviderValidationResult) string {
+	reasons := make([]string, 0, len(result.FailedMethods))
+	for method, failed := range result.FailedMethods {
+		reasons = append(reasons, fmt.Sprintf("%s (%s)", method, failed.ErrorClass()))
+	}
+	sort.Strings(reasons)
+	return strings.Join(reasons, ", ")
+}
+
+// send delivers an event to every target accepting it, failures are logged
+func (a *Alerter) send(ctx context.Context, event Event) {
+	for _, target := range a.targets {
+		if !target.accepts(event.Type) {
+			continue
+		}
+		if err := target.Notifier.Notify(ctx, event); err != nil {
+			a.logger.Error("failed to send alert", "target", target.Name, "key", event.Key, "error", err)
+		}
+	}
+}
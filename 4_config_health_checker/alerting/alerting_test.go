@@ -0,0 +1,180 @@
+package alerting
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/friofry/config-health-checker/chainconfig"
+	"github.com/friofry/config-health-checker/checker"
+	requestsrunner "github.com/friofry/config-health-checker/requests-runner"
+	"github.com/friofry/config-health-checker/rpcprovider"
+)
+
+// recordingNotifier stores the events it receives
+type recordingNotifier struct {
+	events []Event
+}
+
+func (n *recordingNotifier) Notify(ctx context.Context, event Event) error {
+	n.events = append(n.events, event)
+	return nil
+}
+
+// failed returns a validation result failing a method with the given error class
+func failed(class requestsrunner.ErrorClass) checker.ProviderValidationResult {
+	return checker.ProviderValidationResult{FailedMethods: map[string]checker.FailedMethodResult{
+		"eth_blockNumber": {Error: &requestsrunner.ProviderError{Class: class, Err: errors.New(string(class))}},
+	}}
+}
+
+// cycleWith returns a run of a chain with providers a and b and their validation results
+func cycleWith(results map[string]checker.ProviderValidationResult) checker.CycleResult {
+	chain := checker.ChainResult{
+		Chain: chainconfig.ChainConfig{
+			Name: "ethereum", Network: "mainnet", ChainId: 1,
+			Providers: []rpcprovider.RpcProvider{{Name: "a"}, {Name: "b"}},
+		},
+		Reference: &chainconfig.ReferenceChainConfig{ChainId: 1, Provider: rpcprovider.RpcProvider{Name: "reference"}},
+		Providers: results,
+	}
+	for _, provider := range chain.Chain.Providers {
+		if results[provider.Name].Valid {
+			chain.ValidProviders = append(chain.ValidProviders, provider)
+		}
+	}
+	return checker.CycleResult{Chains: []checker.ChainResult{chain}, Duration: time.Second}
+}
+
+// newTestAlerter returns an alerter with a clock that can be advanced
+func newTestAlerter(config Config) (*Alerter, *time.Time) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	alerter := New(config, nil)
+	alerter.now = func() time.Time { return now }
+	return alerter, &now
+}
+
+func keys(events []Event) []string {
+	var result []string
+	for _, event := range events {
+		prefix := ""
+		if event.Resolved {
+			prefix = "resolved "
+		}
+		result = append(result, prefix+event.Key)
+	}
+	return result
+}
+
+func TestEvaluateProviderEjectedAndRestored(t *testing.T) {
+	alerter, now := newTestAlerter(Config{MinValidProviders: 2, Cooldown: time.Hour})
+	healthy := map[string]checker.ProviderValidationResult{"a": {Valid: true}, "b": {Valid: true}}
+	ejected := map[string]checker.ProviderValidationResult{"a": {Valid: true}, "b": failed(requestsrunner.ErrorClassResultMismatch)}
+
+	assert.Empty(t, alerter.Evaluate(cycleWith(healthy)))
+
+	events := alerter.Evaluate(cycleWith(ejected))
+	assert.Equal(t, []string{"chain/1", "provider/1/b"}, keys(events))
+	assert.Equal(t, SeverityWarning, events[0].Severity)
+	assert.Equal(t, "ethereum/mainnet (1) has 1 valid providers, at least 2 required", events[0].Summary)
+	assert.Equal(t, "provider b of ethereum/mainnet (1) ejected", events[1].Summary)
+	assert.Equal(t, "eth_blockNumber (result_mismatch)", events[1].Reason)
+
+	// Conditions that persist are not sent again
+	assert.Empty(t, alerter.Evaluate(cycleWith(ejected)))
+
+	*now = now.Add(time.Minute)
+	events = alerter.Evaluate(cycleWith(healthy))
+	assert.Equal(t, []string{"resolved chain/1", "resolved provider/1/b"}, keys(events))
+	assert.Equal(t, "resolved: provider b of ethereum/mainnet (1) ejected", events[1].Summary)
+
+	// A flapping provider is reported again only after the cooldown
+	*now = now.Add(time.Minute)
+	assert.Empty(t, alerter.Evaluate(cycleWith(ejected)))
+	*now = now.Add(time.Hour)
+	assert.Equal(t, []string{"chain/1", "provider/1/b"}, keys(alerter.Evaluate(cycleWith(ejected))))
+}
+
+func TestEvaluateChainWithoutValidProviders(t *testing.T) {
+	alerter, _ := newTestAlerter(Config{})
+	events := alerter.Evaluate(cycleWith(map[string]checker.ProviderValidationResult{
+		"a": failed(requestsrunner.ErrorClassTimeout),
+		"b": failed(requestsrunner.ErrorClassServerError),
+	}))
+	assert.Equal(t, []string{"chain/1", "provider/1/a", "provider/1/b"}, keys(events))
+	assert.Equal(t, SeverityCritical, events[0].Severity)
+}
+
+func TestEvaluateReferenceFailing(t *testing.T) {
+	alerter, _ := newTestAlerter(Config{})
+	alerter.Evaluate(cycleWith(map[string]checker.ProviderValidationResult{
+		"a": {Valid: true},
+		"b": failed(requestsrunner.ErrorClassTimeout),
+	}))
+
+	// Providers cannot be judged while the reference fails: open alerts stay open, no new ones are raised
+	events := alerter.Evaluate(cycleWith(map[string]checker.ProviderValidationResult{
+		"a": failed(requestsrunner.ErrorClassReferenceFailure),
+		"b": failed(requestsrunner.ErrorClassReferenceFailure),
+	}))
+	assert.Equal(t, []string{"chain/1", "reference/1"}, keys(events))
+	assert.Equal(t, "reference provider reference of ethereum/mainnet (1) is failing", events[1].Summary)
+
+	events = alerter.Evaluate(cycleWith(map[string]checker.ProviderValidationResult{
+		"a": {Valid: true},
+		"b": {Valid: true},
+	}))
+	assert.Equal(t, []string{"resolved chain/1", "resolved provider/1/b", "resolved reference/1"}, keys(events))
+}
+
+func TestEvaluateCycleOverrun(t *testing.T) {
+	alerter, _ := newTestAlerter(Config{Interval: 30 * time.Second})
+	healthy := cycleWith(map[string]checker.ProviderValidationResult{"a": {Valid: true}, "b": {Valid: true}})
+
+	slow := healthy
+	slow.Duration = 45 * time.Second
+	events := alerter.Evaluate(slow)
+	require.Equal(t, []string{"cycle"}, keys(events))
+	assert.Equal(t, EventCycleOverrun, events[0].Type)
+	assert.Equal(t, "validation run took 45s, longer than the 30s interval", events[0].Summary)
+
+	assert.Equal(t, []string{"resolved cycle"}, keys(alerter.Evaluate(healthy)))
+}
+
+func TestObserveCycleFiltersTargets(t *testing.T) {
+	all := &recordingNotifier{}
+	chainsOnly := &recordingNotifier{}
+	alerter := New(Config{}, []Target{
+		{Name: "all", Notifier: all},
+		{Name: "chains", Notifier: chainsOnly, Events: map[EventType]bool{EventChainBelowMin: true}},
+	})
+
+	alerter.ObserveCycle(context.Background(), cycleWith(map[string]checker.ProviderValidationResult{
+		"a": failed(requestsrunner.ErrorClassTimeout),
+		"b": failed(requestsrunner.ErrorClassTimeout),
+	}))
+	assert.Len(t, all.events, 3)
+	assert.Equal(t, []string{"chain/1"}, keys(chainsOnly.events))
+}
+
+func TestEvaluateSingleChainRun(t *testing.T) {
+	alerter, _ := newTestAlerter(Config{Interval: 30 * time.Second})
+	failing := cycleWith(map[string]checker.ProviderValidationResult{
+		"a": failed(requestsrunner.ErrorClassTimeout),
+		"b": {Valid: true},
+	})
+	failing.Duration = time.Minute
+	assert.Equal(t, []string{"cycle", "provider/1/a"}, keys(alerter.Evaluate(failing)))
+
+	// A run of another chain neither resolves the alerts of chain 1 nor the overrun of the full run
+	other := checker.CycleResult{ChainID: 10, Duration: time.Second}
+	assert.Empty(t, alerter.Evaluate(other))
+
+	healthy := cycleWith(map[string]checker.ProviderValidationResult{"a": {Valid: true}, "b": {Valid: true}})
+	healthy.ChainID = 1
+	assert.Equal(t, []string{"resolved provider/1/a"}, keys(alerter.Evaluate(healthy)))
+}
@@ -0,0 +1,96 @@
+package alerting
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/friofry/config-health-checker/configreader"
+	"github.com/friofry/config-health-checker/rpcprovider"
+)
+
+// Target types of the alerting configuration
+const (
+	TargetWebhook   = "webhook"
+	TargetSlack     = "slack"
+	TargetPagerDuty = "pagerduty"
+)
+
+// FromConfig creates an alerter from the alerting section of the checker configuration.
+// Secret references in URLs, headers and routing keys are resolved.
+func FromConfig(cfg configreader.CheckerConfig) (*Alerter, error) {
+	alertingCfg := cfg.Alerting
+	source := alertingCfg.Source
+	if source == "" {
+		source, _ = os.Hostname()
+	}
+
+	targets := make([]Target, 0, len(alertingCfg.Targets))
+	for i, targetCfg := range alertingCfg.Targets {
+		target, err := targetFromConfig(targetCfg, source)
+		if err != nil {
+			return nil, fmt.Errorf("alerting target %d (%s): %w", i, targetCfg.Type, err)
+		}
+		targets = append(targets, target)
+	}
+
+	return New(Config{
+		MinValidProviders: alertingCfg.MinValidProviders,
+		Cooldown:          time.Duration(alertingCfg.CooldownSeconds) * time.Second,
+		Interval:          time.Duration(cfg.IntervalSeconds) * time.Second,
+	}, targets), nil
+}
+
+// targetFromConfig creates the notifier of a single target
+func targetFromConfig(cfg configreader.AlertTargetConfig, source string) (Target, error) {
+	target := Target{Name: cfg.Type, Events: make(map[EventType]bool, len(cfg.Events))}
+	for _, value := range cfg.Events {
+		eventType, err := ParseEventType(value)
+		if err != nil {
+			return Target{}, err
+		}
+		target.Events[eventType] = true
+	}
+
+	url, err := rpcprovider.ResolveSecret(cfg.URL)
+	if err != nil {
+		return Target{}, fmt.Errorf("failed to resolve url: %w", err)
+	}
+
+	switch cfg.Type {
+	case TargetWebhook:
+		if url == "" {
+			return Target{}, errors.New("url is required")
+		}
+		notifier := &WebhookNotifier{URL: url, Headers: make(map[string]string, len(cfg.Headers))}
+		for name, value := range cfg.Headers {
+			if notifier.Headers[name], err = rpcprovider.ResolveSecret(value); err != nil {
+				return Target{}, fmt.Errorf("failed to resolve header %s: %w", name, err)
+			}
+		}
+		if cfg.Template != "" {
+			if notifier.Template, err = ParsePayloadTemplate(cfg.Template); err != nil {
+				return Target{}, fmt.Errorf("invalid template: %w", err)
+			}
+		}
+		target.Notifier = notifier
+	case TargetSlack:
+		if url == "" {
+			return Target{}, errors.New("url is required")
+		}
+		target.Notifier = &SlackNotifier{URL: url}
+	case TargetPagerDuty:
+		routingKey, err := rpcprovider.ResolveSecret(cfg.RoutingKey)
+		if err != nil {
+			return Target{}, fmt.Errorf("failed to resolve routing_key: %w", err)
+		}
+		if routingKey == "" {
+			return Target{}, errors.New("routing_key is required")
+		}
+		target.Notifier = &PagerDutyNotifier{RoutingKey: routingKey, URL: url, Source: source}
+	default:
+		return Target{}, fmt.Errorf("unknown target type %q, expected %s, %s or %s", cfg.Type, TargetWebhook, TargetSlack, TargetPagerDuty)
+	}
+	return target, nil
+}
@@ -0,0 +1,203 @@
+package alerting
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"text/template"
+	"time"
+
+	"github.com/friofry/config-health-checker/rpcprovider"
+)
+
+// DefaultPagerDutyURL is the PagerDuty Events API v2 endpoint
+const DefaultPagerDutyURL = "https://events.pagerduty.com/v2/enqueue"
+
+// notifyTimeout bounds a single delivery
+const notifyTimeout = 10 * time.Second
+
+// templateFuncs are available in webhook payload templates
+var templateFuncs = template.FuncMap{
+	// json encodes a value, e.g. {{json .Summary}} for a quoted and escaped string
+	"json": func(v interface{}) (string, error) {
+		data, err := json.Marshal(v)
+		return string(data), err
+	},
+}
+
+// ParsePayloadTemplate parses a webhook payload template executed with an Event
+func ParsePayloadTemplate(text string) (*template.Template, error) {
+	return template.New("payload").Funcs(templateFuncs).Parse(text)
+}
+
+// WebhookNotifier posts events to a URL, as JSON or rendered with a payload template
+type WebhookNotifier struct {
+	URL      string             // Endpoint, may contain credentials
+	Headers  map[string]string  // Additional request headers, e.g. authorization
+	Template *template.Template // Payload template, the JSON-encoded event if nil
+	Client   *http.Client       // HTTP client, a client with notifyTimeout if nil
+}
+
+// Notify implements Notifier
+func (n *WebhookNotifier) Notify(ctx context.Context, event Event) error {
+	var body []byte
+	if n.Template != nil {
+		var buf bytes.Buffer
+		if err := n.Template.Execute(&buf, event); err != nil {
+			return fmt.Errorf("failed to render webhook payload: %w", err)
+		}
+		body = buf.Bytes()
+	} else {
+		var err error
+		if body, err = json.Marshal(event); err != nil {
+			return fmt.Errorf("failed to marshal webhook payload: %w", err)
+		}
+	}
+	return post(ctx, n.Client, n.URL, n.Headers, body)
+}
+
+// SlackNotifier posts events to a Slack-compatible incoming webhook
+type SlackNotifier struct {
+	URL    string       // Incoming webhook URL, contains credentials
+	Client *http.Client // HTTP client, a client with notifyTimeout if nil
+}
+
+// slackMessage is the payload of a Slack incoming webhook
+type slackMessage struct {
+	Text string `json:"text"`
+}
+
+// Notify implements Notifier
+func (n *SlackNotifier) Notify(ctx context.Context, event Event) error {
+	icon := ":warning:"
+	switch {
+	case event.Resolved:
+		icon = ":white_check_mark:"
+	case event.Severity == SeverityCritical:
+		icon = ":rotating_light:"
+	}
+	text := fmt.Sprintf("%s *%s* %s", icon, event.Severity, event.Summary)
+	if event.Resolved {
+		text = fmt.Sprintf("%s *resolved* %s", icon, event.Summary)
+	}
+	if event.Reason != "" {
+		text += "\nFailed: " + event.Reason
+	}
+
+	body, err := json.Marshal(slackMessage{Text: text})
+	if err != nil {
+		return fmt.Errorf("failed to marshal Slack payload: %w", err)
+	}
+	return post(ctx, n.Client, n.URL, nil, body)
+}
+
+// PagerDutyNotifier triggers and resolves PagerDuty incidents through the Events API v2.
+// The event key is the dedup key, so an incident is resolved when its condition ends.
+type PagerDutyNotifier struct {
+	RoutingKey string       // Integration key of the PagerDuty service
+	URL        string       // Events API endpoint, DefaultPagerDutyURL if empty
+	Source     string       // Source reported in incidents
+	Client     *http.Client // HTTP client, a client with notifyTimeout if nil
+}
+
+// pagerDutyEvent is the payload of the PagerDuty Events API v2
+type pagerDutyEvent struct {
+	RoutingKey  string            `json:"routing_key"`
+	EventAction string            `json:"event_action"`
+	DedupKey    string            `json:"dedup_key"`
+	Payload     *pagerDutyPayload `json:"payload,omitempty"`
+}
+
+// pagerDutyPayload describes a triggered incident
+type pagerDutyPayload struct {
+	Summary       string            `json:"summary"`
+	Source        string            `json:"source"`
+	Severity      Severity          `json:"severity"`
+	Timestamp     string            `json:"timestamp"`
+	Component     string            `json:"component,omitempty"`
+	Group         string            `json:"group,omitempty"`
+	Class         EventType         `json:"class"`
+	CustomDetails map[string]string `json:"custom_details,omitempty"`
+}
+
+// Notify implements Notifier
+func (n *PagerDutyNotifier) Notify(ctx context.Context, event Event) error {
+	pdEvent := pagerDutyEvent{
+		RoutingKey:  n.RoutingKey,
+		EventAction: "trigger",
+		DedupKey:    event.Key,
+	}
+	if event.Resolved {
+		pdEvent.EventAction = "resolve"
+	} else {
+		pdEvent.Payload = &pagerDutyPayload{
+			Summary:   event.Summary,
+			Source:    n.Source,
+			Severity:  event.Severity,
+			Timestamp: event.Time.UTC().Format(time.RFC3339),
+			Component: event.Provider,
+			Group:     event.Chain,
+			Class:     event.Type,
+		}
+		if event.Reason != "" {
+			pdEvent.Payload.CustomDetails = map[string]string{"reason": event.Reason}
+		}
+	}
+
+	body, err := json.Marshal(pdEvent)
+	if err != nil {
+		return fmt.Errorf("failed to marshal PagerDuty event: %w", err)
+	}
+	target := n.URL
+	if target == "" {
+		target = DefaultPagerDutyURL
+	}
+	return post(ctx, n.Client, target, nil, body)
+}
+
+// post sends a JSON body and fails on non-2xx responses.
+// Only the host of the URL is included in errors, since webhook paths often contain secrets.
+func post(ctx context.Context, client *http.Client, target string, headers map[string]string, body []byte) error {
+	if client == nil {
+		client = &http.Client{Timeout: notifyTimeout}
+	}
+	host := redactedHost(target)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, target, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("invalid alert target %s", host)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for name, value := range headers {
+		req.Header.Set(name, value)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Errorf("request to %s failed: %w", host, err)
+	}
+	defer resp.Body.Close()
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("%s answered with status %d", host, resp.StatusCode)
+	}
+	return nil
+}
+
+// redactedHost returns the scheme and host of a URL
+func redactedHost(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return rpcprovider.RedactedValue
+	}
+	return u.Scheme + "://" + u.Host
+}
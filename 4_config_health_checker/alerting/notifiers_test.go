@@ -0,0 +1,151 @@
+package alerting
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/friofry/config-health-checker/configreader"
+)
+
+var testEvent = Event{
+	Type:     EventProviderEjected,
+	Severity: SeverityWarning,
+	Time:     time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC),
+	Key:      "provider/1/infura",
+	ChainID:  1,
+	Chain:    "ethereum/mainnet",
+	Provider: "infura",
+	Summary:  `provider infura of ethereum/mainnet (1) ejected`,
+	Reason:   "eth_blockNumber (timeout)",
+}
+
+// captureServer records the body and headers of the last request
+func captureServer(t *testing.T, status int) (*httptest.Server, *[]byte, *http.Header) {
+	var body []byte
+	var header http.Header
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ = io.ReadAll(r.Body)
+		header = r.Header.Clone()
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(server.Close)
+	return server, &body, &header
+}
+
+func TestWebhookNotifier(t *testing.T) {
+	t.Run("JSON event", func(t *testing.T) {
+		server, body, header := captureServer(t, http.StatusOK)
+		notifier := &WebhookNotifier{URL: server.URL, Headers: map[string]string{"Authorization": "Bearer token"}}
+		require.NoError(t, notifier.Notify(context.Background(), testEvent))
+
+		var event Event
+		require.NoError(t, json.Unmarshal(*body, &event))
+		assert.Equal(t, testEvent, event)
+		assert.Equal(t, "Bearer token", header.Get("Authorization"))
+		assert.Equal(t, "application/json", header.Get("Content-Type"))
+	})
+
+	t.Run("template", func(t *testing.T) {
+		server, body, _ := captureServer(t, http.StatusOK)
+		tmpl, err := ParsePayloadTemplate(`{"title": {{json .Summary}}, "resolved": {{.Resolved}}, "chain": {{.ChainID}}}`)
+		require.NoError(t, err)
+		notifier := &WebhookNotifier{URL: server.URL, Template: tmpl}
+		require.NoError(t, notifier.Notify(context.Background(), testEvent))
+		assert.JSONEq(t, `{"title": "provider infura of ethereum/mainnet (1) ejected", "resolved": false, "chain": 1}`, string(*body))
+	})
+
+	t.Run("error status hides the URL path", func(t *testing.T) {
+		server, _, _ := captureServer(t, http.StatusInternalServerError)
+		notifier := &WebhookNotifier{URL: server.URL + "/hooks/secret-token"}
+		err := notifier.Notify(context.Background(), testEvent)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "status 500")
+		assert.NotContains(t, err.Error(), "secret-token")
+	})
+}
+
+func TestSlackNotifier(t *testing.T) {
+	server, body, _ := captureServer(t, http.StatusOK)
+	notifier := &SlackNotifier{URL: server.URL}
+	require.NoError(t, notifier.Notify(context.Background(), testEvent))
+	assert.JSONEq(t, `{"text": ":warning: *warning* provider infura of ethereum/mainnet (1) ejected\nFailed: eth_blockNumber (timeout)"}`, string(*body))
+
+	resolved := testEvent
+	resolved.Resolved = true
+	resolved.Reason = ""
+	resolved.Summary = "resolved: " + testEvent.Summary
+	require.NoError(t, notifier.Notify(context.Background(), resolved))
+	assert.JSONEq(t, `{"text": ":white_check_mark: *resolved* resolved: provider infura of ethereum/mainnet (1) ejected"}`, string(*body))
+}
+
+func TestPagerDutyNotifier(t *testing.T) {
+	server, body, _ := captureServer(t, http.StatusAccepted)
+	notifier := &PagerDutyNotifier{RoutingKey: "routing-key", URL: server.URL, Source: "checker-1"}
+
+	require.NoError(t, notifier.Notify(context.Background(), testEvent))
+	assert.JSONEq(t, `{
+		"routing_key": "routing-key",
+		"event_action": "trigger",
+		"dedup_key": "provider/1/infura",
+		"payload": {
+			"summary": "provider infura of ethereum/mainnet (1) ejected",
+			"source": "checker-1",
+			"severity": "warning",
+			"timestamp": "2026-05-01T12:00:00Z",
+			"component": "infura",
+			"group": "ethereum/mainnet",
+			"class": "provider_ejected",
+			"custom_details": {"reason": "eth_blockNumber (timeout)"}
+		}
+	}`, string(*body))
+
+	resolved := testEvent
+	resolved.Resolved = true
+	require.NoError(t, notifier.Notify(context.Background(), resolved))
+	assert.JSONEq(t, `{"routing_key": "routing-key", "event_action": "resolve", "dedup_key": "provider/1/infura"}`, string(*body))
+}
+
+func TestFromConfig(t *testing.T) {
+	t.Setenv("ALERT_ROUTING_KEY", "resolved-key")
+	cfg := configreader.CheckerConfig{
+		IntervalSeconds: 30,
+		Alerting: configreader.AlertingConfig{
+			MinValidProviders: 2,
+			Source:            "checker-1",
+			Targets: []configreader.AlertTargetConfig{
+				{Type: "webhook", URL: "https://alerts.example.io/hook", Template: `{"text": {{json .Summary}}}`},
+				{Type: "slack", URL: "https://hooks.slack.com/services/T/B/X", Events: []string{"chain_below_min_providers"}},
+				{Type: "pagerduty", RoutingKey: "env:ALERT_ROUTING_KEY"},
+			},
+		},
+	}
+
+	alerter, err := FromConfig(cfg)
+	require.NoError(t, err)
+	assert.Equal(t, Config{MinValidProviders: 2, Cooldown: DefaultCooldown, Interval: 30 * time.Second}, alerter.config)
+	require.Len(t, alerter.targets, 3)
+	assert.NotNil(t, alerter.targets[0].Notifier.(*WebhookNotifier).Template)
+	assert.Equal(t, map[EventType]bool{EventChainBelowMin: true}, alerter.targets[1].Events)
+	assert.Equal(t, &PagerDutyNotifier{RoutingKey: "resolved-key", Source: "checker-1"}, alerter.targets[2].Notifier)
+
+	for name, target := range map[string]configreader.AlertTargetConfig{
+		"unknown type":      {Type: "email", URL: "https://example.io"},
+		"missing url":       {Type: "slack"},
+		"missing key":       {Type: "pagerduty"},
+		"unknown event":     {Type: "slack", URL: "https://example.io", Events: []string{"provider_down"}},
+		"invalid template":  {Type: "webhook", URL: "https://example.io", Template: "{{.Summary"},
+		"unresolved secret": {Type: "webhook", URL: "env:ALERT_MISSING_URL"},
+	} {
+		cfg.Alerting.Targets = []configreader.AlertTargetConfig{target}
+		_, err := FromConfig(cfg)
+		assert.Error(t, err, name)
+	}
+}
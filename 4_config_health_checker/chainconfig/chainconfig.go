@@ -7,7 +7,9 @@ import (
 	"os"
 	"strings"
 
+	"github.com/friofry/config-health-checker/chainprotocol"
 	rpcprovider "github.com/friofry/config-health-checker/rpcprovider"
+	"github.com/friofry/config-health-checker/strictjson"
 	"github.com/go-playground/validator/v10"
 )
 
@@ -40,6 +42,7 @@ type ChainConfig struct {
 	Name      string                    `json:"name" validate:"required,lowercase"`
 	Network   string                    `json:"network" validate:"required,lowercase"`
 	ChainId   int                       `json:"chainId" validate:"required"`
+	Protocol  chainprotocol.Protocol    `json:"protocol,omitempty" validate:"omitempty,oneof=evm solana bitcoin"`
 	Providers []rpcprovider.RpcProvider `json:"providers" validate:"required,dive"`
 }
 
@@ -106,25 +109,29 @@ func (c ReferenceChainConfig) Validate() error {
 	return nil
 }
 
-// LoadChains loads and validates chain configurations from a JSON file
-func LoadChains(filePath string) (ChainsConfig, error) {
+// LoadChains loads and validates chain configurations from a JSON file, handling unknown fields as mode says.
+// Secret references in provider credentials are resolved.
+func LoadChains(filePath string, mode strictjson.Mode) (ChainsConfig, error) {
 	file, err := os.ReadFile(filePath)
 	if err != nil {
 		return ChainsConfig{}, fmt.Errorf("failed to read config file: %w", err)
 	}
 
 	var config ChainsConfig
-	if err := json.Unmarshal(file, &config); err != nil {
-		return ChainsConfig{}, fmt.Errorf("failed to parse chains config: %w", err)
+	if err := strictjson.DecodeMode(file, &config, mode); err != nil {
+		return ChainsConfig{}, fmt.Errorf("failed to parse chains config %s: %w", filePath, err)
 	}
 
 	if len(config.Chains) == 0 {
 		return ChainsConfig{}, errors.New("no chains configured")
 	}
 
-	// Validate and normalize each chain
+	// Resolve secrets, validate and normalize each chain
 	for i := range config.Chains {
-		config.Chains[i].normalize()
+		if err := rpcprovider.ResolveProvidersSecrets(config.Chains[i].Providers); err != nil {
+			return ChainsConfig{}, fmt.Errorf("failed to resolve provider secrets: %w", err)
+		}
+		config.Chains[i].Normalize()
 		if err := config.Chains[i].Validate(); err != nil {
 			return ChainsConfig{}, fmt.Errorf("invalid chain configuration: %w", err)
 		}
@@ -133,25 +140,29 @@ func LoadChains(filePath string) (ChainsConfig, error) {
 	return config, nil
 }
 
-// LoadReferenceChains loads and validates reference provider configurations from a JSON file
-func LoadReferenceChains(filePath string) (ReferenceChainsConfig, error) {
+// LoadReferenceChains loads and validates reference provider configurations from a JSON file,
+// handling unknown fields as mode says. Secret references in provider credentials are resolved.
+func LoadReferenceChains(filePath string, mode strictjson.Mode) (ReferenceChainsConfig, error) {
 	file, err := os.ReadFile(filePath)
 	if err != nil {
 		return ReferenceChainsConfig{}, fmt.Errorf("failed to read config file: %w", err)
 	}
 
 	var config ReferenceChainsConfig
-	if err := json.Unmarshal(file, &config); err != nil {
-		return ReferenceChainsConfig{}, fmt.Errorf("failed to parse reference chains config: %w", err)
+	if err := strictjson.DecodeMode(file, &config, mode); err != nil {
+		return ReferenceChainsConfig{}, fmt.Errorf("failed to parse reference chains config %s: %w", filePath, err)
 	}
 
 	if len(config.Chains) == 0 {
 		return ReferenceChainsConfig{}, errors.New("no reference chains configured")
 	}
 
-	// Validate and normalize each reference chain
+	// Resolve secrets, validate and normalize each reference chain
 	for i := range config.Chains {
-		config.Chains[i].normalize()
+		if err := config.Chains[i].Provider.ResolveSecrets(); err != nil {
+			return ReferenceChainsConfig{}, fmt.Errorf("failed to resolve reference provider secrets: %w", err)
+		}
+		config.Chains[i].Normalize()
 		if err := config.Chains[i].Validate(); err != nil {
 			return ReferenceChainsConfig{}, fmt.Errorf("invalid reference chain configuration: %w", err)
 		}
@@ -180,14 +191,14 @@ func GetReferenceProvider(chains []ReferenceChainConfig, name, network string) (
 	return nil, fmt.Errorf("reference provider for %s (%s) not found", name, network)
 }
 
-// normalize ensures chain name and network are lowercase
-func (c *ChainConfig) normalize() {
+// Normalize ensures chain name and network are lowercase
+func (c *ChainConfig) Normalize() {
 	c.Name = strings.ToLower(c.Name)
 	c.Network = strings.ToLower(c.Network)
 }
 
-// normalize ensures reference chain name and network are lowercase
-func (c *ReferenceChainConfig) normalize() {
+// Normalize ensures reference chain name and network are lowercase
+func (c *ReferenceChainConfig) Normalize() {
 	c.Name = strings.ToLower(c.Name)
 	c.Network = strings.ToLower(c.Network)
 }
@@ -1,10 +1,13 @@
 package chainconfig
 
 import (
+	"errors"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/friofry/config-health-checker/rpcprovider"
+	"github.com/friofry/config-health-checker/strictjson"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -38,7 +41,7 @@ func TestLoadChains(t *testing.T) {
 	tmpFile.Close()
 
 	t.Run("successful load", func(t *testing.T) {
-		chains, err := LoadChains(tmpFile.Name())
+		chains, err := LoadChains(tmpFile.Name(), strictjson.ModeStrict)
 		assert.NoError(t, err)
 		assert.Len(t, chains.Chains, 1)
 		assert.Equal(t, "ethereum", chains.Chains[0].Name)
@@ -48,7 +51,7 @@ func TestLoadChains(t *testing.T) {
 	})
 
 	t.Run("file not found", func(t *testing.T) {
-		_, err := LoadChains("nonexistent.json")
+		_, err := LoadChains("nonexistent.json", strictjson.ModeStrict)
 		assert.Error(t, err)
 	})
 
@@ -61,11 +64,81 @@ func TestLoadChains(t *testing.T) {
 		assert.NoError(t, err)
 		invalidFile.Close()
 
-		_, err = LoadChains(invalidFile.Name())
+		_, err = LoadChains(invalidFile.Name(), strictjson.ModeStrict)
 		assert.Error(t, err)
 	})
 }
 
+func TestLoadChainsUnknownFields(t *testing.T) {
+	content := `{
+  "chains": [
+    {
+      "name": "ethereum",
+      "network": "mainnet",
+      "chainId": 1,
+      "providers": [
+        {
+          "name": "infura",
+          "url": "https://mainnet.infura.io/v3",
+          "authType": "token-auth",
+          "authtoken": "test"
+        }
+      ]
+    }
+  ]
+}`
+	path := filepath.Join(t.TempDir(), "providers.json")
+	assert.NoError(t, os.WriteFile(path, []byte(content), 0644))
+
+	_, err := LoadChains(path, strictjson.ModeStrict)
+	var unknownErr *strictjson.UnknownFieldsError
+	assert.True(t, errors.As(err, &unknownErr), "got %v", err)
+	assert.Contains(t, err.Error(), `line 12, column 11: unknown field "authtoken" in chains[0].providers[0] ("infura"), did you mean "authToken"?`)
+
+	// In warn mode the file is decoded as before, matching the key case-insensitively
+	chains, err := LoadChains(path, strictjson.ModeWarn)
+	assert.NoError(t, err)
+	assert.Equal(t, "test", chains.Chains[0].Providers[0].AuthToken)
+}
+
+func TestLoadChainsResolvesSecrets(t *testing.T) {
+	t.Setenv("CHC_TEST_INFURA_KEY", "resolved-token")
+
+	content := `{
+		"chains": [
+			{
+				"name": "ethereum",
+				"network": "mainnet",
+				"chainId": 1,
+				"providers": [
+					{
+						"name": "infura",
+						"url": "https://mainnet.infura.io/v3",
+						"authType": "token-auth",
+						"authToken": "env:CHC_TEST_INFURA_KEY"
+					}
+				]
+			}
+		]
+	}`
+
+	tmpFile, err := os.CreateTemp("", "test-secret-config-*.json")
+	assert.NoError(t, err)
+	defer os.Remove(tmpFile.Name())
+
+	_, err = tmpFile.WriteString(content)
+	assert.NoError(t, err)
+	tmpFile.Close()
+
+	chains, err := LoadChains(tmpFile.Name(), strictjson.ModeStrict)
+	assert.NoError(t, err)
+	assert.Equal(t, "resolved-token", chains.Chains[0].Providers[0].AuthToken)
+
+	os.Unsetenv("CHC_TEST_INFURA_KEY")
+	_, err = LoadChains(tmpFile.Name(), strictjson.ModeStrict)
+	assert.ErrorContains(t, err, "CHC_TEST_INFURA_KEY")
+}
+
 func TestLoadReferenceChains(t *testing.T) {
 	// Create temporary test file
 	content := `{
@@ -94,7 +167,7 @@ func TestLoadReferenceChains(t *testing.T) {
 	tmpFile.Close()
 
 	t.Run("successful load", func(t *testing.T) {
-		chains, err := LoadReferenceChains(tmpFile.Name())
+		chains, err := LoadReferenceChains(tmpFile.Name(), strictjson.ModeStrict)
 		assert.NoError(t, err)
 		assert.Len(t, chains.Chains, 1)
 		assert.Equal(t, "ethereum", chains.Chains[0].Name)
@@ -104,7 +177,7 @@ func TestLoadReferenceChains(t *testing.T) {
 	})
 
 	t.Run("file not found", func(t *testing.T) {
-		_, err := LoadReferenceChains("nonexistent.json")
+		_, err := LoadReferenceChains("nonexistent.json", strictjson.ModeStrict)
 		assert.Error(t, err)
 	})
 
@@ -117,7 +190,7 @@ func TestLoadReferenceChains(t *testing.T) {
 		assert.NoError(t, err)
 		invalidFile.Close()
 
-		_, err = LoadReferenceChains(invalidFile.Name())
+		_, err = LoadReferenceChains(invalidFile.Name(), strictjson.ModeStrict)
 		assert.Error(t, err)
 	})
 
@@ -130,7 +203,7 @@ func TestLoadReferenceChains(t *testing.T) {
 		assert.NoError(t, err)
 		invalidFile.Close()
 
-		_, err = LoadReferenceChains(invalidFile.Name())
+		_, err = LoadReferenceChains(invalidFile.Name(), strictjson.ModeStrict)
 		assert.Error(t, err)
 	})
 
@@ -160,7 +233,7 @@ func TestLoadReferenceChains(t *testing.T) {
 		assert.NoError(t, err)
 		tmpFile.Close()
 
-		chains, err := LoadReferenceChains(tmpFile.Name())
+		chains, err := LoadReferenceChains(tmpFile.Name(), strictjson.ModeStrict)
 		assert.NoError(t, err)
 		assert.Equal(t, "ethereum", chains.Chains[0].Name)
 		assert.Equal(t, "mainnet", chains.Chains[0].Network)
@@ -280,6 +353,19 @@ func TestValidateChainConfig(t *testing.T) {
 			},
 			wantErr: true,
 		},
+		{
+			name: "unknown protocol",
+			config: ChainConfig{
+				Name:     "cosmos",
+				Network:  "mainnet",
+				ChainId:  1,
+				Protocol: "cosmos",
+				Providers: []rpcprovider.RpcProvider{
+					{Name: "provider1", URL: "https://provider1.example.com", AuthType: "no-auth"},
+				},
+			},
+			wantErr: true,
+		},
 		{
 			name: "missing chainId",
 			config: ChainConfig{
@@ -0,0 +1,143 @@
+package chainprotocol
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Protocol defines the RPC dialect spoken by the providers of a chain
+type Protocol string
+
+const (
+	EVM     Protocol = "evm"     // Ethereum JSON-RPC 2.0, numbers are hex strings such as "0x10"
+	Solana  Protocol = "solana"  // Solana JSON-RPC 2.0, numbers are JSON numbers, often nested as {"context":...,"value":...}
+	Bitcoin Protocol = "bitcoin" // Bitcoin Core JSON-RPC 1.0, numbers are JSON numbers
+)
+
+// Parse converts a configuration value into a Protocol, empty means EVM
+func Parse(value string) (Protocol, error) {
+	switch protocol := Protocol(value); protocol {
+	case "":
+		return EVM, nil
+	case EVM, Solana, Bitcoin:
+		return protocol, nil
+	default:
+		return "", fmt.Errorf("unknown protocol: %q", value)
+	}
+}
+
+// OrDefault returns the protocol, or EVM if it is not set
+func (p Protocol) OrDefault() Protocol {
+	if p == "" {
+		return EVM
+	}
+	return p
+}
+
+// JSONRPCVersion returns the JSON-RPC version sent in requests
+func (p Protocol) JSONRPCVersion() string {
+	if p == Bitcoin {
+		return "1.0"
+	}
+	return "2.0"
+}
+
+// ProbeMethod returns a cheap method without parameters every provider of the protocol answers
+func (p Protocol) ProbeMethod() string {
+	switch p {
+	case Solana:
+		return "getHealth"
+	case Bitcoin:
+		return "getblockcount"
+	default:
+		return "eth_chainId"
+	}
+}
+
+// ParseResult extracts a number from the result of a JSON-RPC response.
+// path selects a nested value with dot-separated object keys or array indexes, e.g. "value" or "blocks.0.height".
+// EVM strings are parsed as hex, strings of other protocols as decimal unless prefixed with 0x.
+func (p Protocol) ParseResult(result json.RawMessage, path string) (*big.Int, error) {
+	value, err := selectPath(result, path)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(value))
+	decoder.UseNumber()
+	var decoded interface{}
+	if err := decoder.Decode(&decoded); err != nil {
+		return nil, fmt.Errorf("failed to parse result: %w", err)
+	}
+
+	switch v := decoded.(type) {
+	case json.Number:
+		return parseDecimal(v.String())
+	case string:
+		if v == "" {
+			return nil, errors.New("empty result")
+		}
+		if hex, ok := strings.CutPrefix(v, "0x"); ok || p.OrDefault() == EVM {
+			number, ok := new(big.Int).SetString(hex, 16)
+			if !ok {
+				return nil, fmt.Errorf("failed to parse result as hex number: %s", hex)
+			}
+			return number, nil
+		}
+		return parseDecimal(v)
+	case nil:
+		return nil, errors.New("empty result")
+	default:
+		return nil, fmt.Errorf("result is not a number: %s", value)
+	}
+}
+
+// selectPath returns the JSON value at path inside result
+func selectPath(result json.RawMessage, path string) (json.RawMessage, error) {
+	if path == "" {
+		return result, nil
+	}
+
+	value := result
+	for _, key := range strings.Split(path, ".") {
+		if index, err := strconv.Atoi(key); err == nil {
+			var array []json.RawMessage
+			if err := json.Unmarshal(value, &array); err == nil {
+				if index < 0 || index >= len(array) {
+					return nil, fmt.Errorf("result path %q: index %d out of range", path, index)
+				}
+				value = array[index]
+				continue
+			}
+		}
+
+		var object map[string]json.RawMessage
+		if err := json.Unmarshal(value, &object); err != nil {
+			return nil, fmt.Errorf("result path %q: %s is not an object", path, key)
+		}
+		next, ok := object[key]
+		if !ok {
+			return nil, fmt.Errorf("result path %q: key %s not found", path, key)
+		}
+		value = next
+	}
+	return value, nil
+}
+
+// parseDecimal parses a decimal number, truncating fractional values
+func parseDecimal(value string) (*big.Int, error) {
+	if number, ok := new(big.Int).SetString(value, 10); ok {
+		return number, nil
+	}
+	float, ok := new(big.Float).SetString(value)
+	if !ok {
+		return nil, fmt.Errorf("failed to parse result as decimal number: %s", value)
+	}
+	number, _ := float.Int(nil)
+	return number, nil
+}
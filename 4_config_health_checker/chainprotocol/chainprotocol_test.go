@@ -0,0 +1,64 @@
+package chainprotocol
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	protocol, err := Parse("")
+	require.NoError(t, err)
+	assert.Equal(t, EVM, protocol)
+
+	protocol, err = Parse("bitcoin")
+	require.NoError(t, err)
+	assert.Equal(t, Bitcoin, protocol)
+	assert.Equal(t, "1.0", protocol.JSONRPCVersion())
+	assert.Equal(t, "2.0", Solana.JSONRPCVersion())
+	assert.Equal(t, "eth_chainId", EVM.ProbeMethod())
+	assert.Equal(t, "getHealth", Solana.ProbeMethod())
+	assert.Equal(t, "getblockcount", Bitcoin.ProbeMethod())
+
+	_, err = Parse("cosmos")
+	assert.Error(t, err)
+}
+
+func TestParseResult(t *testing.T) {
+	tests := []struct {
+		name     string
+		protocol Protocol
+		result   string
+		path     string
+		expected int64
+		wantErr  bool
+	}{
+		{name: "EVM hex", protocol: EVM, result: `"0x64"`, expected: 100},
+		{name: "Default protocol is EVM", result: `"0x64"`, expected: 100},
+		{name: "EVM invalid hex", protocol: EVM, result: `"not-hex"`, wantErr: true},
+		{name: "Solana number", protocol: Solana, result: `285714000`, expected: 285714000},
+		{name: "Solana nested value", protocol: Solana, result: `{"context":{"slot":1},"value":5000}`, path: "value", expected: 5000},
+		{name: "Bitcoin decimal string", protocol: Bitcoin, result: `"840000"`, expected: 840000},
+		{name: "Bitcoin fractional number", protocol: Bitcoin, result: `{"difficulty":86.5}`, path: "difficulty", expected: 86},
+		{name: "Array index", protocol: Solana, result: `{"value":[{"slot":7}]}`, path: "value.0.slot", expected: 7},
+		{name: "Hex string for non-EVM", protocol: Solana, result: `"0x10"`, expected: 16},
+		{name: "Missing key", protocol: Solana, result: `{"context":{}}`, path: "value", wantErr: true},
+		{name: "Index out of range", protocol: Solana, result: `[1]`, path: "3", wantErr: true},
+		{name: "Object is not a number", protocol: Solana, result: `{"value":1}`, wantErr: true},
+		{name: "Null result", protocol: Bitcoin, result: `null`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			number, err := tt.protocol.ParseResult(json.RawMessage(tt.result), tt.path)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.expected, number.Int64())
+		})
+	}
+}
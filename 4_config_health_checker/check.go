@@ -0,0 +1,97 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+
+	"github.com/friofry/config-health-checker/checker"
+	"github.com/friofry/config-health-checker/report"
+	requestsrunner "github.com/friofry/config-health-checker/requests-runner"
+)
+
+// Exit codes of the check subcommand
+const (
+	exitHealthy   = 0 // Every enabled provider passed validation
+	exitDegraded  = 1 // Some providers failed, every chain still has a valid provider
+	exitUsage     = 2 // Invalid flags or configuration, or the valid providers could not be written
+	exitUnhealthy = 3 // A chain has no valid provider or no reference
+)
+
+// runCheck implements the check subcommand: a single validation run that writes the valid providers,
+// prints a report and returns an exit code reflecting the overall health
+func runCheck(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("check", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	configFlags := registerConfigFlags(flags)
+	formatName := flags.String("format", string(report.FormatTable), "report format: table, json, junit, markdown or html")
+	reportPath := flags.String("report", "", "path to write the report to instead of stdout")
+	if err := flags.Parse(args); err != nil {
+		return exitUsage
+	}
+
+	format, err := report.ParseFormat(*formatName)
+	if err != nil {
+		fmt.Fprintf(stderr, "error: %v\n", err)
+		return exitUsage
+	}
+
+	loaded, err := loadConfig(configFlags)
+	if err != nil {
+		fmt.Fprintf(stderr, "error: %v\n", err)
+		return exitUsage
+	}
+	config, err := loaded.local(context.Background())
+	if err != nil {
+		fmt.Fprintf(stderr, "error: %v\n", err)
+		return exitUsage
+	}
+
+	runnerOptions, err := checker.RunnerOptionsFromConfig(config)
+	if err != nil {
+		fmt.Fprintf(stderr, "error: invalid requests runner configuration: %v\n", err)
+		return exitUsage
+	}
+	runner, err := checker.NewRunnerFromConfig(config, requestsrunner.NewRequestsRunnerWithOptions(runnerOptions))
+	if err != nil {
+		fmt.Fprintf(stderr, "error: %v\n", err)
+		return exitUsage
+	}
+	// Keep stdout for the report
+	runner.SetLogger(slog.New(slog.NewJSONHandler(stderr, nil)))
+
+	cycle, writeErr := runner.Check(context.Background())
+	if writeErr != nil {
+		fmt.Fprintf(stderr, "error: %v\n", writeErr)
+	}
+
+	out := stdout
+	if *reportPath != "" {
+		file, err := os.Create(*reportPath)
+		if err != nil {
+			fmt.Fprintf(stderr, "error: failed to create report file: %v\n", err)
+			return exitUsage
+		}
+		defer file.Close()
+		out = file
+	}
+	if err := report.Write(out, format, report.Build(cycle)); err != nil {
+		fmt.Fprintf(stderr, "error: %v\n", err)
+	}
+
+	// An unwritable output path is a configuration error
+	if writeErr != nil {
+		return exitUsage
+	}
+	switch cycle.Health() {
+	case checker.HealthHealthy:
+		return exitHealthy
+	case checker.HealthDegraded:
+		return exitDegraded
+	default:
+		return exitUnhealthy
+	}
+}
@@ -5,9 +5,12 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
+	"log/slog"
 	"math/big"
+	"sort"
 	"time"
 
+	"github.com/friofry/config-health-checker/chainprotocol"
 	"github.com/friofry/config-health-checker/rpctestsconfig"
 
 	requestsrunner "github.com/friofry/config-health-checker/requests-runner"
@@ -59,7 +62,16 @@ func TestEVMMethodWithCaller(
 
 	// Execute the EVM method in parallel using ParallelCallEVMMethods
 	results := requestsrunner.ParallelCallEVMMethods(ctx, allProviders, config.Method, config.Params, timeout, caller)
+	return checkMethodResults(config, results, providers, referenceProvider)
+}
 
+// checkMethodResults validates the results of a single method against the result of the reference provider
+func checkMethodResults(
+	config rpctestsconfig.EVMMethodTestConfig,
+	results map[string]requestsrunner.ProviderResult,
+	providers []rpcprovider.RpcProvider,
+	referenceProvider rpcprovider.RpcProvider,
+) map[string]CheckResult {
 	// Extract reference result
 	refResult, refExists := results[referenceProvider.Name]
 	if !refExists || !refResult.Success {
@@ -67,7 +79,7 @@ func TestEVMMethodWithCaller(
 	}
 
 	// Parse reference value
-	refValue, err := parseJSONRPCResult(refResult.Response)
+	refValue, err := parseResult(refResult.Response, config.Protocol, config.ResultPath)
 	if err != nil {
 		return handleReferenceParseError(results, referenceProvider.Name, err)
 	}
@@ -84,6 +96,17 @@ func TestEVMMethodWithCaller(
 			continue
 		}
 
+		// A rate-limited provider is throttled rather than unhealthy, so it is not ejected
+		if result.ErrorClass() == requestsrunner.ErrorClassRateLimited {
+			checkResults[provider.Name] = CheckResult{
+				Valid:     true,
+				Throttled: true,
+				Result:    result,
+				Error:     result.Error,
+			}
+			continue
+		}
+
 		// Handle failed requests
 		if !result.Success {
 			checkResults[provider.Name] = CheckResult{
@@ -95,21 +118,34 @@ func TestEVMMethodWithCaller(
 		}
 
 		// Parse provider's result
-		providerValue, err := parseJSONRPCResult(result.Response)
+		providerValue, err := parseResult(result.Response, config.Protocol, config.ResultPath)
 		if err != nil {
 			checkResults[provider.Name] = CheckResult{
 				Valid:  false,
 				Result: result,
-				Error:  fmt.Errorf("failed to parse provider response: %w", err),
+				Error: &requestsrunner.ProviderError{
+					Class: requestsrunner.ErrorClassMalformedJSON,
+					Err:   fmt.Errorf("failed to parse provider response: %w", err),
+				},
 			}
 			continue
 		}
 
 		// Use provided comparison function
-		valid := config.CompareFunc(refValue, providerValue)
+		if !config.CompareFunc(refValue, providerValue) {
+			checkResults[provider.Name] = CheckResult{
+				Valid:  false,
+				Result: result,
+				Error: &requestsrunner.ProviderError{
+					Class: requestsrunner.ErrorClassResultMismatch,
+					Err:   fmt.Errorf("result %s differs from reference result %s", providerValue, refValue),
+				},
+			}
+			continue
+		}
 
 		checkResults[provider.Name] = CheckResult{
-			Valid:  valid,
+			Valid:  true,
 			Result: result,
 		}
 	}
@@ -119,12 +155,14 @@ func TestEVMMethodWithCaller(
 
 // CheckResult contains the validation result for a provider
 type CheckResult struct {
-	Valid  bool
-	Result requestsrunner.ProviderResult
-	Error  error
+	Valid     bool
+	Throttled bool // Provider was rate-limited, the method could not be validated
+	Result    requestsrunner.ProviderResult
+	Error     error
 }
 
-// TestMultipleEVMMethods runs multiple EVM method tests and returns results per provider per method
+// TestMultipleEVMMethods runs multiple EVM method tests and returns results per provider per method.
+// If the caller supports JSON-RPC batches, all methods are sent to a provider in one batch.
 func TestMultipleEVMMethods(
 	ctx context.Context,
 	methodConfigs []rpctestsconfig.EVMMethodTestConfig, // list of method configs
@@ -133,6 +171,20 @@ func TestMultipleEVMMethods(
 	referenceProvider rpcprovider.RpcProvider,
 	timeout time.Duration,
 ) map[string]map[string]CheckResult { // provider -> method -> result
+	results, _ := testMultipleEVMMethods(ctx, methodConfigs, caller, providers, referenceProvider, timeout)
+	return results
+}
+
+// testMultipleEVMMethods runs multiple EVM method tests and also returns whether the providers
+// support batches, for providers that were sent one
+func testMultipleEVMMethods(
+	ctx context.Context,
+	methodConfigs []rpctestsconfig.EVMMethodTestConfig,
+	caller requestsrunner.EVMMethodCaller,
+	providers []rpcprovider.RpcProvider,
+	referenceProvider rpcprovider.RpcProvider,
+	timeout time.Duration,
+) (map[string]map[string]CheckResult, map[string]bool) {
 	results := make(map[string]map[string]CheckResult)
 
 	// Initialize result structure
@@ -140,17 +192,83 @@ func TestMultipleEVMMethods(
 		results[provider.Name] = make(map[string]CheckResult)
 	}
 
-	// Run tests for each method
-	for _, config := range methodConfigs {
-		methodResults := TestEVMMethodWithCaller(ctx, config, caller, providers, referenceProvider, timeout)
+	batchCaller, batching := caller.(requestsrunner.BatchEVMMethodCaller)
+	if !batching || len(methodConfigs) < 2 || referenceProvider.Name == "" {
+		// Run tests for each method
+		for _, config := range methodConfigs {
+			methodResults := TestEVMMethodWithCaller(ctx, config, caller, providers, referenceProvider, timeout)
 
-		// Store results per provider using method name from config
-		for providerName, result := range methodResults {
+			// Store results per provider using method name from config
+			for providerName, result := range methodResults {
+				results[providerName][config.Method] = result
+			}
+		}
+		return results, nil
+	}
+
+	allProviders := append([]rpcprovider.RpcProvider{referenceProvider}, providers...)
+	callResults, batchSupport := callMethodsBatch(ctx, methodConfigs, batchCaller, allProviders, timeout)
+	for _, config := range methodConfigs {
+		for providerName, result := range checkMethodResults(config, callResults[config.Method], providers, referenceProvider) {
 			results[providerName][config.Method] = result
 		}
 	}
+	return results, batchSupport
+}
 
-	return results
+// callMethodsBatch sends the methods to every provider as one JSON-RPC batch. Providers rejecting
+// the batch are called once per method instead. It returns the results per method and provider
+// and whether the providers support batches; providers that failed as a whole are left out of the latter.
+func callMethodsBatch(
+	ctx context.Context,
+	methodConfigs []rpctestsconfig.EVMMethodTestConfig,
+	caller requestsrunner.BatchEVMMethodCaller,
+	providers []rpcprovider.RpcProvider,
+	timeout time.Duration,
+) (map[string]map[string]requestsrunner.ProviderResult, map[string]bool) {
+	calls := make([]requestsrunner.MethodCall, len(methodConfigs))
+	results := make(map[string]map[string]requestsrunner.ProviderResult, len(methodConfigs))
+	for i, config := range methodConfigs {
+		calls[i] = requestsrunner.MethodCall{Method: config.Method, Params: config.Params}
+		results[config.Method] = make(map[string]requestsrunner.ProviderResult, len(providers))
+	}
+
+	batchResults := requestsrunner.ParallelCallEVMMethodsBatch(ctx, providers, calls, timeout, caller)
+	batchSupport := make(map[string]bool, len(providers))
+	var singleCallProviders []rpcprovider.RpcProvider
+	for _, provider := range providers {
+		batch := batchResults[provider.Name]
+		switch {
+		case batch.Error == nil:
+			batchSupport[provider.Name] = true
+			for i, config := range methodConfigs {
+				results[config.Method][provider.Name] = batch.Results[i]
+			}
+		case batch.Rejected():
+			batchSupport[provider.Name] = false
+			singleCallProviders = append(singleCallProviders, provider)
+		default:
+			// The provider failed as a whole, e.g. timed out, single calls would fail the same way
+			for _, config := range methodConfigs {
+				results[config.Method][provider.Name] = requestsrunner.ProviderResult{
+					Error:       batch.Error,
+					Response:    batch.Response,
+					ElapsedTime: batch.ElapsedTime,
+					Attempts:    batch.Attempts,
+				}
+			}
+		}
+	}
+
+	if len(singleCallProviders) > 0 {
+		for _, config := range methodConfigs {
+			singleResults := requestsrunner.ParallelCallEVMMethods(ctx, singleCallProviders, config.Method, config.Params, timeout, caller)
+			for name, result := range singleResults {
+				results[config.Method][name] = result
+			}
+		}
+	}
+	return results, batchSupport
 }
 
 // handleReferenceFailure handles cases where reference provider fails
@@ -163,7 +281,10 @@ func handleReferenceFailure(results map[string]requestsrunner.ProviderResult, re
 			checkResults[name] = CheckResult{
 				Valid:  false,
 				Result: result,
-				Error:  fmt.Errorf("validation failed: reference provider %s failed", refName),
+				Error: &requestsrunner.ProviderError{
+					Class: requestsrunner.ErrorClassReferenceFailure,
+					Err:   fmt.Errorf("validation failed: reference provider %s failed", refName),
+				},
 			}
 		}
 	}
@@ -178,7 +299,10 @@ func handleReferenceParseError(results map[string]requestsrunner.ProviderResult,
 		checkResults[name] = CheckResult{
 			Valid:  false,
 			Result: result,
-			Error:  fmt.Errorf("failed to parse reference provider %s response: %w", refName, err),
+			Error: &requestsrunner.ProviderError{
+				Class: requestsrunner.ErrorClassReferenceFailure,
+				Err:   fmt.Errorf("failed to parse reference provider %s response: %w", refName, err),
+			},
 		}
 	}
 	return checkResults
@@ -194,7 +318,7 @@ func ValidateMultipleEVMMethods(
 	timeout time.Duration,
 ) map[string]ProviderValidationResult {
 	// Run all method tests
-	methodResults := TestMultipleEVMMethods(ctx, methodConfigs, caller, providers, referenceProvider, timeout)
+	methodResults, batchSupport := testMultipleEVMMethods(ctx, methodConfigs, caller, providers, referenceProvider, timeout)
 
 	// Prepare validation results
 	validationResults := make(map[string]ProviderValidationResult)
@@ -202,9 +326,13 @@ func ValidateMultipleEVMMethods(
 	for providerName, results := range methodResults {
 		// Track failed methods
 		failedMethods := make(map[string]FailedMethodResult)
+		var throttledMethods []string
 		allValid := true
 
 		for method, result := range results {
+			if result.Throttled {
+				throttledMethods = append(throttledMethods, method)
+			}
 			if !result.Valid {
 				allValid = false
 				// Get reference result for this method
@@ -213,14 +341,22 @@ func ValidateMultipleEVMMethods(
 				failedMethods[method] = FailedMethodResult{
 					Result:          result.Result,
 					ReferenceResult: refResult.Result,
+					Error:           result.Error,
 				}
 			}
 		}
 
-		validationResults[providerName] = ProviderValidationResult{
-			Valid:         allValid,
-			FailedMethods: failedMethods,
+		sort.Strings(throttledMethods)
+		validationResult := ProviderValidationResult{
+			Valid:            allValid,
+			FailedMethods:    failedMethods,
+			ThrottledMethods: throttledMethods,
+			Methods:          results,
+		}
+		if supported, sent := batchSupport[providerName]; sent {
+			validationResult.BatchSupported = &supported
 		}
+		validationResults[providerName] = validationResult
 	}
 
 	return validationResults
@@ -228,26 +364,69 @@ func ValidateMultipleEVMMethods(
 
 // ProviderValidationResult contains aggregated validation results for a provider
 type ProviderValidationResult struct {
-	Valid         bool                          // Overall validation status
-	FailedMethods map[string]FailedMethodResult // Map of failed test methods to their results
+	Valid            bool                          // Overall validation status
+	FailedMethods    map[string]FailedMethodResult // Map of failed test methods to their results
+	ThrottledMethods []string                      // Methods skipped because the provider was rate-limited
+	Methods          map[string]CheckResult        // Results of every checked method, including passed ones
+	BatchSupported   *bool                         // Whether the provider answers JSON-RPC batches, nil if unknown
 }
 
 // FailedMethodResult contains details about a failed method test
 type FailedMethodResult struct {
 	Result          requestsrunner.ProviderResult // Raw result from the provider
 	ReferenceResult requestsrunner.ProviderResult // Raw result from the reference provider
+	Error           error                         // Reason the method failed validation
 }
 
-// parseJSONRPCResult extracts the numeric result from a JSON-RPC response
+// ErrorClass returns the class of the validation failure
+func (f FailedMethodResult) ErrorClass() requestsrunner.ErrorClass {
+	return requestsrunner.ClassifyError(f.Error)
+}
+
+// LogValue implements slog.LogValuer so that logged failures are redacted
+func (f FailedMethodResult) LogValue() slog.Value {
+	attrs := []slog.Attr{
+		slog.Any("result", f.Result),
+		slog.Any("reference", f.ReferenceResult),
+	}
+	if f.Error != nil {
+		attrs = append(attrs,
+			slog.String("error", f.Error.Error()),
+			slog.String("errorClass", string(f.ErrorClass())),
+		)
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// LogValue implements slog.LogValuer so that logged validation results are redacted
+func (r ProviderValidationResult) LogValue() slog.Value {
+	failed := make([]slog.Attr, 0, len(r.FailedMethods))
+	for method, result := range r.FailedMethods {
+		failed = append(failed, slog.Any(method, result))
+	}
+	return slog.GroupValue(
+		slog.Bool("valid", r.Valid),
+		slog.Attr{Key: "failedMethods", Value: slog.GroupValue(failed...)},
+		slog.Any("throttledMethods", r.ThrottledMethods),
+	)
+}
+
+// parseJSONRPCResult extracts the numeric result from an EVM JSON-RPC response
 // Returns the parsed big.Int value or an error if parsing fails
 func parseJSONRPCResult(response []byte) (*big.Int, error) {
+	return parseResult(response, chainprotocol.EVM, "")
+}
+
+// parseResult extracts the numeric result from a JSON-RPC response of the given protocol.
+// resultPath selects a nested value inside the result, e.g. "value" for Solana context responses.
+func parseResult(response []byte, protocol chainprotocol.Protocol, resultPath string) (*big.Int, error) {
 	if len(response) == 0 {
 		return nil, fmt.Errorf("empty response")
 	}
 
 	var jsonResponse struct {
-		Result string `json:"result"`
-		Error  struct {
+		Result json.RawMessage `json:"result"`
+		Error  *struct {
 			Code    int    `json:"code"`
 			Message string `json:"message"`
 		} `json:"error"`
@@ -257,27 +436,16 @@ func parseJSONRPCResult(response []byte) (*big.Int, error) {
 		return nil, fmt.Errorf("failed to unmarshal JSON-RPC response: %w", err)
 	}
 
-	// Check for JSON-RPC error
-	if jsonResponse.Error.Code != 0 {
+	// Check for JSON-RPC error, Bitcoin responses always carry an error field that is null on success
+	if jsonResponse.Error != nil && jsonResponse.Error.Code != 0 {
 		return nil, fmt.Errorf("JSON-RPC error: %s (code: %d)",
 			jsonResponse.Error.Message,
 			jsonResponse.Error.Code)
 	}
 
-	if jsonResponse.Result == "" {
+	if len(jsonResponse.Result) == 0 || string(jsonResponse.Result) == "null" {
 		return nil, errors.New("empty result in JSON-RPC response")
 	}
 
-	// Remove 0x prefix if present
-	resultStr := jsonResponse.Result
-	if len(resultStr) > 2 && resultStr[0:2] == "0x" {
-		resultStr = resultStr[2:]
-	}
-
-	value, ok := new(big.Int).SetString(resultStr, 16)
-	if !ok {
-		return nil, fmt.Errorf("failed to parse result as hex number: %s", resultStr)
-	}
-
-	return value, nil
+	return protocol.OrDefault().ParseResult(jsonResponse.Result, resultPath)
 }
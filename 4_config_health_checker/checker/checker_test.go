@@ -3,10 +3,16 @@ package checker
 import (
 	"context"
 	"errors"
+	"io"
 	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
 	"testing"
 	"time"
 
+	"github.com/friofry/config-health-checker/chainprotocol"
 	"github.com/friofry/config-health-checker/rpctestsconfig"
 
 	requestsrunner "github.com/friofry/config-health-checker/requests-runner"
@@ -216,3 +222,169 @@ func TestFailedMethodResultResponse(t *testing.T) {
 	// Verify the response is accessible through the Result field
 	assert.Equal(t, `{"result":"test response"}`, string(failedResult.Result.Response))
 }
+
+func TestValidateMultipleEVMMethodsErrorClasses(t *testing.T) {
+	referenceProvider := rpcprovider.RpcProvider{Name: "reference"}
+	providers := []rpcprovider.RpcProvider{{Name: "mismatch"}, {Name: "malformed"}, {Name: "rpcError"}}
+
+	methodConfigs := []rpctestsconfig.EVMMethodTestConfig{
+		{
+			Method: "eth_blockNumber",
+			CompareFunc: func(reference, result *big.Int) bool {
+				return reference.Cmp(result) == 0
+			},
+		},
+	}
+
+	mockCaller := &mocks.EVMMethodCaller{
+		Responses: map[string]requestsrunner.ProviderResult{
+			"reference": {Success: true, Response: []byte(`{"result":"0x64"}`)},
+			"mismatch":  {Success: true, Response: []byte(`{"result":"0x65"}`)},
+			"malformed": {Success: true, Response: []byte(`{"result":"not-hex"}`)},
+			"rpcError": {
+				Success: false,
+				Error:   &requestsrunner.ProviderError{Class: requestsrunner.ErrorClassRPCError, RPCCode: -32000},
+			},
+		},
+	}
+
+	results := ValidateMultipleEVMMethods(context.Background(), methodConfigs, mockCaller, providers, referenceProvider, time.Second)
+
+	assert.Equal(t, requestsrunner.ErrorClassResultMismatch, results["mismatch"].FailedMethods["eth_blockNumber"].ErrorClass())
+	assert.Equal(t, requestsrunner.ErrorClassMalformedJSON, results["malformed"].FailedMethods["eth_blockNumber"].ErrorClass())
+	assert.Equal(t, requestsrunner.ErrorClassRPCError, results["rpcError"].FailedMethods["eth_blockNumber"].ErrorClass())
+
+	failingReference := &mocks.EVMMethodCaller{
+		Responses: map[string]requestsrunner.ProviderResult{
+			"reference": {Success: false, Error: errors.New("reference down")},
+			"mismatch":  {Success: true, Response: []byte(`{"result":"0x64"}`)},
+		},
+	}
+	results = ValidateMultipleEVMMethods(context.Background(), methodConfigs, failingReference, providers[:1], referenceProvider, time.Second)
+	assert.Equal(t, requestsrunner.ErrorClassReferenceFailure, results["mismatch"].FailedMethods["eth_blockNumber"].ErrorClass())
+}
+
+func TestValidateMultipleEVMMethodsThrottledProvider(t *testing.T) {
+	referenceProvider := rpcprovider.RpcProvider{Name: "reference"}
+	providers := []rpcprovider.RpcProvider{{Name: "throttled"}}
+
+	methodConfigs := []rpctestsconfig.EVMMethodTestConfig{
+		{
+			Method: "eth_blockNumber",
+			CompareFunc: func(reference, result *big.Int) bool {
+				return reference.Cmp(result) == 0
+			},
+		},
+	}
+
+	mockCaller := &mocks.EVMMethodCaller{
+		Responses: map[string]requestsrunner.ProviderResult{
+			"reference": {Success: true, Response: []byte(`{"result":"0x64"}`)},
+			"throttled": {
+				Success: false,
+				Error:   &requestsrunner.ProviderError{Class: requestsrunner.ErrorClassRateLimited, StatusCode: 429},
+			},
+		},
+	}
+
+	results := ValidateMultipleEVMMethods(context.Background(), methodConfigs, mockCaller, providers, referenceProvider, time.Second)
+
+	assert.True(t, results["throttled"].Valid, "rate-limited provider should not be marked unhealthy")
+	assert.Empty(t, results["throttled"].FailedMethods)
+	assert.Equal(t, []string{"eth_blockNumber"}, results["throttled"].ThrottledMethods)
+}
+
+func TestValidateMultipleEVMMethodsNonEVMProtocols(t *testing.T) {
+	referenceProvider := rpcprovider.RpcProvider{Name: "reference"}
+	providers := []rpcprovider.RpcProvider{{Name: "synced"}, {Name: "behind"}}
+	withinTen := func(reference, result *big.Int) bool {
+		return new(big.Int).Abs(new(big.Int).Sub(reference, result)).Cmp(big.NewInt(10)) <= 0
+	}
+
+	t.Run("Solana nested result", func(t *testing.T) {
+		methodConfigs := []rpctestsconfig.EVMMethodTestConfig{
+			{Method: "getBalance", Protocol: chainprotocol.Solana, ResultPath: "value", CompareFunc: withinTen},
+		}
+		mockCaller := &mocks.EVMMethodCaller{
+			Responses: map[string]requestsrunner.ProviderResult{
+				"reference": {Success: true, Response: []byte(`{"jsonrpc":"2.0","result":{"context":{"slot":1},"value":1000}}`)},
+				"synced":    {Success: true, Response: []byte(`{"jsonrpc":"2.0","result":{"context":{"slot":2},"value":1005}}`)},
+				"behind":    {Success: true, Response: []byte(`{"jsonrpc":"2.0","result":{"context":{"slot":1},"value":900}}`)},
+			},
+		}
+
+		results := ValidateMultipleEVMMethods(context.Background(), methodConfigs, mockCaller, providers, referenceProvider, time.Second)
+		assert.True(t, results["synced"].Valid)
+		assert.False(t, results["behind"].Valid)
+		assert.Equal(t, requestsrunner.ErrorClassResultMismatch, results["behind"].FailedMethods["getBalance"].ErrorClass())
+	})
+
+	t.Run("Bitcoin decimal result", func(t *testing.T) {
+		methodConfigs := []rpctestsconfig.EVMMethodTestConfig{
+			{Method: "getblockcount", Protocol: chainprotocol.Bitcoin, CompareFunc: withinTen},
+		}
+		mockCaller := &mocks.EVMMethodCaller{
+			Responses: map[string]requestsrunner.ProviderResult{
+				"reference": {Success: true, Response: []byte(`{"result":840000,"error":null,"id":1}`)},
+				"synced":    {Success: true, Response: []byte(`{"result":840001,"error":null,"id":1}`)},
+				"behind":    {Success: true, Response: []byte(`{"result":null,"error":{"code":-28,"message":"Loading block index"},"id":1}`)},
+			},
+		}
+
+		results := ValidateMultipleEVMMethods(context.Background(), methodConfigs, mockCaller, providers, referenceProvider, time.Second)
+		assert.True(t, results["synced"].Valid)
+		assert.False(t, results["behind"].Valid)
+	})
+}
+
+func TestValidateMultipleEVMMethodsBatch(t *testing.T) {
+	// newServer answers batches if batching is set, and single requests otherwise, with the block number
+	newServer := func(blockNumber string, batching bool, batches *int32) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := io.ReadAll(r.Body)
+			if !strings.HasPrefix(strings.TrimSpace(string(body)), "[") {
+				w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"` + blockNumber + `"}`))
+				return
+			}
+			atomic.AddInt32(batches, 1)
+			if !batching {
+				w.Write([]byte(`{"jsonrpc":"2.0","id":null,"error":{"code":-32600,"message":"batch requests are not supported"}}`))
+				return
+			}
+			w.Write([]byte(`[{"jsonrpc":"2.0","id":2,"result":"` + blockNumber + `"},{"jsonrpc":"2.0","id":1,"result":"` + blockNumber + `"}]`))
+		}))
+	}
+
+	var batches int32
+	reference := newServer("0x64", true, &batches)
+	defer reference.Close()
+	batching := newServer("0x64", true, &batches)
+	defer batching.Close()
+	single := newServer("0x64", false, &batches)
+	defer single.Close()
+	behind := newServer("0x10", false, &batches)
+	defer behind.Close()
+
+	referenceProvider := rpcprovider.RpcProvider{Name: "reference", URL: reference.URL}
+	providers := []rpcprovider.RpcProvider{
+		{Name: "batching", URL: batching.URL},
+		{Name: "single", URL: single.URL},
+		{Name: "behind", URL: behind.URL},
+	}
+	equal := func(reference, result *big.Int) bool { return reference.Cmp(result) == 0 }
+	methodConfigs := []rpctestsconfig.EVMMethodTestConfig{
+		{Method: "eth_blockNumber", CompareFunc: equal},
+		{Method: "eth_getBlockByNumber", Params: []interface{}{"latest", false}, CompareFunc: equal},
+	}
+
+	results := ValidateMultipleEVMMethods(context.Background(), methodConfigs, requestsrunner.NewRequestsRunner(),
+		providers, referenceProvider, time.Second)
+
+	assert.Equal(t, int32(4), atomic.LoadInt32(&batches), "every provider is sent one batch")
+	assert.True(t, results["batching"].Valid)
+	assert.True(t, *results["batching"].BatchSupported)
+	assert.True(t, results["single"].Valid, "providers without batch support fall back to single calls")
+	assert.False(t, *results["single"].BatchSupported)
+	assert.False(t, results["behind"].Valid)
+	assert.Len(t, results["behind"].FailedMethods, 2)
+}
@@ -0,0 +1,92 @@
+package checker
+
+import (
+	"context"
+	"sync"
+)
+
+// RunFunc runs a validation of one chain, or of all chains if chainID is zero
+type RunFunc func(ctx context.Context, chainID int64) (CycleResult, error)
+
+// Run is a scheduled or running validation
+type Run struct {
+	chainID int64 // Validated chain, zero for all chains; guarded by the mutex of the coordinator
+	done    chan struct{}
+	result  CycleResult
+	err     error
+}
+
+// covers reports whether the run validates the given chain, zero meaning all chains
+func (r *Run) covers(chainID int64) bool {
+	return r.chainID == 0 || r.chainID == chainID
+}
+
+// Done is closed when the run has finished
+func (r *Run) Done() <-chan struct{} {
+	return r.done
+}
+
+// Wait waits until the run has finished and returns its result, or the error of ctx
+func (r *Run) Wait(ctx context.Context) (CycleResult, error) {
+	select {
+	case <-r.done:
+		return r.result, r.err
+	case <-ctx.Done():
+		return CycleResult{}, ctx.Err()
+	}
+}
+
+// Coordinator runs validations one at a time, so that periodic and on-demand runs do not overlap.
+// A request joins the running validation if it covers the requested chain, otherwise it is queued;
+// queued requests for different chains are merged into a validation of all chains.
+type Coordinator struct {
+	mu      sync.Mutex
+	run     RunFunc
+	current *Run // Running validation
+	next    *Run // Validation started once the current one finishes
+}
+
+// NewCoordinator creates a coordinator running validations with run
+func NewCoordinator(run RunFunc) *Coordinator {
+	return &Coordinator{run: run}
+}
+
+// Request schedules a validation of a chain, or of all chains if chainID is zero. It returns the run
+// serving the request and whether the request joined a run that was already running or queued.
+func (c *Coordinator) Request(chainID int64) (*Run, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	switch {
+	case c.current == nil:
+		c.current = &Run{chainID: chainID, done: make(chan struct{})}
+		go c.execute(c.current)
+		return c.current, false
+	case c.current.covers(chainID):
+		return c.current, true
+	case c.next == nil:
+		c.next = &Run{chainID: chainID, done: make(chan struct{})}
+		return c.next, false
+	case !c.next.covers(chainID):
+		c.next.chainID = 0
+	}
+	return c.next, true
+}
+
+// execute runs validations until no request is queued
+func (c *Coordinator) execute(run *Run) {
+	for run != nil {
+		c.mu.Lock()
+		chainID := run.chainID
+		c.mu.Unlock()
+
+		// Runs are shared by requests, so they are not cancelled with any of them
+		run.result, run.err = c.run(context.Background(), chainID)
+		close(run.done)
+
+		c.mu.Lock()
+		run, c.next = c.next, nil
+		c.current = run
+		c.mu.Unlock()
+	}
+}
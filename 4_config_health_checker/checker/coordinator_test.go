@@ -0,0 +1,57 @@
+package checker
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCoordinator(t *testing.T) {
+	var mu sync.Mutex
+	var runs []int64
+	release := make(chan struct{})
+	coordinator := NewCoordinator(func(ctx context.Context, chainID int64) (CycleResult, error) {
+		mu.Lock()
+		runs = append(runs, chainID)
+		mu.Unlock()
+		<-release
+		return CycleResult{ChainID: chainID}, nil
+	})
+
+	first, coalesced := coordinator.Request(1)
+	assert.False(t, coalesced)
+	joined, coalesced := coordinator.Request(1)
+	assert.True(t, coalesced)
+	assert.Same(t, first, joined)
+
+	// Requests for other chains are queued and merged into a run of all chains
+	queued, coalesced := coordinator.Request(2)
+	assert.False(t, coalesced)
+	merged, coalesced := coordinator.Request(3)
+	assert.True(t, coalesced)
+	assert.Same(t, queued, merged)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond)
+	defer cancel()
+	_, err := first.Wait(ctx)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+
+	close(release)
+	cycle, err := first.Wait(context.Background())
+	require.NoError(t, err)
+	assert.EqualValues(t, 1, cycle.ChainID)
+	cycle, err = queued.Wait(context.Background())
+	require.NoError(t, err)
+	assert.Zero(t, cycle.ChainID)
+	assert.Equal(t, []int64{1, 0}, runs)
+
+	// A new request starts a new run once the previous ones finished
+	next, coalesced := coordinator.Request(0)
+	assert.False(t, coalesced)
+	_, err = next.Wait(context.Background())
+	require.NoError(t, err)
+}
@@ -0,0 +1,155 @@
+package checker
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"time"
+
+	"github.com/friofry/config-health-checker/chainconfig"
+	"github.com/friofry/config-health-checker/rpcprovider"
+)
+
+// Health summarises the state of a chain or of a whole validation run
+type Health string
+
+const (
+	HealthHealthy   Health = "healthy"   // Every enabled provider passed validation
+	HealthDegraded  Health = "degraded"  // Some providers failed, but every chain still has a valid provider
+	HealthUnhealthy Health = "unhealthy" // A chain has no valid provider or could not be checked
+)
+
+// severity orders health states from best to worst
+func (h Health) severity() int {
+	switch h {
+	case HealthHealthy:
+		return 0
+	case HealthDegraded:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// CycleResult contains the outcome of a single validation run
+type CycleResult struct {
+	StartedAt time.Time     // Start of the run
+	Duration  time.Duration // Time taken by the run
+	Chains    []ChainResult // Results ordered by chain ID
+	Published bool          // Valid providers were written to the output
+	ChainID   int64         // Only chain validated by CheckChain, zero for a run of all chains
+}
+
+// ChainResult contains the outcome of a single chain
+type ChainResult struct {
+	Chain          chainconfig.ChainConfig             // Chain as configured, including disabled providers
+	Reference      *chainconfig.ReferenceChainConfig   // Reference of the chain, nil if it has none and was not checked
+	Methods        []string                            // Methods the providers were checked with
+	Providers      map[string]ProviderValidationResult // Validation results of the enabled providers by name
+	ValidProviders []rpcprovider.RpcProvider           // Providers written to the output, ordered by priority
+}
+
+// Checked reports whether the chain had a reference and was validated
+func (c ChainResult) Checked() bool {
+	return c.Reference != nil
+}
+
+// Health returns the health of the chain
+func (c ChainResult) Health() Health {
+	if !c.Checked() || len(c.ValidProviders) == 0 {
+		return HealthUnhealthy
+	}
+	for _, result := range c.Providers {
+		if !result.Valid {
+			return HealthDegraded
+		}
+	}
+	return HealthHealthy
+}
+
+// Health returns the worst health of all chains
+func (r CycleResult) Health() Health {
+	health := HealthHealthy
+	for _, chain := range r.Chains {
+		if chainHealth := chain.Health(); chainHealth.severity() > health.severity() {
+			health = chainHealth
+		}
+	}
+	return health
+}
+
+// Check runs a single validation of all chains, logs and records the results, writes the valid providers,
+// notifies the observers and returns the results, including chains that were skipped for lack of a reference
+func (r *ChainValidationRunner) Check(ctx context.Context) (CycleResult, error) {
+	startedAt := time.Now()
+	validChains, results := r.validateChains(ctx)
+	r.logResults(results)
+	r.recordHistory(startedAt, results)
+
+	validChains, err := r.writeValidChains(validChains)
+	validByChain := make(map[int64][]rpcprovider.RpcProvider, len(validChains))
+	for _, chain := range validChains {
+		validByChain[int64(chain.ChainId)] = chain.Providers
+	}
+
+	cycle := CycleResult{StartedAt: startedAt, ChainID: r.onlyChain}
+	for chainId, chainCfg := range r.chainConfigs {
+		chain := ChainResult{
+			Chain:          chainCfg,
+			Providers:      results[chainId],
+			ValidProviders: validByChain[chainId],
+		}
+		if refCfg, exists := r.referenceChainCfgs[chainId]; exists {
+			chain.Reference = &refCfg
+			for _, methodConfig := range r.methodsForProtocol(chainCfg.Protocol.OrDefault()) {
+				chain.Methods = append(chain.Methods, methodConfig.Method)
+			}
+		} else {
+			r.logger.Warn("chain has no reference provider and is not checked", slog.Int64("chainId", chainId))
+		}
+		cycle.Chains = append(cycle.Chains, chain)
+	}
+	sort.Slice(cycle.Chains, func(i, j int) bool {
+		return cycle.Chains[i].Chain.ChainId < cycle.Chains[j].Chain.ChainId
+	})
+
+	cycle.Published = err == nil && r.outputProvidersPath != ""
+	cycle.Duration = time.Since(startedAt)
+	for _, observer := range r.observers {
+		observer.ObserveCycle(ctx, cycle)
+	}
+	return cycle, err
+}
+
+// ErrUnknownChain is returned by CheckChain for chains missing from the default providers
+var ErrUnknownChain = errors.New("unknown chain")
+
+// CheckChain runs Check for a single chain. Only the entry of the chain is replaced in the output,
+// the other chains keep the providers of their last validation.
+func (r *ChainValidationRunner) CheckChain(ctx context.Context, chainId int64) (CycleResult, error) {
+	chainCfg, exists := r.chainConfigs[chainId]
+	if !exists {
+		return CycleResult{}, fmt.Errorf("%w: %d", ErrUnknownChain, chainId)
+	}
+	scoped := *r
+	scoped.chainConfigs = map[int64]chainconfig.ChainConfig{chainId: chainCfg}
+	scoped.onlyChain = chainId
+	return scoped.Check(ctx)
+}
+
+// CycleObserver is notified of the result of every validation run, e.g. to raise alerts
+type CycleObserver interface {
+	ObserveCycle(ctx context.Context, cycle CycleResult)
+}
+
+// AddObserver registers an observer notified at the end of every Check
+func (r *ChainValidationRunner) AddObserver(observer CycleObserver) {
+	r.observers = append(r.observers, observer)
+}
+
+// SetLogger replaces the logger validation results are written to
+func (r *ChainValidationRunner) SetLogger(logger *slog.Logger) {
+	r.logger = logger
+}
@@ -0,0 +1,60 @@
+package checker
+
+import (
+	"sort"
+	"time"
+
+	"github.com/friofry/config-health-checker/history"
+	requestsrunner "github.com/friofry/config-health-checker/requests-runner"
+)
+
+// SetHistory sets the store every validation run is recorded in
+func (r *ChainValidationRunner) SetHistory(store *history.Store) {
+	r.history = store
+}
+
+// recordHistory stores the result of every checked method of every provider
+func (r *ChainValidationRunner) recordHistory(startedAt time.Time, results map[int64]map[string]ProviderValidationResult) {
+	if r.history == nil {
+		return
+	}
+	if err := r.history.Append(historyRecords(startedAt, results)...); err != nil {
+		r.logger.Error("failed to record validation history", "error", err)
+	}
+}
+
+// historyRecords converts validation results into history records ordered by chain, provider and method
+func historyRecords(startedAt time.Time, results map[int64]map[string]ProviderValidationResult) []history.Record {
+	var records []history.Record
+	for chainId, chainResults := range results {
+		for providerName, result := range chainResults {
+			for method, check := range result.Methods {
+				record := history.Record{
+					Time:      startedAt,
+					ChainID:   chainId,
+					Provider:  providerName,
+					Method:    method,
+					Success:   check.Valid && !check.Throttled,
+					Throttled: check.Throttled,
+					LatencyMs: check.Result.ElapsedTime.Milliseconds(),
+				}
+				if check.Error != nil && !record.Success {
+					record.ErrorClass = requestsrunner.ClassifyError(check.Error)
+					record.Error = check.Error.Error()
+				}
+				records = append(records, record)
+			}
+		}
+	}
+	sort.Slice(records, func(i, j int) bool {
+		a, b := records[i], records[j]
+		if a.ChainID != b.ChainID {
+			return a.ChainID < b.ChainID
+		}
+		if a.Provider != b.Provider {
+			return a.Provider < b.Provider
+		}
+		return a.Method < b.Method
+	})
+	return records
+}
@@ -0,0 +1,121 @@
+package checker
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+	"sync"
+	"time"
+
+	requestsrunner "github.com/friofry/config-health-checker/requests-runner"
+	"github.com/friofry/config-health-checker/rpcprovider"
+)
+
+const (
+	// NewHeadsMethod is the method name under which newHeads subscription failures are reported
+	NewHeadsMethod = "eth_subscribe:newHeads"
+
+	blockNumberMethod = "eth_blockNumber"
+)
+
+// ValidateNewHeads subscribes to newHeads on every provider and checks that a head arrives within timeout.
+// When compareFunc is set, the head number must also match the reference block number fetched after the head arrived.
+func ValidateNewHeads(
+	ctx context.Context,
+	subscriber requestsrunner.HeadsSubscriber,
+	caller requestsrunner.EVMMethodCaller,
+	providers []rpcprovider.RpcProvider,
+	referenceProvider rpcprovider.RpcProvider,
+	timeout time.Duration,
+	compareFunc func(reference, result *big.Int) bool,
+) map[string]CheckResult {
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		results = make(map[string]CheckResult, len(providers))
+	)
+
+	for _, provider := range providers {
+		wg.Add(1)
+		go func(provider rpcprovider.RpcProvider) {
+			defer wg.Done()
+			result := validateNewHeads(ctx, subscriber, caller, provider, referenceProvider, timeout, compareFunc)
+
+			mu.Lock()
+			results[provider.Name] = result
+			mu.Unlock()
+		}(provider)
+	}
+	wg.Wait()
+
+	return results
+}
+
+// validateNewHeads checks the newHeads subscription of a single provider
+func validateNewHeads(
+	ctx context.Context,
+	subscriber requestsrunner.HeadsSubscriber,
+	caller requestsrunner.EVMMethodCaller,
+	provider rpcprovider.RpcProvider,
+	referenceProvider rpcprovider.RpcProvider,
+	timeout time.Duration,
+	compareFunc func(reference, result *big.Int) bool,
+) CheckResult {
+	head := subscriber.SubscribeNewHeads(ctx, provider, timeout)
+	result := requestsrunner.ProviderResult{
+		Success:     head.Success,
+		Error:       head.Error,
+		Response:    head.Header,
+		Result:      head.Number,
+		ElapsedTime: head.ElapsedTime,
+	}
+	if !head.Success {
+		return CheckResult{Valid: false, Result: result, Error: head.Error}
+	}
+
+	headNumber, ok := new(big.Int).SetString(strings.TrimPrefix(head.Number, "0x"), 16)
+	if !ok {
+		return CheckResult{
+			Valid:  false,
+			Result: result,
+			Error: &requestsrunner.ProviderError{
+				Class: requestsrunner.ErrorClassMalformedJSON,
+				Err:   fmt.Errorf("invalid head number %q", head.Number),
+			},
+		}
+	}
+	if compareFunc == nil {
+		return CheckResult{Valid: true, Result: result}
+	}
+
+	reference := caller.CallEVMMethod(ctx, referenceProvider, blockNumberMethod, nil, timeout)
+	var referenceNumber *big.Int
+	err := reference.Error
+	if reference.Success {
+		referenceNumber, err = parseJSONRPCResult(reference.Response)
+	}
+	if err != nil {
+		return CheckResult{
+			Valid:  false,
+			Result: result,
+			Error: &requestsrunner.ProviderError{
+				Class: requestsrunner.ErrorClassReferenceFailure,
+				Err:   fmt.Errorf("reference block number unavailable: %w", err),
+			},
+		}
+	}
+
+	if !compareFunc(referenceNumber, headNumber) {
+		return CheckResult{
+			Valid:  false,
+			Result: result,
+			Error: &requestsrunner.ProviderError{
+				Class: requestsrunner.ErrorClassResultMismatch,
+				Err:   fmt.Errorf("head %s differs from reference block %s", headNumber, referenceNumber),
+			},
+		}
+	}
+
+	return CheckResult{Valid: true, Result: result}
+}
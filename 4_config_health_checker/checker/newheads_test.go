@@ -0,0 +1,121 @@
+package checker
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/friofry/config-health-checker/chainconfig"
+	requestsrunner "github.com/friofry/config-health-checker/requests-runner"
+	"github.com/friofry/config-health-checker/rpcprovider"
+	"github.com/friofry/config-health-checker/rpctestsconfig"
+)
+
+// MockHeadsCaller implements EVMMethodCaller and HeadsSubscriber for testing
+type MockHeadsCaller struct {
+	MockEVMMethodCaller
+	heads map[string]requestsrunner.HeadResult
+}
+
+func (m *MockHeadsCaller) SubscribeNewHeads(
+	ctx context.Context,
+	provider rpcprovider.RpcProvider,
+	timeout time.Duration,
+) requestsrunner.HeadResult {
+	return m.heads[provider.Name]
+}
+
+func TestValidateNewHeads(t *testing.T) {
+	referenceProvider := rpcprovider.RpcProvider{Name: "reference"}
+	providers := []rpcprovider.RpcProvider{{Name: "inSync"}, {Name: "behind"}, {Name: "silent"}, {Name: "garbled"}}
+
+	caller := &MockHeadsCaller{
+		MockEVMMethodCaller: MockEVMMethodCaller{
+			results: map[string]requestsrunner.ProviderResult{
+				"reference": {Success: true, Response: []byte(`{"result":"0x64"}`)},
+			},
+		},
+		heads: map[string]requestsrunner.HeadResult{
+			"inSync":  {Success: true, Number: "0x63"},
+			"behind":  {Success: true, Number: "0x50"},
+			"silent":  {Error: &requestsrunner.ProviderError{Class: requestsrunner.ErrorClassTimeout, Err: context.DeadlineExceeded}},
+			"garbled": {Success: true, Number: "latest"},
+		},
+	}
+	compareFunc := func(reference, result *big.Int) bool {
+		diff := new(big.Int).Abs(new(big.Int).Sub(reference, result))
+		return diff.Cmp(big.NewInt(2)) <= 0
+	}
+
+	results := ValidateNewHeads(context.Background(), caller, caller, providers, referenceProvider, time.Second, compareFunc)
+
+	assert.True(t, results["inSync"].Valid)
+	assert.Equal(t, "0x63", results["inSync"].Result.Result)
+	assert.Equal(t, requestsrunner.ErrorClassResultMismatch, requestsrunner.ClassifyError(results["behind"].Error))
+	assert.Equal(t, requestsrunner.ErrorClassTimeout, requestsrunner.ClassifyError(results["silent"].Error))
+	assert.Equal(t, requestsrunner.ErrorClassMalformedJSON, requestsrunner.ClassifyError(results["garbled"].Error))
+
+	caller.results["reference"] = requestsrunner.ProviderResult{Error: errors.New("reference down")}
+	results = ValidateNewHeads(context.Background(), caller, caller, providers[:1], referenceProvider, time.Second, compareFunc)
+	assert.Equal(t, requestsrunner.ErrorClassReferenceFailure, requestsrunner.ClassifyError(results["inSync"].Error))
+
+	// Without a comparison only the arrival of a head is checked
+	results = ValidateNewHeads(context.Background(), caller, caller, providers[:2], referenceProvider, time.Second, nil)
+	assert.True(t, results["behind"].Valid)
+}
+
+func TestChainValidationRunner_NewHeads(t *testing.T) {
+	chainCfgs := map[int64]chainconfig.ChainConfig{
+		1: {
+			Providers: []rpcprovider.RpcProvider{
+				{Name: "http", URL: "https://rpc.example.io"},
+				{Name: "wsHealthy", URL: "wss://healthy.example.io"},
+				{Name: "wsSilent", URL: "wss://silent.example.io"},
+			},
+		},
+	}
+	referenceCfgs := map[int64]chainconfig.ReferenceChainConfig{
+		1: {Provider: rpcprovider.RpcProvider{Name: "reference"}},
+	}
+	methodConfigs := []rpctestsconfig.EVMMethodTestConfig{
+		{
+			Method: "eth_blockNumber",
+			CompareFunc: func(ref, res *big.Int) bool {
+				return ref.Cmp(res) == 0
+			},
+		},
+	}
+
+	response := requestsrunner.ProviderResult{Success: true, Response: []byte(`{"result":"0x64"}`)}
+	caller := &MockHeadsCaller{
+		MockEVMMethodCaller: MockEVMMethodCaller{
+			results: map[string]requestsrunner.ProviderResult{
+				"reference": response,
+				"http":      response,
+				"wsHealthy": response,
+				"wsSilent":  response,
+			},
+		},
+		heads: map[string]requestsrunner.HeadResult{
+			"wsHealthy": {Success: true, Number: "0x64"},
+			"wsSilent":  {Error: &requestsrunner.ProviderError{Class: requestsrunner.ErrorClassTimeout, Err: context.DeadlineExceeded}},
+		},
+	}
+
+	runner := NewChainValidationRunner(chainCfgs, referenceCfgs, methodConfigs, caller, time.Second, "", "")
+	validChains, results := runner.validateChains(context.Background())
+
+	assert.True(t, results[1]["http"].Valid)
+	assert.True(t, results[1]["wsHealthy"].Valid)
+	assert.False(t, results[1]["wsSilent"].Valid)
+	assert.Contains(t, results[1]["wsSilent"].FailedMethods, NewHeadsMethod)
+
+	// The proxy only speaks HTTP, so healthy websocket providers are not written for it
+	if assert.Len(t, validChains, 1) && assert.Len(t, validChains[0].Providers, 1) {
+		assert.Equal(t, "http", validChains[0].Providers[0].Name)
+	}
+}
@@ -0,0 +1,66 @@
+package checker
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/friofry/config-health-checker/configreader"
+	requestsrunner "github.com/friofry/config-health-checker/requests-runner"
+)
+
+// RunnerOptionsFromConfig builds requests runner options from the checker configuration.
+// Fields left at zero take their values from requestsrunner.DefaultRunnerOptions.
+func RunnerOptionsFromConfig(cfg configreader.CheckerConfig) (requestsrunner.RunnerOptions, error) {
+	retryPolicy, err := RetryPolicyFromConfig(cfg.Retry)
+	if err != nil {
+		return requestsrunner.RunnerOptions{}, err
+	}
+
+	breaker := requestsrunner.DefaultCircuitBreakerConfig()
+	breaker.Disabled = cfg.CircuitBreaker.Disabled
+	if cfg.CircuitBreaker.FailureThreshold > 0 {
+		breaker.FailureThreshold = cfg.CircuitBreaker.FailureThreshold
+	}
+	if cfg.CircuitBreaker.OpenSeconds > 0 {
+		breaker.OpenDuration = time.Duration(cfg.CircuitBreaker.OpenSeconds) * time.Second
+	}
+	if cfg.CircuitBreaker.ProbeMethod != "" {
+		breaker.ProbeMethod = cfg.CircuitBreaker.ProbeMethod
+	}
+
+	return requestsrunner.RunnerOptions{
+		RetryPolicy:    retryPolicy,
+		CircuitBreaker: breaker,
+	}, nil
+}
+
+// RetryPolicyFromConfig converts the retry section of the checker configuration into a retry policy.
+// Fields left at zero take their values from requestsrunner.DefaultRetryPolicy.
+func RetryPolicyFromConfig(cfg configreader.RetryConfig) (requestsrunner.RetryPolicy, error) {
+	policy := requestsrunner.DefaultRetryPolicy()
+
+	if cfg.MaxAttempts > 0 {
+		policy.MaxAttempts = cfg.MaxAttempts
+	}
+	if cfg.BaseBackoffMs > 0 {
+		policy.BaseBackoff = time.Duration(cfg.BaseBackoffMs) * time.Millisecond
+	}
+	if cfg.MaxBackoffMs > 0 {
+		policy.MaxBackoff = time.Duration(cfg.MaxBackoffMs) * time.Millisecond
+	}
+	if cfg.MethodDeadlineMs > 0 {
+		policy.MethodDeadline = time.Duration(cfg.MethodDeadlineMs) * time.Millisecond
+	}
+	if cfg.RetryOn != nil {
+		policy.RetryOn = make([]requestsrunner.ErrorClass, 0, len(cfg.RetryOn))
+		for _, name := range cfg.RetryOn {
+			class := requestsrunner.ErrorClass(name)
+			if !class.IsValid() {
+				return requestsrunner.RetryPolicy{}, fmt.Errorf("unknown error class in retry_on: %q", name)
+			}
+			policy.RetryOn = append(policy.RetryOn, class)
+		}
+	}
+
+	return policy, nil
+}
@@ -0,0 +1,46 @@
+package checker
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/friofry/config-health-checker/configreader"
+	requestsrunner "github.com/friofry/config-health-checker/requests-runner"
+)
+
+func TestRetryPolicyFromConfig(t *testing.T) {
+	policy, err := RetryPolicyFromConfig(configreader.RetryConfig{})
+	assert.NoError(t, err)
+	assert.Equal(t, requestsrunner.DefaultRetryPolicy(), policy)
+
+	policy, err = RetryPolicyFromConfig(configreader.RetryConfig{
+		MaxAttempts:      5,
+		BaseBackoffMs:    100,
+		MaxBackoffMs:     1000,
+		RetryOn:          []string{"timeout", "http_5xx"},
+		MethodDeadlineMs: 3000,
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, requestsrunner.RetryPolicy{
+		MaxAttempts:    5,
+		BaseBackoff:    100 * time.Millisecond,
+		MaxBackoff:     time.Second,
+		RetryOn:        []requestsrunner.ErrorClass{requestsrunner.ErrorClassTimeout, requestsrunner.ErrorClassServerError},
+		MethodDeadline: 3 * time.Second,
+	}, policy)
+
+	_, err = RetryPolicyFromConfig(configreader.RetryConfig{RetryOn: []string{"bogus"}})
+	assert.Error(t, err)
+}
+
+func TestRunnerOptionsFromConfig(t *testing.T) {
+	opts, err := RunnerOptionsFromConfig(configreader.CheckerConfig{
+		CircuitBreaker: configreader.CircuitBreakerConfig{FailureThreshold: 5, OpenSeconds: 10},
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 5, opts.CircuitBreaker.FailureThreshold)
+	assert.Equal(t, 10*time.Second, opts.CircuitBreaker.OpenDuration)
+	assert.Equal(t, requestsrunner.DefaultCircuitBreakerConfig().ProbeMethod, opts.CircuitBreaker.ProbeMethod)
+}
@@ -0,0 +1,107 @@
+package checker
+
+import (
+	"log/slog"
+	"sort"
+	"sync"
+
+	"github.com/friofry/config-health-checker/chainconfig"
+	"github.com/friofry/config-health-checker/overrides"
+	"github.com/friofry/config-health-checker/rpcprovider"
+)
+
+// outputMu serialises writes of the output file by validation runs and ApplyOverrides
+var outputMu sync.Mutex
+
+// SetOverrides sets the store of manual provider states honored when valid providers are written
+func (r *ChainValidationRunner) SetOverrides(store *overrides.Store) {
+	r.overrides = store
+}
+
+// HasProvider reports whether the provider files list a provider of the given name for a chain
+func (r *ChainValidationRunner) HasProvider(chainID int64, name string) bool {
+	for _, provider := range r.chainConfigs[chainID].Providers {
+		if provider.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// applyOverrides returns the valid chains with the active overrides applied: providers pinned as unhealthy
+// are removed and enabled HTTP providers pinned as healthy are added. Only chains with a reference are served.
+func (r *ChainValidationRunner) applyOverrides(validChains []chainconfig.ChainConfig) []chainconfig.ChainConfig {
+	if r.overrides == nil {
+		return validChains
+	}
+	active := r.overrides.Active()
+	if len(active) == 0 {
+		return validChains
+	}
+
+	pinned := make(map[int64]map[string]overrides.State)
+	for _, override := range active {
+		if pinned[override.ChainID] == nil {
+			pinned[override.ChainID] = make(map[string]overrides.State)
+		}
+		pinned[override.ChainID][override.Provider] = override.State
+	}
+	valid := make(map[int64]map[string]bool, len(validChains))
+	for _, chain := range validChains {
+		names := make(map[string]bool, len(chain.Providers))
+		for _, provider := range chain.Providers {
+			names[provider.Name] = true
+		}
+		valid[int64(chain.ChainId)] = names
+	}
+
+	var result []chainconfig.ChainConfig
+	for chainId, chainCfg := range r.chainConfigs {
+		if _, exists := r.referenceChainCfgs[chainId]; !exists {
+			continue
+		}
+		var providers []rpcprovider.RpcProvider
+		for _, provider := range rpcprovider.HTTPProviders(rpcprovider.EnabledProviders(chainCfg.Providers)) {
+			isValid := valid[chainId][provider.Name]
+			if state, exists := pinned[chainId][provider.Name]; exists {
+				if pinnedValid := state == overrides.StateHealthy; pinnedValid != isValid {
+					r.logger.Info("provider state overridden", slog.Int64("chainId", chainId),
+						slog.String("provider", provider.Name), slog.String("state", string(state)))
+					isValid = pinnedValid
+				}
+			}
+			if isValid {
+				providers = append(providers, provider)
+			}
+		}
+		if len(providers) == 0 {
+			continue
+		}
+		rpcprovider.SortByPriority(providers)
+		validChain := chainCfg
+		validChain.Providers = providers
+		result = append(result, validChain)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		return result[i].ChainId < result[j].ChainId
+	})
+	return result
+}
+
+// ApplyOverrides rewrites the output file with the active overrides, without waiting for the next
+// validation run. The providers of the output are taken as the last validation results, so providers
+// whose override was removed return to their validated state with the next run.
+func (r *ChainValidationRunner) ApplyOverrides() error {
+	if r.outputProvidersPath == "" {
+		return nil
+	}
+
+	outputMu.Lock()
+	defer outputMu.Unlock()
+	published, err := r.readOutput()
+	if err != nil {
+		return err
+	}
+	_, err = r.writeOutput(published)
+	return err
+}
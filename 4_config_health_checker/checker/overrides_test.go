@@ -0,0 +1,95 @@
+package checker
+
+import (
+	"context"
+	"math/big"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/friofry/config-health-checker/chainconfig"
+	"github.com/friofry/config-health-checker/overrides"
+	requestsrunner "github.com/friofry/config-health-checker/requests-runner"
+	"github.com/friofry/config-health-checker/rpcprovider"
+	"github.com/friofry/config-health-checker/rpctestsconfig"
+	"github.com/friofry/config-health-checker/strictjson"
+)
+
+// providerNames returns the names of the providers of the only chain in the output file
+func providerNames(t *testing.T, outputPath string) []string {
+	chains, err := chainconfig.LoadChains(outputPath, strictjson.ModeStrict)
+	require.NoError(t, err)
+	require.Len(t, chains.Chains, 1)
+	var names []string
+	for _, provider := range chains.Chains[0].Providers {
+		names = append(names, provider.Name)
+	}
+	return names
+}
+
+func TestChainValidationRunner_Overrides(t *testing.T) {
+	chainCfgs := map[int64]chainconfig.ChainConfig{
+		1: {
+			Name:    "ethereum",
+			Network: "mainnet",
+			ChainId: 1,
+			Providers: []rpcprovider.RpcProvider{
+				{Name: "good", URL: "https://good.example.io", AuthType: rpcprovider.NoAuth},
+				{Name: "lagging", URL: "https://lagging.example.io", AuthType: rpcprovider.NoAuth},
+				{Name: "other", URL: "https://other.example.io", AuthType: rpcprovider.NoAuth},
+			},
+		},
+	}
+	referenceCfgs := map[int64]chainconfig.ReferenceChainConfig{
+		1: {ChainId: 1, Provider: rpcprovider.RpcProvider{Name: "reference"}},
+	}
+	methodConfigs := []rpctestsconfig.EVMMethodTestConfig{
+		{Method: "eth_blockNumber", CompareFunc: func(ref, res *big.Int) bool { return ref.Cmp(res) == 0 }},
+	}
+	mockCaller := &MockEVMMethodCaller{
+		results: map[string]requestsrunner.ProviderResult{
+			"reference": {Success: true, Response: []byte(`{"result":"0x2"}`)},
+			"good":      {Success: true, Response: []byte(`{"result":"0x2"}`)},
+			"lagging":   {Success: true, Response: []byte(`{"result":"0x1"}`)},
+			"other":     {Success: true, Response: []byte(`{"result":"0x2"}`)},
+		},
+	}
+
+	dir := t.TempDir()
+	outputPath := filepath.Join(dir, "providers.json")
+	store, err := overrides.Open(filepath.Join(dir, "overrides.json"), "", 0)
+	require.NoError(t, err)
+
+	runner := NewChainValidationRunner(chainCfgs, referenceCfgs, methodConfigs, mockCaller, time.Second, outputPath, "")
+	runner.SetOverrides(store)
+	assert.True(t, runner.HasProvider(1, "lagging"))
+	assert.False(t, runner.HasProvider(1, "reference"))
+	assert.False(t, runner.HasProvider(10, "good"))
+	_, err = runner.Check(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"good", "other"}, providerNames(t, outputPath))
+
+	// Overrides are applied to the output right away
+	_, err = store.Set(overrides.Override{ChainID: 1, Provider: "lagging", State: overrides.StateHealthy, Reason: "test"}, time.Hour)
+	require.NoError(t, err)
+	_, err = store.Set(overrides.Override{ChainID: 1, Provider: "other", State: overrides.StateUnhealthy, Reason: "test"}, time.Hour)
+	require.NoError(t, err)
+	require.NoError(t, runner.ApplyOverrides())
+	assert.Equal(t, []string{"good", "lagging"}, providerNames(t, outputPath))
+
+	// and honored by validation runs
+	cycle, err := runner.Check(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"good", "lagging"}, providerNames(t, outputPath))
+	assert.Len(t, cycle.Chains[0].ValidProviders, 2)
+
+	removed, err := store.Remove(1, "other", "test", "")
+	require.NoError(t, err)
+	assert.True(t, removed)
+	_, err = runner.Check(context.Background())
+	require.NoError(t, err)
+	assert.Equal(t, []string{"good", "lagging", "other"}, providerNames(t, outputPath))
+}
@@ -2,21 +2,27 @@ package checker
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
 	"io"
 	"log/slog"
+	"math/big"
 	"os"
 	"time"
 
 	"github.com/friofry/config-health-checker/chainconfig"
+	"github.com/friofry/config-health-checker/chainprotocol"
 	"github.com/friofry/config-health-checker/configreader"
+	"github.com/friofry/config-health-checker/history"
+	"github.com/friofry/config-health-checker/overrides"
 	requestsrunner "github.com/friofry/config-health-checker/requests-runner"
 	"github.com/friofry/config-health-checker/rpcprovider"
 	"github.com/friofry/config-health-checker/rpctestsconfig"
+	"github.com/friofry/config-health-checker/strictjson"
 )
 
-func loadChainsToMap(filePath string) (map[int64]chainconfig.ChainConfig, error) {
-	chains, err := chainconfig.LoadChains(filePath)
+func loadChainsToMap(filePath string, mode strictjson.Mode) (map[int64]chainconfig.ChainConfig, error) {
+	chains, err := chainconfig.LoadChains(filePath, mode)
 	if err != nil {
 		return nil, err
 	}
@@ -28,8 +34,8 @@ func loadChainsToMap(filePath string) (map[int64]chainconfig.ChainConfig, error)
 	return chainMap, nil
 }
 
-func loadReferenceChainsToMap(filePath string) (map[int64]chainconfig.ReferenceChainConfig, error) {
-	chains, err := chainconfig.LoadReferenceChains(filePath)
+func loadReferenceChainsToMap(filePath string, mode strictjson.Mode) (map[int64]chainconfig.ReferenceChainConfig, error) {
+	chains, err := chainconfig.LoadReferenceChains(filePath, mode)
 	if err != nil {
 		return nil, err
 	}
@@ -49,7 +55,12 @@ type ChainValidationRunner struct {
 	caller              requestsrunner.EVMMethodCaller
 	timeout             time.Duration
 	outputProvidersPath string
+	newHeadsTimeout     time.Duration // Deadline for the first head of websocket providers
 	logger              *slog.Logger
+	history             *history.Store   // Records every validation run, optional
+	overrides           *overrides.Store // Manual provider states honored when writing valid providers, optional
+	onlyChain           int64            // Chain validated by CheckChain, only its entry of the output is replaced
+	observers           []CycleObserver
 }
 
 // NewChainValidationRunner creates a new validation runner
@@ -83,15 +94,28 @@ func NewChainValidationRunner(
 		caller:              caller,
 		timeout:             timeout,
 		outputProvidersPath: outputProvidersPath,
+		newHeadsTimeout:     timeout,
 		logger:              logger,
 	}
 }
 
 // Run executes validation across all configured chains and writes valid providers to output file
 func (r *ChainValidationRunner) Run(ctx context.Context) {
-	validChains, results := r.validateChains(ctx)
-	r.logger.Info("validation results", "results", results)
-	r.writeValidChains(validChains)
+	if _, err := r.Check(ctx); err != nil {
+		r.logger.Error("validation run failed", "error", err)
+	}
+}
+
+// logResults logs validation results per chain. Providers are logged as separate attributes
+// so that their slog.LogValuer implementations redact credentials.
+func (r *ChainValidationRunner) logResults(results map[int64]map[string]ProviderValidationResult) {
+	for chainId, chainResults := range results {
+		providers := make([]any, 0, len(chainResults))
+		for name, result := range chainResults {
+			providers = append(providers, slog.Any(name, result))
+		}
+		r.logger.Info("validation results", "chainId", chainId, slog.Group("providers", providers...))
+	}
 }
 
 // validateChains runs validation for all chains and returns valid chains and validation results
@@ -122,54 +146,163 @@ func (r *ChainValidationRunner) validateChain(
 	chainCfg chainconfig.ChainConfig,
 	refCfg chainconfig.ReferenceChainConfig,
 ) map[string]ProviderValidationResult {
-	return ValidateMultipleEVMMethods(
+	protocol := chainCfg.Protocol.OrDefault()
+	// Disabled providers are skipped and therefore never reach the output
+	chainCfg.Providers = rpcprovider.EnabledProviders(chainCfg.Providers)
+	results := ValidateMultipleEVMMethods(
 		ctx,
-		r.methodConfigs,
-		r.caller,
+		r.methodsForProtocol(protocol),
+		r.callerForProtocol(protocol),
 		chainCfg.Providers,
 		refCfg.Provider,
 		r.timeout,
 	)
+	// newHeads subscriptions are specific to EVM chains
+	if subscriber, ok := r.caller.(requestsrunner.HeadsSubscriber); ok && protocol == chainprotocol.EVM {
+		r.validateNewHeads(ctx, subscriber, chainCfg, refCfg, results)
+	}
+	return results
+}
+
+// methodsForProtocol returns the method configs that apply to chains of the given protocol
+func (r *ChainValidationRunner) methodsForProtocol(protocol chainprotocol.Protocol) []rpctestsconfig.EVMMethodTestConfig {
+	var configs []rpctestsconfig.EVMMethodTestConfig
+	for _, methodConfig := range r.methodConfigs {
+		if methodConfig.Protocol.OrDefault() == protocol {
+			configs = append(configs, methodConfig)
+		}
+	}
+	return configs
+}
+
+// callerForProtocol returns a caller speaking the JSON-RPC dialect of the given protocol
+func (r *ChainValidationRunner) callerForProtocol(protocol chainprotocol.Protocol) requestsrunner.EVMMethodCaller {
+	if protocolCaller, ok := r.caller.(requestsrunner.ProtocolCaller); ok {
+		return protocolCaller.ForProtocol(protocol)
+	}
+	return r.caller
+}
+
+// validateNewHeads checks newHeads subscriptions of the websocket providers of the chain
+// and marks providers whose heads are late or off as invalid
+func (r *ChainValidationRunner) validateNewHeads(
+	ctx context.Context,
+	subscriber requestsrunner.HeadsSubscriber,
+	chainCfg chainconfig.ChainConfig,
+	refCfg chainconfig.ReferenceChainConfig,
+	results map[string]ProviderValidationResult,
+) {
+	var wsProviders []rpcprovider.RpcProvider
+	for _, provider := range chainCfg.Providers {
+		if provider.IsWebSocket() {
+			wsProviders = append(wsProviders, provider)
+		}
+	}
+	if len(wsProviders) == 0 {
+		return
+	}
+
+	// Heads are compared with the tolerance configured for eth_blockNumber, if any
+	var compareFunc func(reference, result *big.Int) bool
+	for _, methodConfig := range r.methodsForProtocol(chainprotocol.EVM) {
+		if methodConfig.Method == blockNumberMethod {
+			compareFunc = methodConfig.CompareFunc
+		}
+	}
+
+	headResults := ValidateNewHeads(ctx, subscriber, r.caller, wsProviders, refCfg.Provider, r.newHeadsTimeout, compareFunc)
+	for name, headResult := range headResults {
+		result, exists := results[name]
+		if headResult.Valid && !exists {
+			continue
+		}
+		if result.Methods == nil {
+			result.Methods = make(map[string]CheckResult)
+		}
+		result.Methods[NewHeadsMethod] = headResult
+		if headResult.Valid {
+			results[name] = result
+			continue
+		}
+		result.Valid = false
+		if result.FailedMethods == nil {
+			result.FailedMethods = make(map[string]FailedMethodResult)
+		}
+		result.FailedMethods[NewHeadsMethod] = FailedMethodResult{
+			Result: headResult.Result,
+			Error:  headResult.Error,
+		}
+		results[name] = result
+	}
 }
 
-// getValidProviders filters and returns valid providers from validation results
+// getValidProviders filters and returns valid providers from validation results.
+// Providers are ordered by priority, so that the proxy tries preferred providers first.
+// Websocket providers are left out, the proxy only speaks HTTP.
 func (r *ChainValidationRunner) getValidProviders(
 	chainCfg chainconfig.ChainConfig,
 	results map[string]ProviderValidationResult,
 ) []rpcprovider.RpcProvider {
 	var validProviders []rpcprovider.RpcProvider
 
-	for providerName, result := range results {
-		if result.Valid {
-			if provider := r.findProviderByName(chainCfg.Providers, providerName); provider != nil {
-				validProviders = append(validProviders, *provider)
-			}
+	for _, provider := range rpcprovider.HTTPProviders(chainCfg.Providers) {
+		if result, exists := results[provider.Name]; exists && result.Valid {
+			validProviders = append(validProviders, provider)
 		}
 	}
 
+	rpcprovider.SortByPriority(validProviders)
 	return validProviders
 }
 
-// findProviderByName finds a provider by name in the list
-func (r *ChainValidationRunner) findProviderByName(
-	providers []rpcprovider.RpcProvider,
-	name string,
-) *rpcprovider.RpcProvider {
-	for _, provider := range providers {
-		if provider.Name == name {
-			return &provider
+// writeValidChains applies the provider overrides to valid chains and writes them to output file
+// if path is specified. It returns the chains with the overrides applied.
+func (r *ChainValidationRunner) writeValidChains(validChains []chainconfig.ChainConfig) ([]chainconfig.ChainConfig, error) {
+	outputMu.Lock()
+	defer outputMu.Unlock()
+	return r.writeOutput(validChains)
+}
+
+// writeOutput applies the overrides and writes valid chains, the caller holds outputMu
+func (r *ChainValidationRunner) writeOutput(validChains []chainconfig.ChainConfig) ([]chainconfig.ChainConfig, error) {
+	validChains = r.applyOverrides(validChains)
+	if r.outputProvidersPath == "" {
+		return validChains, nil
+	}
+
+	output := validChains
+	if r.onlyChain != 0 {
+		published, err := r.readOutput()
+		if err != nil {
+			return validChains, err
 		}
+		output = nil
+		for _, chain := range published {
+			if int64(chain.ChainId) != r.onlyChain {
+				output = append(output, chain)
+			}
+		}
+		output = append(output, validChains...)
+	}
+	if err := chainconfig.WriteChains(r.outputProvidersPath, chainconfig.ChainsConfig{Chains: output}); err != nil {
+		return validChains, fmt.Errorf("failed to write valid providers: %w", err)
 	}
-	return nil
+	return validChains, nil
 }
 
-// writeValidChains writes valid chains to output file if path is specified
-func (r *ChainValidationRunner) writeValidChains(validChains []chainconfig.ChainConfig) {
-	if r.outputProvidersPath != "" {
-		if err := chainconfig.WriteChains(r.outputProvidersPath, chainconfig.ChainsConfig{Chains: validChains}); err != nil {
-			fmt.Printf("Failed to write valid providers: %v\n", err)
+// readOutput returns the chains of the output file, none if it does not exist yet
+func (r *ChainValidationRunner) readOutput() ([]chainconfig.ChainConfig, error) {
+	data, err := os.ReadFile(r.outputProvidersPath)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read valid providers: %w", err)
+	}
+	var published chainconfig.ChainsConfig
+	if len(data) > 0 {
+		if err := json.Unmarshal(data, &published); err != nil {
+			return nil, fmt.Errorf("failed to parse valid providers: %w", err)
 		}
 	}
+	return published.Chains, nil
 }
 
 // NewRunnerFromConfig creates a new ChainValidationRunner from configreader.CheckerConfig
@@ -178,24 +311,24 @@ func NewRunnerFromConfig(
 	caller requestsrunner.EVMMethodCaller,
 ) (*ChainValidationRunner, error) {
 	// Load reference chains
-	referenceChains, err := loadReferenceChainsToMap(cfg.ReferenceProvidersPath)
+	referenceChains, err := loadReferenceChainsToMap(cfg.ReferenceProvidersPath, cfg.UnknownFieldsMode())
 	if err != nil {
 		return nil, fmt.Errorf("failed to load reference chains: %w", err)
 	}
 
 	// Load default chains
-	defaultChains, err := loadChainsToMap(cfg.DefaultProvidersPath)
+	defaultChains, err := loadChainsToMap(cfg.DefaultProvidersPath, cfg.UnknownFieldsMode())
 	if err != nil {
 		return nil, fmt.Errorf("failed to load default chains: %w", err)
 	}
 
 	// Load test configurations
-	testConfigs, err := rpctestsconfig.ReadConfig(cfg.TestsConfigPath)
+	testConfigs, err := rpctestsconfig.ReadConfig(cfg.TestsConfigPath, cfg.UnknownFieldsMode())
 	if err != nil {
 		return nil, fmt.Errorf("failed to load test configurations: %w", err)
 	}
 
-	return NewChainValidationRunner(
+	runner := NewChainValidationRunner(
 		defaultChains,
 		referenceChains,
 		testConfigs,
@@ -203,5 +336,9 @@ func NewRunnerFromConfig(
 		time.Duration(cfg.IntervalSeconds)*time.Second,
 		cfg.OutputProvidersPath,
 		"", // Empty log path for now
-	), nil
+	)
+	if cfg.WebSocket.NewHeadsTimeoutMs > 0 {
+		runner.newHeadsTimeout = time.Duration(cfg.WebSocket.NewHeadsTimeoutMs) * time.Millisecond
+	}
+	return runner, nil
 }
@@ -4,12 +4,17 @@ import (
 	"context"
 	"errors"
 	"math/big"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/friofry/config-health-checker/rpctestsconfig"
+	"github.com/friofry/config-health-checker/strictjson"
 
 	"github.com/friofry/config-health-checker/chainconfig"
+	"github.com/friofry/config-health-checker/chainprotocol"
+	"github.com/friofry/config-health-checker/history"
 	requestsrunner "github.com/friofry/config-health-checker/requests-runner"
 	"github.com/friofry/config-health-checker/rpcprovider"
 	"github.com/stretchr/testify/assert"
@@ -30,6 +35,16 @@ func (m *MockEVMMethodCaller) CallEVMMethod(
 	return m.results[provider.Name]
 }
 
+// MockProtocolCaller returns a caller with the results of the requested protocol
+type MockProtocolCaller struct {
+	MockEVMMethodCaller
+	protocols map[chainprotocol.Protocol]*MockEVMMethodCaller
+}
+
+func (m *MockProtocolCaller) ForProtocol(protocol chainprotocol.Protocol) requestsrunner.EVMMethodCaller {
+	return m.protocols[protocol]
+}
+
 func TestChainValidationRunner_Run(t *testing.T) {
 	// Setup test data
 	chainCfgs := map[int64]chainconfig.ChainConfig{
@@ -230,3 +245,251 @@ func TestChainValidationRunner_ValidateChains(t *testing.T) {
 		assert.Contains(t, chainResults["provider2"].FailedMethods, "eth_blockNumber", "should track failed eth_blockNumber method")
 	})
 }
+
+func TestChainValidationRunner_Protocols(t *testing.T) {
+	chainCfgs := map[int64]chainconfig.ChainConfig{
+		1:   {Providers: []rpcprovider.RpcProvider{{Name: "eth"}}},
+		101: {Protocol: chainprotocol.Solana, Providers: []rpcprovider.RpcProvider{{Name: "sol"}}},
+	}
+	referenceCfgs := map[int64]chainconfig.ReferenceChainConfig{
+		1:   {Provider: rpcprovider.RpcProvider{Name: "eth-reference"}},
+		101: {Provider: rpcprovider.RpcProvider{Name: "sol-reference"}},
+	}
+	equal := func(ref, res *big.Int) bool { return ref.Cmp(res) == 0 }
+	methodConfigs := []rpctestsconfig.EVMMethodTestConfig{
+		{Method: "eth_blockNumber", CompareFunc: equal},
+		{Method: "getSlot", Protocol: chainprotocol.Solana, CompareFunc: equal},
+	}
+
+	caller := &MockProtocolCaller{protocols: map[chainprotocol.Protocol]*MockEVMMethodCaller{
+		chainprotocol.EVM: {results: map[string]requestsrunner.ProviderResult{
+			"eth-reference": {Success: true, Response: []byte(`{"result":"0x10"}`)},
+			"eth":           {Success: true, Response: []byte(`{"result":"0x10"}`)},
+		}},
+		chainprotocol.Solana: {results: map[string]requestsrunner.ProviderResult{
+			"sol-reference": {Success: true, Response: []byte(`{"result":250000000}`)},
+			"sol":           {Success: true, Response: []byte(`{"result":249999999}`)},
+		}},
+	}}
+
+	runner := NewChainValidationRunner(chainCfgs, referenceCfgs, methodConfigs, caller, time.Second, "", "")
+	_, results := runner.validateChains(context.Background())
+
+	assert.True(t, results[1]["eth"].Valid)
+	assert.False(t, results[101]["sol"].Valid)
+	assert.Contains(t, results[101]["sol"].FailedMethods, "getSlot")
+	assert.NotContains(t, results[101]["sol"].FailedMethods, "eth_blockNumber", "EVM methods are not run against Solana chains")
+}
+
+func TestChainValidationRunner_DisabledProvidersAndPriority(t *testing.T) {
+	disabled := false
+	chainCfgs := map[int64]chainconfig.ChainConfig{
+		1: {
+			Providers: []rpcprovider.RpcProvider{
+				{Name: "fallback", Priority: 1, Weight: 2},
+				{Name: "disabled", Enabled: &disabled},
+				{Name: "primary", Weight: 5},
+			},
+		},
+	}
+	referenceCfgs := map[int64]chainconfig.ReferenceChainConfig{
+		1: {Provider: rpcprovider.RpcProvider{Name: "reference"}},
+	}
+	methodConfigs := []rpctestsconfig.EVMMethodTestConfig{
+		{Method: "eth_blockNumber", CompareFunc: func(ref, res *big.Int) bool { return ref.Cmp(res) == 0 }},
+	}
+	mockCaller := &MockEVMMethodCaller{
+		results: map[string]requestsrunner.ProviderResult{
+			"reference": {Success: true, Response: []byte(`{"result":"0x1"}`)},
+			"fallback":  {Success: true, Response: []byte(`{"result":"0x1"}`)},
+			"disabled":  {Success: true, Response: []byte(`{"result":"0x1"}`)},
+			"primary":   {Success: true, Response: []byte(`{"result":"0x1"}`)},
+		},
+	}
+
+	runner := NewChainValidationRunner(chainCfgs, referenceCfgs, methodConfigs, mockCaller, time.Second, "", "")
+	validChains, results := runner.validateChains(context.Background())
+
+	assert.NotContains(t, results[1], "disabled", "disabled providers are not checked")
+	assert.Len(t, validChains, 1)
+	providers := validChains[0].Providers
+	assert.Len(t, providers, 2)
+	assert.Equal(t, "primary", providers[0].Name, "providers are ordered by priority")
+	assert.Equal(t, 5, providers[0].Weight)
+	assert.Equal(t, "fallback", providers[1].Name)
+}
+
+func TestChainValidationRunner_Check(t *testing.T) {
+	chainCfgs := map[int64]chainconfig.ChainConfig{
+		1: {
+			Name:    "ethereum",
+			Network: "mainnet",
+			ChainId: 1,
+			Providers: []rpcprovider.RpcProvider{
+				{Name: "good", URL: "https://good.example.io", AuthType: rpcprovider.NoAuth},
+				{Name: "lagging", URL: "https://lagging.example.io", AuthType: rpcprovider.NoAuth},
+			},
+		},
+		10: {
+			ChainId:   10,
+			Providers: []rpcprovider.RpcProvider{{Name: "unchecked"}},
+		},
+	}
+	referenceCfgs := map[int64]chainconfig.ReferenceChainConfig{
+		1: {ChainId: 1, Provider: rpcprovider.RpcProvider{Name: "reference"}},
+	}
+	methodConfigs := []rpctestsconfig.EVMMethodTestConfig{
+		{Method: "eth_blockNumber", CompareFunc: func(ref, res *big.Int) bool { return ref.Cmp(res) == 0 }},
+	}
+	mockCaller := &MockEVMMethodCaller{
+		results: map[string]requestsrunner.ProviderResult{
+			"reference": {Success: true, Response: []byte(`{"result":"0x2"}`)},
+			"good":      {Success: true, Response: []byte(`{"result":"0x2"}`)},
+			"lagging":   {Success: true, Response: []byte(`{"result":"0x1"}`)},
+		},
+	}
+
+	outputPath := filepath.Join(t.TempDir(), "providers.json")
+	store, err := history.Open(filepath.Join(t.TempDir(), "history.db"), 0)
+	assert.NoError(t, err)
+	defer store.Close()
+
+	runner := NewChainValidationRunner(chainCfgs, referenceCfgs, methodConfigs, mockCaller, time.Second, outputPath, "")
+	runner.SetHistory(store)
+	cycle, err := runner.Check(context.Background())
+	assert.NoError(t, err)
+	assert.FileExists(t, outputPath)
+
+	assert.Len(t, cycle.Chains, 2)
+	checked := cycle.Chains[0]
+	assert.True(t, checked.Checked())
+	assert.Equal(t, []string{"eth_blockNumber"}, checked.Methods)
+	assert.Len(t, checked.ValidProviders, 1)
+	assert.Equal(t, HealthDegraded, checked.Health())
+
+	unchecked := cycle.Chains[1]
+	assert.False(t, unchecked.Checked())
+	assert.Equal(t, HealthUnhealthy, unchecked.Health())
+	assert.Equal(t, HealthUnhealthy, cycle.Health())
+
+	// Every checked method is recorded, including passed ones
+	records := store.Records(history.Query{ChainID: 1})
+	assert.Len(t, records, 2)
+	assert.Equal(t, "good", records[0].Provider)
+	assert.True(t, records[0].Success)
+	assert.Equal(t, "lagging", records[1].Provider)
+	assert.False(t, records[1].Success)
+	assert.NotEmpty(t, records[1].Error)
+	uptime := store.Uptime(history.Query{ChainID: 1})
+	assert.Equal(t, 100.0, uptime.AvailabilityPercent, "a mismatching provider still answered")
+	assert.Equal(t, 50.0, uptime.CorrectnessPercent)
+}
+
+func TestChainValidationRunner_CheckChain(t *testing.T) {
+	chainCfgs := map[int64]chainconfig.ChainConfig{
+		1: {
+			Name:      "ethereum",
+			Network:   "mainnet",
+			ChainId:   1,
+			Providers: []rpcprovider.RpcProvider{{Name: "good", URL: "https://good.example.io", AuthType: rpcprovider.NoAuth}},
+		},
+		10: {
+			Name:      "optimism",
+			Network:   "mainnet",
+			ChainId:   10,
+			Providers: []rpcprovider.RpcProvider{{Name: "lagging", URL: "https://lagging.example.io", AuthType: rpcprovider.NoAuth}},
+		},
+	}
+	referenceCfgs := map[int64]chainconfig.ReferenceChainConfig{
+		1:  {ChainId: 1, Provider: rpcprovider.RpcProvider{Name: "reference"}},
+		10: {ChainId: 10, Provider: rpcprovider.RpcProvider{Name: "reference"}},
+	}
+	methodConfigs := []rpctestsconfig.EVMMethodTestConfig{
+		{Method: "eth_blockNumber", CompareFunc: func(ref, res *big.Int) bool { return ref.Cmp(res) == 0 }},
+	}
+	mockCaller := &MockEVMMethodCaller{
+		results: map[string]requestsrunner.ProviderResult{
+			"reference": {Success: true, Response: []byte(`{"result":"0x2"}`)},
+			"good":      {Success: true, Response: []byte(`{"result":"0x2"}`)},
+			"lagging":   {Success: true, Response: []byte(`{"result":"0x2"}`)},
+		},
+	}
+
+	outputPath := filepath.Join(t.TempDir(), "providers.json")
+	runner := NewChainValidationRunner(chainCfgs, referenceCfgs, methodConfigs, mockCaller, time.Second, outputPath, "")
+	_, err := runner.Check(context.Background())
+	assert.NoError(t, err)
+
+	// Only the validated chain is replaced in the output
+	mockCaller.results["lagging"] = requestsrunner.ProviderResult{Success: true, Response: []byte(`{"result":"0x1"}`)}
+	cycle, err := runner.CheckChain(context.Background(), 10)
+	assert.NoError(t, err)
+	assert.EqualValues(t, 10, cycle.ChainID)
+	assert.Len(t, cycle.Chains, 1)
+	assert.Empty(t, cycle.Chains[0].ValidProviders)
+
+	chains, err := chainconfig.LoadChains(outputPath, strictjson.ModeStrict)
+	assert.NoError(t, err)
+	assert.Len(t, chains.Chains, 1)
+	assert.Equal(t, 1, chains.Chains[0].ChainId)
+
+	_, err = runner.CheckChain(context.Background(), 5)
+	assert.ErrorIs(t, err, ErrUnknownChain)
+}
+
+func TestChainValidationRunner_LogsRedactedResults(t *testing.T) {
+	chainCfgs := map[int64]chainconfig.ChainConfig{
+		1: {
+			Providers: []rpcprovider.RpcProvider{
+				{Name: "provider1", AuthType: rpcprovider.TokenAuth, AuthToken: "secret-token"},
+			},
+		},
+	}
+
+	referenceCfgs := map[int64]chainconfig.ReferenceChainConfig{
+		1: {
+			Provider: rpcprovider.RpcProvider{Name: "reference"},
+		},
+	}
+
+	methodConfigs := []rpctestsconfig.EVMMethodTestConfig{
+		{
+			Method: "eth_blockNumber",
+			CompareFunc: func(ref, res *big.Int) bool {
+				return ref.Cmp(res) == 0
+			},
+		},
+	}
+
+	mockCaller := &MockEVMMethodCaller{
+		results: map[string]requestsrunner.ProviderResult{
+			"reference": {
+				Success:  true,
+				Response: []byte(`{"result":"0x1234"}`),
+			},
+			"provider1": {
+				Success:  true,
+				Response: []byte(`{"result":"0x5678","echo":"secret-token"}`),
+			},
+		},
+	}
+
+	logPath := filepath.Join(t.TempDir(), "checker.log")
+	runner := NewChainValidationRunner(
+		chainCfgs,
+		referenceCfgs,
+		methodConfigs,
+		mockCaller,
+		10*time.Second,
+		"", // Empty output path for tests
+		logPath,
+	)
+	runner.Run(context.Background())
+
+	logs, err := os.ReadFile(logPath)
+	assert.NoError(t, err)
+	assert.Contains(t, string(logs), "validation results")
+	assert.Contains(t, string(logs), "provider1")
+	assert.Contains(t, string(logs), string(requestsrunner.ErrorClassResultMismatch))
+	assert.NotContains(t, string(logs), "secret-token")
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/friofry/config-health-checker/configreader"
+	"github.com/friofry/config-health-checker/sources"
+)
+
+// configFlags reads the checker config the same way for the server and every subcommand:
+// flags take precedence over CHC_* environment variables, which take precedence over the file
+type configFlags struct {
+	path   *string             // Path of the checker config
+	values configreader.Values // Values set by flags
+}
+
+// registerConfigFlags defines -checker-config and a flag per config field on flags,
+// plus the short forms -default-providers, -reference-providers and -tests-config
+func registerConfigFlags(flags *flag.FlagSet) *configFlags {
+	f := &configFlags{
+		path:   flags.String("checker-config", envOr("CHC_CHECKER_CONFIG", "checker_config.json"), "path to checker config (env CHC_CHECKER_CONFIG)"),
+		values: configreader.RegisterFlags(flags),
+	}
+	for name, path := range map[string]string{
+		"default-providers":   "default_providers_path",
+		"reference-providers": "reference_providers_path",
+		"tests-config":        "tests_config_path",
+	} {
+		path := path
+		flags.Func(name, "short form of -"+configreader.FlagName(path), func(value string) error {
+			f.values[path] = value
+			return nil
+		})
+	}
+	return f
+}
+
+// read reads the checker config with the CHC_* environment variables and the parsed flags applied
+func (f *configFlags) read() (*configreader.CheckerConfig, error) {
+	envValues, err := configreader.EnvValues(os.LookupEnv)
+	if err != nil {
+		return nil, err
+	}
+	return configreader.ReadConfig(*f.path, envValues, f.values)
+}
+
+// checkerConfig is the checker config as configured, with the fetcher of its remote provider files
+type checkerConfig struct {
+	*configreader.CheckerConfig
+	sources *sources.Fetcher
+}
+
+// loadConfig reads the checker config of the flags and creates the fetcher of its provider files
+func loadConfig(f *configFlags) (checkerConfig, error) {
+	config, err := f.read()
+	if err != nil {
+		return checkerConfig{}, fmt.Errorf("%s: %w", *f.path, err)
+	}
+	providerSources, err := sources.FromConfig(*config)
+	if err != nil {
+		return checkerConfig{}, fmt.Errorf("invalid sources configuration: %w", err)
+	}
+	return checkerConfig{CheckerConfig: config, sources: providerSources}, nil
+}
+
+// local returns the config with remote provider files fetched again, or replaced by their last good copies
+func (c checkerConfig) local(ctx context.Context) (configreader.CheckerConfig, error) {
+	return c.sources.LocalConfig(ctx, *c.CheckerConfig)
+}
+
+// envOr returns the value of the environment variable name, or fallback if it is not set
+func envOr(name, fallback string) string {
+	if value, ok := os.LookupEnv(name); ok {
+		return value
+	}
+	return fallback
+}
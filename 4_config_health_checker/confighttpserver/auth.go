@@ -0,0 +1,93 @@
+package confighttpserver
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// AuthType defines how clients authenticate to /providers
+type AuthType string
+
+const (
+	NoAuth     AuthType = ""       // Every client is trusted
+	BearerAuth AuthType = "bearer" // HTTP Header "Authorization: Bearer TOKEN"
+	BasicAuth  AuthType = "basic"  // HTTP Header "Authorization: Basic base64(login:password)"
+)
+
+// AuthConfig contains the credentials clients must present to read /providers
+type AuthConfig struct {
+	Type      AuthType
+	Token     string // Token for BearerAuth
+	TokenName string // Identity of BearerAuth clients, "bearer" if empty
+	Login     string // Login for BasicAuth
+	Password  string // Password for BasicAuth
+}
+
+// ParseAuthType converts a configuration value into an AuthType
+func ParseAuthType(value string) (AuthType, error) {
+	switch authType := AuthType(value); authType {
+	case NoAuth, BearerAuth, BasicAuth:
+		return authType, nil
+	default:
+		return "", fmt.Errorf("unknown HTTP server auth type: %q", value)
+	}
+}
+
+// authenticated reports whether the request carries the configured credentials.
+// Without configured auth every request is authenticated.
+func (c AuthConfig) authenticated(r *http.Request) bool {
+	switch c.Type {
+	case BearerAuth:
+		token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		return ok && secureEqual(token, c.Token)
+	case BasicAuth:
+		login, password, ok := r.BasicAuth()
+		// Evaluate both comparisons to keep timing independent of which one fails
+		loginOK := secureEqual(login, c.Login)
+		passwordOK := secureEqual(password, c.Password)
+		return ok && loginOK && passwordOK
+	default:
+		return true
+	}
+}
+
+// identity names the authenticated client of a request: the basic auth login, the common name of
+// the verified client certificate or the bearer token name, and the client address otherwise
+func (c AuthConfig) identity(r *http.Request) string {
+	if c.Type == BasicAuth {
+		return c.Login
+	}
+	if r.TLS != nil && len(r.TLS.VerifiedChains) > 0 && len(r.TLS.VerifiedChains[0]) > 0 {
+		if name := r.TLS.VerifiedChains[0][0].Subject.CommonName; name != "" {
+			return name
+		}
+	}
+	if c.Type == BearerAuth {
+		if c.TokenName != "" {
+			return c.TokenName
+		}
+		return "bearer"
+	}
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		return host
+	}
+	return r.RemoteAddr
+}
+
+// challenge writes a 401 response asking for the configured credentials
+func (c AuthConfig) challenge(w http.ResponseWriter) {
+	switch c.Type {
+	case BearerAuth:
+		w.Header().Set("WWW-Authenticate", `Bearer realm="config-health-checker"`)
+	case BasicAuth:
+		w.Header().Set("WWW-Authenticate", `Basic realm="config-health-checker"`)
+	}
+	http.Error(w, "unauthorized", http.StatusUnauthorized)
+}
+
+func secureEqual(a, b string) bool {
+	return subtle.ConstantTimeCompare([]byte(a), []byte(b)) == 1
+}
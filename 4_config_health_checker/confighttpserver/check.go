@@ -0,0 +1,121 @@
+package confighttpserver
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/friofry/config-health-checker/checker"
+	"github.com/friofry/config-health-checker/report"
+)
+
+// checkResponse is the body of an accepted validation request that does not wait
+type checkResponse struct {
+	ChainID   int64 `json:"chainId,omitempty"` // Requested chain, omitted for all chains
+	Coalesced bool  `json:"coalesced"`         // The request joined a validation that was already running or queued
+}
+
+// CheckHandler serves POST /check and POST /check/{chainId}, which validate all chains or a single chain
+// right away. Requests join a running validation covering their chain. With ?wait=true the response is
+// the report of the validation in the ?format= given (json by default), otherwise 202 Accepted.
+// Clients are authenticated by the server the handler is registered with.
+func CheckHandler(coordinator *checker.Coordinator) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost {
+			w.Header().Set("Allow", http.MethodPost)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		chainID, wait, format, err := parseCheckRequest(r)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		run, coalesced := coordinator.Request(chainID)
+		if !wait {
+			data, _ := json.Marshal(checkResponse{ChainID: chainID, Coalesced: coalesced})
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusAccepted)
+			w.Write(data)
+			return
+		}
+
+		// Validation runs outlive the write timeout of the server
+		if err := http.NewResponseController(w).SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+			http.Error(w, "waiting is not supported", http.StatusInternalServerError)
+			return
+		}
+		cycle, err := run.Wait(r.Context())
+		switch {
+		case r.Context().Err() != nil:
+			return
+		case errors.Is(err, checker.ErrUnknownChain):
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		case err != nil:
+			http.Error(w, fmt.Sprintf("validation failed: %v", err), http.StatusInternalServerError)
+			return
+		}
+
+		// A request for one chain joining a run of all chains gets the report of its chain only
+		if chainID != 0 {
+			if cycle.Chains = chainResults(cycle.Chains, chainID); len(cycle.Chains) == 0 {
+				http.Error(w, fmt.Sprintf("%v: %d", checker.ErrUnknownChain, chainID), http.StatusNotFound)
+				return
+			}
+		}
+		var buf bytes.Buffer
+		if err := report.Write(&buf, format, report.Build(cycle)); err != nil {
+			http.Error(w, "failed to write report", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", format.ContentType())
+		w.Write(buf.Bytes())
+	})
+}
+
+// parseCheckRequest reads the chain from the path and the wait and format parameters
+func parseCheckRequest(r *http.Request) (int64, bool, report.Format, error) {
+	var chainID int64
+	if value := strings.Trim(strings.TrimPrefix(r.URL.Path, "/check"), "/"); value != "" {
+		var err error
+		if chainID, err = strconv.ParseInt(value, 10, 64); err != nil || chainID <= 0 {
+			return 0, false, "", fmt.Errorf("invalid chainId %q", value)
+		}
+	}
+
+	values := r.URL.Query()
+	wait := false
+	if value := values.Get("wait"); value != "" {
+		var err error
+		if wait, err = strconv.ParseBool(value); err != nil {
+			return 0, false, "", fmt.Errorf("invalid wait %q", value)
+		}
+	}
+
+	format := report.FormatJSON
+	if value := values.Get("format"); value != "" {
+		var err error
+		if format, err = report.ParseFormat(value); err != nil {
+			return 0, false, "", err
+		}
+	}
+	return chainID, wait, format, nil
+}
+
+// chainResults returns the results of a single chain
+func chainResults(chains []checker.ChainResult, chainID int64) []checker.ChainResult {
+	for _, chain := range chains {
+		if int64(chain.Chain.ChainId) == chainID {
+			return []checker.ChainResult{chain}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package confighttpserver
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/friofry/config-health-checker/chainconfig"
+	"github.com/friofry/config-health-checker/checker"
+	"github.com/friofry/config-health-checker/report"
+)
+
+func TestCheckHandler(t *testing.T) {
+	coordinator := checker.NewCoordinator(func(ctx context.Context, chainID int64) (checker.CycleResult, error) {
+		chains := []checker.ChainResult{
+			{Chain: chainconfig.ChainConfig{ChainId: 1, Name: "ethereum"}},
+			{Chain: chainconfig.ChainConfig{ChainId: 10, Name: "optimism"}},
+		}
+		if chainID == 5 {
+			return checker.CycleResult{}, checker.ErrUnknownChain
+		}
+		return checker.CycleResult{Chains: chains, ChainID: chainID}, nil
+	})
+	config := DefaultServerConfig("0", writeProviders(t))
+	config.Auth = AuthConfig{Type: BearerAuth, Token: "secret"}
+	server := NewWithConfig(config)
+	server.Handle("/check", CheckHandler(coordinator))
+	server.Handle("/check/", CheckHandler(coordinator))
+	handler := server.(*httpServer).server.Handler
+
+	serve := func(method, target string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, nil)
+		req.Header.Set("Authorization", "Bearer secret")
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("requires auth", func(t *testing.T) {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/check", nil))
+		assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	})
+
+	t.Run("accepted without waiting", func(t *testing.T) {
+		rec := serve(http.MethodPost, "/check/10")
+		require.Equal(t, http.StatusAccepted, rec.Code)
+		assert.JSONEq(t, `{"chainId":10,"coalesced":false}`, rec.Body.String())
+	})
+
+	t.Run("report of a chain", func(t *testing.T) {
+		rec := serve(http.MethodPost, "/check/10?wait=true")
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+		var result report.Report
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &result))
+		require.Len(t, result.Chains, 1)
+		assert.Equal(t, "optimism", result.Chains[0].Name)
+	})
+
+	t.Run("report of all chains", func(t *testing.T) {
+		rec := serve(http.MethodPost, "/check?wait=1&format=junit")
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "application/xml", rec.Header().Get("Content-Type"))
+		assert.Contains(t, rec.Body.String(), "ethereum")
+		assert.Contains(t, rec.Body.String(), "optimism")
+	})
+
+	t.Run("invalid requests", func(t *testing.T) {
+		assert.Equal(t, http.StatusMethodNotAllowed, serve(http.MethodGet, "/check").Code)
+		assert.Equal(t, http.StatusBadRequest, serve(http.MethodPost, "/check/abc").Code)
+		assert.Equal(t, http.StatusBadRequest, serve(http.MethodPost, "/check?wait=maybe").Code)
+		assert.Equal(t, http.StatusBadRequest, serve(http.MethodPost, "/check?format=pdf").Code)
+		assert.Equal(t, http.StatusNotFound, serve(http.MethodPost, "/check/5?wait=true").Code)
+	})
+}
@@ -0,0 +1,98 @@
+package confighttpserver
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/friofry/config-health-checker/events"
+)
+
+// heartbeatInterval is the interval of comments keeping idle event streams open through proxies
+const heartbeatInterval = 15 * time.Second
+
+// EventsHandler serves /events as a stream of server-sent events. Clients that reconnect with
+// the Last-Event-ID header first receive the events they missed, as far as the broker kept them.
+func EventsHandler(broker *events.Broker) http.Handler {
+	return eventsHandler(broker, heartbeatInterval)
+}
+
+func eventsHandler(broker *events.Broker, heartbeat time.Duration) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		var lastEventID uint64
+		if value := r.Header.Get("Last-Event-ID"); value != "" {
+			var err error
+			if lastEventID, err = strconv.ParseUint(value, 10, 64); err != nil {
+				http.Error(w, fmt.Sprintf("invalid Last-Event-ID %q", value), http.StatusBadRequest)
+				return
+			}
+		}
+
+		// Streams outlive the write timeout of the server
+		rc := http.NewResponseController(w)
+		if err := rc.SetWriteDeadline(time.Time{}); err != nil && !errors.Is(err, http.ErrNotSupported) {
+			http.Error(w, "streaming not supported", http.StatusInternalServerError)
+			return
+		}
+
+		ch, missed, unsubscribe := broker.Subscribe(lastEventID)
+		defer unsubscribe()
+
+		w.Header().Set("Content-Type", "text/event-stream")
+		w.Header().Set("Cache-Control", "no-cache")
+		w.Header().Set("X-Accel-Buffering", "no")
+		w.WriteHeader(http.StatusOK)
+		for _, event := range missed {
+			if err := writeEvent(w, event); err != nil {
+				return
+			}
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+
+		ticker := time.NewTicker(heartbeat)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-r.Context().Done():
+				return
+			case event, ok := <-ch:
+				// A closed channel means the client lagged behind, it resumes with Last-Event-ID
+				if !ok {
+					return
+				}
+				if err := writeEvent(w, event); err != nil {
+					return
+				}
+			case <-ticker.C:
+				if _, err := io.WriteString(w, ": heartbeat\n\n"); err != nil {
+					return
+				}
+			}
+			if err := rc.Flush(); err != nil {
+				return
+			}
+		}
+	})
+}
+
+// writeEvent writes an event in the text/event-stream format, its data is the JSON-encoded payload
+func writeEvent(w io.Writer, event events.Event) error {
+	data, err := json.Marshal(event.Data)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event %d: %w", event.ID, err)
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+	return err
+}
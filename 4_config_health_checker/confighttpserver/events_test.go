@@ -0,0 +1,76 @@
+package confighttpserver
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/friofry/config-health-checker/events"
+)
+
+// readFrame reads the lines of one server-sent event frame
+func readFrame(t *testing.T, reader *bufio.Reader) []string {
+	var lines []string
+	for {
+		line, err := reader.ReadString('\n')
+		require.NoError(t, err)
+		line = strings.TrimSuffix(line, "\n")
+		if line == "" {
+			return lines
+		}
+		lines = append(lines, line)
+	}
+}
+
+func TestEventsHandler(t *testing.T) {
+	broker := events.NewBroker(0)
+	broker.Publish(events.TypeCycleFinished, map[string]int{"chains": 1})
+	broker.Publish(events.TypeSnapshotPublished, map[string]bool{"changed": true})
+
+	// The write timeout is lifted for streams
+	server := httptest.NewUnstartedServer(eventsHandler(broker, 50*time.Millisecond))
+	server.Config.WriteTimeout = 10 * time.Millisecond
+	server.Start()
+	defer server.Close()
+
+	req, err := http.NewRequest(http.MethodGet, server.URL, nil)
+	require.NoError(t, err)
+	req.Header.Set("Last-Event-ID", "1")
+	resp, err := http.DefaultClient.Do(req)
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+	assert.Equal(t, "text/event-stream", resp.Header.Get("Content-Type"))
+
+	reader := bufio.NewReader(resp.Body)
+	// Missed events are replayed first
+	assert.Equal(t, []string{"id: 2", "event: snapshot_published", `data: {"changed":true}`}, readFrame(t, reader))
+
+	assert.Equal(t, []string{": heartbeat"}, readFrame(t, reader))
+	broker.Publish(events.TypeCycleFinished, map[string]int{"chains": 2})
+	frame := readFrame(t, reader)
+	for frame[0] == ": heartbeat" {
+		frame = readFrame(t, reader)
+	}
+	assert.Equal(t, []string{"id: 3", "event: cycle_finished", `data: {"chains":2}`}, frame)
+}
+
+func TestEventsHandlerInvalidRequest(t *testing.T) {
+	handler := EventsHandler(events.NewBroker(0))
+
+	rec := httptest.NewRecorder()
+	req := httptest.NewRequest(http.MethodGet, "/events", nil)
+	req.Header.Set("Last-Event-ID", "abc")
+	handler.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/events", nil))
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
@@ -0,0 +1,121 @@
+package confighttpserver
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/friofry/config-health-checker/overrides"
+)
+
+// AuthenticatesClients reports whether clients are authenticated with credentials or client certificates
+func (c ServerConfig) AuthenticatesClients() bool {
+	return c.Auth.Type != NoAuth || c.ClientCAFile != ""
+}
+
+// overrideRequest is the body of POST /admin/overrides
+type overrideRequest struct {
+	ChainID    int64           `json:"chainId"`
+	Provider   string          `json:"provider"`
+	State      overrides.State `json:"state"`
+	Reason     string          `json:"reason"`
+	TTLSeconds int64           `json:"ttlSeconds"`
+	Note       string          `json:"note"` // Free text kept with the override; the actor is the authenticated client
+}
+
+// OverridesHandler serves the admin API of manual provider overrides: GET lists the active overrides,
+// POST pins a provider as healthy or unhealthy and DELETE ?chainId=&provider= removes an override.
+// Clients are authenticated by the server the handler is registered with and auth names them in the
+// audit log. apply is called after every change.
+func OverridesHandler(store *overrides.Store, auth AuthConfig, apply func() error) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+			JSONHandler(func() interface{} { return store.Active() }).ServeHTTP(w, r)
+		case http.MethodPost:
+			setOverride(w, r, store, auth, apply)
+		case http.MethodDelete:
+			removeOverride(w, r, store, auth, apply)
+		default:
+			w.Header().Set("Allow", "GET, POST, DELETE")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		}
+	})
+}
+
+func setOverride(w http.ResponseWriter, r *http.Request, store *overrides.Store, auth AuthConfig, apply func() error) {
+	var req overrideRequest
+	decoder := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64*1024))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&req); err != nil {
+		http.Error(w, fmt.Sprintf("invalid override: %v", err), http.StatusBadRequest)
+		return
+	}
+
+	override, err := store.Set(overrides.Override{
+		ChainID:  req.ChainID,
+		Provider: req.Provider,
+		State:    req.State,
+		Reason:   req.Reason,
+		Actor:    auth.identity(r),
+		Note:     req.Note,
+	}, time.Duration(req.TTLSeconds)*time.Second)
+	if err != nil {
+		status := http.StatusInternalServerError
+		if errors.Is(err, overrides.ErrInvalid) {
+			status = http.StatusBadRequest
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+	applyOverrides(apply)
+
+	data, err := json.MarshalIndent(override, "", "  ")
+	if err != nil {
+		http.Error(w, "failed to marshal response", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	w.Write(data)
+}
+
+func removeOverride(w http.ResponseWriter, r *http.Request, store *overrides.Store, auth AuthConfig, apply func() error) {
+	values := r.URL.Query()
+	chainID, err := strconv.ParseInt(values.Get("chainId"), 10, 64)
+	if err != nil || chainID <= 0 {
+		http.Error(w, fmt.Sprintf("invalid chainId %q", values.Get("chainId")), http.StatusBadRequest)
+		return
+	}
+	provider := values.Get("provider")
+	if provider == "" {
+		http.Error(w, "provider is required", http.StatusBadRequest)
+		return
+	}
+
+	removed, err := store.Remove(chainID, provider, auth.identity(r), values.Get("note"))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	if !removed {
+		http.Error(w, "override not found", http.StatusNotFound)
+		return
+	}
+	applyOverrides(apply)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// applyOverrides publishes a change; a failure is logged, since the next validation run applies it too
+func applyOverrides(apply func() error) {
+	if apply == nil {
+		return
+	}
+	if err := apply(); err != nil {
+		slog.Default().Error("failed to apply provider overrides", "error", err)
+	}
+}
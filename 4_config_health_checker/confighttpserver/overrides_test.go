@@ -0,0 +1,107 @@
+package confighttpserver
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/friofry/config-health-checker/overrides"
+)
+
+func TestOverridesHandler(t *testing.T) {
+	store, err := overrides.Open(filepath.Join(t.TempDir(), "overrides.json"), "", 0)
+	require.NoError(t, err)
+	auth := AuthConfig{Type: BasicAuth, Login: "admin", Password: "secret"}
+	applied := 0
+	config := DefaultServerConfig("0", writeProviders(t))
+	config.Auth = auth
+	server := NewWithConfig(config)
+	server.Handle("/admin/overrides", OverridesHandler(store, auth, func() error {
+		applied++
+		return nil
+	}))
+	handler := server.(*httpServer).server.Handler
+
+	serve := func(method, target, body string, authenticated bool) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, target, strings.NewReader(body))
+		if authenticated {
+			req.SetBasicAuth("admin", "secret")
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, serve(http.MethodGet, "/admin/overrides", "", false).Code)
+
+	rec := serve(http.MethodPost, "/admin/overrides",
+		`{"chainId":1,"provider":"infura","state":"unhealthy","reason":"INC-1","ttlSeconds":600,"note":"alice"}`, true)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+	var override overrides.Override
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &override))
+	assert.Equal(t, "admin", override.Actor)
+	assert.Equal(t, "alice", override.Note)
+	assert.Equal(t, 1, applied)
+
+	for _, body := range []string{
+		`{"chainId":1,"provider":"infura","state":"unhealthy","reason":"INC-1"}`,
+		`{"chainId":1,"provider":"infura","state":"off","reason":"INC-1","ttlSeconds":600}`,
+		`{"chainId":1,"provider":"infura","state":"healthy","reason":"INC-1","ttlSeconds":600,"unknown":1}`,
+		`{"chainId":1,"provider":"infura","state":"healthy","reason":"INC-1","ttlSeconds":600,"actor":"root"}`,
+	} {
+		assert.Equal(t, http.StatusBadRequest, serve(http.MethodPost, "/admin/overrides", body, true).Code, body)
+	}
+
+	rec = serve(http.MethodGet, "/admin/overrides", "", true)
+	require.Equal(t, http.StatusOK, rec.Code)
+	var active []overrides.Override
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &active))
+	require.Len(t, active, 1)
+	assert.Equal(t, overrides.StateUnhealthy, active[0].State)
+
+	assert.Equal(t, http.StatusBadRequest, serve(http.MethodDelete, "/admin/overrides?provider=infura", "", true).Code)
+	assert.Equal(t, http.StatusNoContent, serve(http.MethodDelete, "/admin/overrides?chainId=1&provider=infura", "", true).Code)
+	assert.Equal(t, http.StatusNotFound, serve(http.MethodDelete, "/admin/overrides?chainId=1&provider=infura", "", true).Code)
+	assert.Equal(t, 2, applied)
+	assert.Empty(t, store.Active())
+}
+
+func TestAuthIdentity(t *testing.T) {
+	withCert := func(req *http.Request, commonName string) *http.Request {
+		cert := &x509.Certificate{Subject: pkix.Name{CommonName: commonName}}
+		req.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{{cert}}}
+		return req
+	}
+	newRequest := func() *http.Request {
+		req := httptest.NewRequest(http.MethodPost, "/admin/overrides", nil)
+		req.RemoteAddr = "10.0.0.1:4321"
+		return req
+	}
+
+	tests := []struct {
+		name     string
+		auth     AuthConfig
+		request  *http.Request
+		expected string
+	}{
+		{name: "basic auth login", auth: AuthConfig{Type: BasicAuth, Login: "admin"}, request: withCert(newRequest(), "ops"), expected: "admin"},
+		{name: "client certificate", auth: AuthConfig{Type: BearerAuth, TokenName: "ci"}, request: withCert(newRequest(), "ops"), expected: "ops"},
+		{name: "bearer token name", auth: AuthConfig{Type: BearerAuth, TokenName: "ci"}, request: newRequest(), expected: "ci"},
+		{name: "unnamed bearer token", auth: AuthConfig{Type: BearerAuth}, request: newRequest(), expected: "bearer"},
+		{name: "client address", auth: AuthConfig{}, request: newRequest(), expected: "10.0.0.1"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.expected, tt.auth.identity(tt.request))
+		})
+	}
+}
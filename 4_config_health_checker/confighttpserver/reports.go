@@ -0,0 +1,68 @@
+package confighttpserver
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/friofry/config-health-checker/history"
+)
+
+// DefaultUptimeRange is the time range of an uptime report without "from", and of the uptime in /status
+const DefaultUptimeRange = 24 * time.Hour
+
+// UptimeHandler serves /reports/uptime?from=&to=&chainId= from the validation history.
+// Times are RFC 3339 or Unix seconds, "to" defaults to now and "from" to 24 hours before "to".
+func UptimeHandler(store *history.Store) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query, err := parseUptimeQuery(r, time.Now())
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		JSONHandler(func() interface{} { return store.UptimeReport(query) }).ServeHTTP(w, r)
+	})
+}
+
+// parseUptimeQuery reads the time range and chain of an uptime report request
+func parseUptimeQuery(r *http.Request, now time.Time) (history.Query, error) {
+	values := r.URL.Query()
+	query := history.Query{To: now}
+
+	var err error
+	if value := values.Get("to"); value != "" {
+		if query.To, err = parseTime(value); err != nil {
+			return history.Query{}, fmt.Errorf("invalid to: %w", err)
+		}
+	}
+	query.From = query.To.Add(-DefaultUptimeRange)
+	if value := values.Get("from"); value != "" {
+		if query.From, err = parseTime(value); err != nil {
+			return history.Query{}, fmt.Errorf("invalid from: %w", err)
+		}
+	}
+	if !query.From.Before(query.To) {
+		return history.Query{}, errors.New("from must be before to")
+	}
+
+	if value := values.Get("chainId"); value != "" {
+		if query.ChainID, err = strconv.ParseInt(value, 10, 64); err != nil || query.ChainID <= 0 {
+			return history.Query{}, fmt.Errorf("invalid chainId %q", value)
+		}
+	}
+	return query, nil
+}
+
+// parseTime parses an RFC 3339 timestamp or Unix seconds
+func parseTime(value string) (time.Time, error) {
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(seconds, 0).UTC(), nil
+	}
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("expected RFC 3339 time or Unix seconds, got %q", value)
+	}
+	return t, nil
+}
@@ -0,0 +1,67 @@
+package confighttpserver
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/friofry/config-health-checker/history"
+	requestsrunner "github.com/friofry/config-health-checker/requests-runner"
+)
+
+func TestUptimeHandler(t *testing.T) {
+	store, err := history.Open(filepath.Join(t.TempDir(), "history.db"), 0)
+	require.NoError(t, err)
+	defer store.Close()
+
+	recent := time.Now().Add(-time.Hour).UTC().Truncate(time.Second)
+	require.NoError(t, store.Append(
+		history.Record{Time: recent, ChainID: 1, Provider: "infura", Method: "eth_blockNumber", Success: true, LatencyMs: 10},
+		history.Record{Time: recent, ChainID: 10, Provider: "public", Method: "eth_blockNumber", ErrorClass: requestsrunner.ErrorClassTimeout},
+	))
+	handler := UptimeHandler(store)
+
+	get := func(target string) *httptest.ResponseRecorder {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, target, nil))
+		return rec
+	}
+
+	t.Run("last 24 hours by default", func(t *testing.T) {
+		rec := get("/reports/uptime")
+		require.Equal(t, http.StatusOK, rec.Code)
+		var report history.UptimeReport
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+		assert.Len(t, report.Chains, 2)
+		assert.Equal(t, 24*time.Hour, report.To.Sub(report.From))
+	})
+
+	t.Run("chain and time range", func(t *testing.T) {
+		from := recent.Add(-time.Minute).Format(time.RFC3339)
+		to := strconv.FormatInt(recent.Add(time.Minute).Unix(), 10)
+		rec := get("/reports/uptime?chainId=10&from=" + from + "&to=" + to)
+		require.Equal(t, http.StatusOK, rec.Code)
+		var report history.UptimeReport
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+		require.Len(t, report.Chains, 1)
+		assert.EqualValues(t, 10, report.Chains[0].ChainID)
+		assert.Equal(t, 1, report.Chains[0].Providers[0].Unavailable)
+	})
+
+	t.Run("invalid parameters", func(t *testing.T) {
+		for _, target := range []string{
+			"/reports/uptime?chainId=abc",
+			"/reports/uptime?from=yesterday",
+			"/reports/uptime?from=2026-01-02T00:00:00Z&to=2026-01-01T00:00:00Z",
+		} {
+			assert.Equal(t, http.StatusBadRequest, get(target).Code, target)
+		}
+	})
+}
@@ -2,17 +2,55 @@ package confighttpserver
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
 	"log/slog"
 	"net/http"
 	"os"
 	"time"
+
+	"github.com/friofry/config-health-checker/chainconfig"
+	"github.com/friofry/config-health-checker/configreader"
+	"github.com/friofry/config-health-checker/rpcprovider"
+)
+
+// CredentialsMode defines how provider credentials are served by /providers
+type CredentialsMode string
+
+const (
+	CredentialsFull CredentialsMode = "full" // Credentials are served as stored
+	CredentialsMask CredentialsMode = "mask" // Credentials are replaced with a placeholder
+	CredentialsOmit CredentialsMode = "omit" // Credentials and custom headers are removed
 )
 
+// redacts reports whether credentials are masked or omitted
+func (m CredentialsMode) redacts() bool {
+	return m == CredentialsMask || m == CredentialsOmit
+}
+
+// ParseCredentialsMode converts a configuration value into a CredentialsMode, empty means full
+func ParseCredentialsMode(value string) (CredentialsMode, error) {
+	switch mode := CredentialsMode(value); mode {
+	case "":
+		return CredentialsFull, nil
+	case CredentialsFull, CredentialsMask, CredentialsOmit:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown providers credentials mode: %q", value)
+	}
+}
+
 // ServerConfig contains configuration for the HTTP server
 type ServerConfig struct {
 	Port          string
 	ProvidersPath string
+	Credentials   CredentialsMode // How provider credentials are served to unauthenticated /providers clients
+	Auth          AuthConfig      // Credentials required for every route but the public ones, and to read /providers with credentials
+	TLSCertFile   string          // TLS certificate, reloaded when the file changes; TLS is off when empty
+	TLSKeyFile    string          // TLS private key, reloaded when the file changes
+	ClientCAFile  string          // CA bundle used to require and verify client certificates (mTLS)
 	ReadTimeout   time.Duration
 	WriteTimeout  time.Duration
 	IdleTimeout   time.Duration
@@ -28,11 +66,13 @@ type Provider struct {
 type Server interface {
 	Start() error
 	Stop() error
+	Handle(pattern string, handler http.Handler)
 }
 
 type httpServer struct {
 	config        ServerConfig
 	server        *http.Server
+	mux           *http.ServeMux
 	logger        *slog.Logger
 	providers     []Provider
 	lastReload    time.Time
@@ -40,36 +80,97 @@ type httpServer struct {
 }
 
 func New(port, providersPath string) Server {
+	return NewWithConfig(DefaultServerConfig(port, providersPath))
+}
+
+// DefaultServerConfig returns the configuration used by New
+func DefaultServerConfig(port, providersPath string) ServerConfig {
+	return ServerConfig{
+		Port:          port,
+		ProvidersPath: providersPath,
+		Credentials:   CredentialsFull,
+		ReadTimeout:   5 * time.Second,
+		WriteTimeout:  10 * time.Second,
+		IdleTimeout:   15 * time.Second,
+	}
+}
+
+// ServerConfigFromConfig builds the server configuration from the http_server section of the checker configuration.
+// Secret references in auth credentials are resolved.
+func ServerConfigFromConfig(port string, cfg configreader.CheckerConfig) (ServerConfig, error) {
+	config := DefaultServerConfig(port, cfg.OutputProvidersPath)
+	httpCfg := cfg.HTTPServer
+
+	credentials, err := ParseCredentialsMode(httpCfg.ProvidersCredentials)
+	if err != nil {
+		return ServerConfig{}, err
+	}
+	config.Credentials = credentials
+
+	authType, err := ParseAuthType(httpCfg.AuthType)
+	if err != nil {
+		return ServerConfig{}, err
+	}
+	config.Auth = AuthConfig{Type: authType, TokenName: httpCfg.AuthTokenName, Login: httpCfg.AuthLogin}
+	if config.Auth.Token, err = rpcprovider.ResolveSecret(httpCfg.AuthToken); err != nil {
+		return ServerConfig{}, fmt.Errorf("failed to resolve auth_token: %w", err)
+	}
+	if config.Auth.Password, err = rpcprovider.ResolveSecret(httpCfg.AuthPassword); err != nil {
+		return ServerConfig{}, fmt.Errorf("failed to resolve auth_password: %w", err)
+	}
+	switch {
+	case authType == BearerAuth && config.Auth.Token == "":
+		return ServerConfig{}, errors.New("bearer auth requires auth_token")
+	case authType == BasicAuth && (config.Auth.Login == "" || config.Auth.Password == ""):
+		return ServerConfig{}, errors.New("basic auth requires auth_login and auth_password")
+	}
+
+	config.TLSCertFile = httpCfg.TLSCertPath
+	config.TLSKeyFile = httpCfg.TLSKeyPath
+	config.ClientCAFile = httpCfg.ClientCAPath
+
+	return config, nil
+}
+
+// NewWithConfig creates a server from a full configuration
+func NewWithConfig(config ServerConfig) Server {
 	mux := http.NewServeMux()
 	srv := &http.Server{
-		Addr:    ":" + port,
+		Addr:    ":" + config.Port,
 		Handler: mux,
 	}
 
 	s := &httpServer{
-		config: ServerConfig{
-			Port:          port,
-			ProvidersPath: providersPath,
-			ReadTimeout:   5 * time.Second,
-			WriteTimeout:  10 * time.Second,
-			IdleTimeout:   15 * time.Second,
-		},
+		config: config,
 		server: srv,
+		mux:    mux,
 		logger: slog.Default(),
 	}
 
 	mux.HandleFunc("/providers", s.providersHandler)
-	mux.HandleFunc("/health", s.healthHandler)
+	s.Handle("/health", http.HandlerFunc(s.healthHandler))
 
 	return s
 }
 
 func (s *httpServer) Start() error {
-	s.logger.Info("starting config HTTP server", "port", s.config.Port)
 	s.server.ReadTimeout = s.config.ReadTimeout
 	s.server.WriteTimeout = s.config.WriteTimeout
 	s.server.IdleTimeout = s.config.IdleTimeout
-	return s.server.ListenAndServe()
+
+	if !s.config.tlsEnabled() {
+		s.logger.Info("starting config HTTP server", "port", s.config.Port)
+		return s.server.ListenAndServe()
+	}
+
+	tlsConfig, err := newTLSConfig(s.config)
+	if err != nil {
+		return fmt.Errorf("failed to configure TLS: %w", err)
+	}
+	s.server.TLSConfig = tlsConfig
+	s.logger.Info("starting config HTTPS server", "port", s.config.Port, "mtls", s.config.ClientCAFile != "")
+	// Certificates are provided by tlsConfig.GetCertificate
+	return s.server.ListenAndServeTLS("", "")
 }
 
 func (s *httpServer) Stop() error {
@@ -79,7 +180,61 @@ func (s *httpServer) Stop() error {
 	return s.server.Shutdown(context.Background())
 }
 
+// publicRoutes are served without authentication. /providers is not listed, as it decides itself:
+// unauthenticated clients get redacted providers if providers_credentials is "mask" or "omit".
+var publicRoutes = map[string]bool{
+	"/health": true, // Liveness probes
+}
+
+// Handle registers an additional handler for the given pattern.
+// Unless the pattern is a public route, requests must carry the configured credentials.
+func (s *httpServer) Handle(pattern string, handler http.Handler) {
+	if !publicRoutes[pattern] {
+		handler = s.requireAuth(handler)
+	}
+	s.mux.Handle(pattern, handler)
+}
+
+// requireAuth rejects requests without the configured credentials
+func (s *httpServer) requireAuth(handler http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !s.config.Auth.authenticated(r) {
+			s.config.Auth.challenge(w)
+			return
+		}
+		handler.ServeHTTP(w, r)
+	})
+}
+
+// JSONHandler returns a handler that serves the value returned by fn as JSON
+func JSONHandler(fn func() interface{}) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		data, err := json.MarshalIndent(fn(), "", "  ")
+		if err != nil {
+			slog.Default().Error("failed to marshal response", "error", err)
+			http.Error(w, "failed to marshal response", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(data)
+	})
+}
+
 func (s *httpServer) providersHandler(w http.ResponseWriter, r *http.Request) {
+	// Unauthenticated clients get credentials masked or omitted if configured, otherwise they are rejected
+	if !s.config.Auth.authenticated(r) {
+		if !s.config.Credentials.redacts() {
+			s.config.Auth.challenge(w)
+			return
+		}
+		s.redactedProvidersHandler(w, r)
+		return
+	}
+	if s.config.Auth.Type == NoAuth && s.config.Credentials.redacts() {
+		s.redactedProvidersHandler(w, r)
+		return
+	}
+
 	f, err := os.Open(s.config.ProvidersPath)
 	if err != nil {
 		s.logger.Error("failed to open providers file", "error", err)
@@ -96,6 +251,37 @@ func (s *httpServer) providersHandler(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
+// redactedProvidersHandler serves the providers file with credentials masked or omitted
+func (s *httpServer) redactedProvidersHandler(w http.ResponseWriter, r *http.Request) {
+	data, err := os.ReadFile(s.config.ProvidersPath)
+	if err != nil {
+		s.logger.Error("failed to open providers file", "error", err)
+		http.Error(w, "failed to open providers file", http.StatusInternalServerError)
+		return
+	}
+
+	var chains chainconfig.ChainsConfig
+	if err := json.Unmarshal(data, &chains); err != nil {
+		s.logger.Error("failed to parse providers file", "error", err)
+		http.Error(w, "failed to parse providers file", http.StatusInternalServerError)
+		return
+	}
+
+	for i := range chains.Chains {
+		providers := make([]rpcprovider.RpcProvider, len(chains.Chains[i].Providers))
+		for j, provider := range chains.Chains[i].Providers {
+			if s.config.Credentials == CredentialsOmit {
+				providers[j] = provider.WithoutCredentials()
+			} else {
+				providers[j] = provider.Redacted()
+			}
+		}
+		chains.Chains[i].Providers = providers
+	}
+
+	JSONHandler(func() interface{} { return chains }).ServeHTTP(w, r)
+}
+
 func (s *httpServer) healthHandler(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 	w.Write([]byte("ok"))
@@ -0,0 +1,289 @@
+package confighttpserver
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"io"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/friofry/config-health-checker/configreader"
+)
+
+const testProviders = `{
+  "chains": [
+    {
+      "name": "ethereum",
+      "network": "mainnet",
+      "chainId": 1,
+      "providers": [
+        {
+          "name": "infura",
+          "url": "https://mainnet.infura.io/v3",
+          "authType": "token-auth",
+          "authToken": "secret-token"
+        }
+      ]
+    }
+  ]
+}`
+
+func writeProviders(t *testing.T) string {
+	path := filepath.Join(t.TempDir(), "providers.json")
+	require.NoError(t, os.WriteFile(path, []byte(testProviders), 0644))
+	return path
+}
+
+func getProviders(t *testing.T, handler http.Handler, setup func(r *http.Request)) (int, string) {
+	req := httptest.NewRequest(http.MethodGet, "/providers", nil)
+	if setup != nil {
+		setup(req)
+	}
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	return rec.Code, rec.Body.String()
+}
+
+func TestProvidersAuth(t *testing.T) {
+	providersPath := writeProviders(t)
+
+	t.Run("Bearer auth", func(t *testing.T) {
+		config := DefaultServerConfig("0", providersPath)
+		config.Auth = AuthConfig{Type: BearerAuth, Token: "server-token"}
+		handler := NewWithConfig(config).(*httpServer).server.Handler
+
+		code, _ := getProviders(t, handler, nil)
+		assert.Equal(t, http.StatusUnauthorized, code)
+
+		code, _ = getProviders(t, handler, func(r *http.Request) { r.Header.Set("Authorization", "Bearer wrong") })
+		assert.Equal(t, http.StatusUnauthorized, code)
+
+		code, body := getProviders(t, handler, func(r *http.Request) { r.Header.Set("Authorization", "Bearer server-token") })
+		assert.Equal(t, http.StatusOK, code)
+		assert.Contains(t, body, "secret-token")
+	})
+
+	t.Run("Basic auth", func(t *testing.T) {
+		config := DefaultServerConfig("0", providersPath)
+		config.Auth = AuthConfig{Type: BasicAuth, Login: "proxy", Password: "pass"}
+		handler := NewWithConfig(config).(*httpServer).server.Handler
+
+		code, _ := getProviders(t, handler, func(r *http.Request) { r.SetBasicAuth("proxy", "wrong") })
+		assert.Equal(t, http.StatusUnauthorized, code)
+
+		code, body := getProviders(t, handler, func(r *http.Request) { r.SetBasicAuth("proxy", "pass") })
+		assert.Equal(t, http.StatusOK, code)
+		assert.Contains(t, body, "secret-token")
+	})
+
+	t.Run("Unauthenticated clients get masked credentials", func(t *testing.T) {
+		config := DefaultServerConfig("0", providersPath)
+		config.Auth = AuthConfig{Type: BearerAuth, Token: "server-token"}
+		config.Credentials = CredentialsMask
+		handler := NewWithConfig(config).(*httpServer).server.Handler
+
+		code, body := getProviders(t, handler, nil)
+		assert.Equal(t, http.StatusOK, code)
+		assert.NotContains(t, body, "secret-token")
+		assert.Contains(t, body, "infura")
+
+		code, body = getProviders(t, handler, func(r *http.Request) { r.Header.Set("Authorization", "Bearer server-token") })
+		assert.Equal(t, http.StatusOK, code)
+		assert.Contains(t, body, "secret-token")
+	})
+
+	t.Run("Omitted credentials without auth", func(t *testing.T) {
+		config := DefaultServerConfig("0", providersPath)
+		config.Credentials = CredentialsOmit
+		handler := NewWithConfig(config).(*httpServer).server.Handler
+
+		code, body := getProviders(t, handler, nil)
+		assert.Equal(t, http.StatusOK, code)
+		assert.NotContains(t, body, "secret-token")
+		assert.NotContains(t, body, "REDACTED")
+	})
+}
+
+func TestRoutesAuth(t *testing.T) {
+	config := DefaultServerConfig("0", writeProviders(t))
+	config.Auth = AuthConfig{Type: BearerAuth, Token: "server-token"}
+	server := NewWithConfig(config)
+	server.Handle("/status", JSONHandler(func() interface{} { return map[string]string{"state": "ok"} }))
+	handler := server.(*httpServer).server.Handler
+
+	get := func(path, token string) int {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		if token != "" {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusUnauthorized, get("/status", ""))
+	assert.Equal(t, http.StatusUnauthorized, get("/status", "wrong"))
+	assert.Equal(t, http.StatusOK, get("/status", "server-token"))
+
+	// Public routes are served to everyone
+	for route := range publicRoutes {
+		assert.Equal(t, http.StatusOK, get(route, ""), route)
+	}
+}
+
+func TestServerConfigFromConfig(t *testing.T) {
+	t.Setenv("CHC_TEST_SERVER_TOKEN", "resolved-token")
+
+	config, err := ServerConfigFromConfig("8080", configreader.CheckerConfig{
+		OutputProvidersPath: "providers.json",
+		HTTPServer: configreader.HTTPServerConfig{
+			ProvidersCredentials: "mask",
+			AuthType:             "bearer",
+			AuthToken:            "env:CHC_TEST_SERVER_TOKEN",
+		},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, CredentialsMask, config.Credentials)
+	assert.Equal(t, AuthConfig{Type: BearerAuth, Token: "resolved-token"}, config.Auth)
+
+	invalid := []configreader.HTTPServerConfig{
+		{ProvidersCredentials: "partial"},
+		{AuthType: "digest"},
+		{AuthType: "bearer"},
+		{AuthType: "basic", AuthLogin: "proxy"},
+		{AuthType: "bearer", AuthToken: "env:CHC_TEST_MISSING"},
+	}
+	for _, httpCfg := range invalid {
+		_, err := ServerConfigFromConfig("8080", configreader.CheckerConfig{HTTPServer: httpCfg})
+		assert.Error(t, err, "%+v", httpCfg)
+	}
+}
+
+// writeCertificate writes a self-signed certificate for commonName, signed by parent if given
+func writeCertificate(
+	t *testing.T,
+	dir, name, commonName string,
+	parent *x509.Certificate,
+	parentKey *ecdsa.PrivateKey,
+) (*x509.Certificate, *ecdsa.PrivateKey, string, string) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          serial,
+		Subject:               pkix.Name{CommonName: commonName},
+		DNSNames:              []string{"localhost"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:           []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		BasicConstraintsValid: true,
+		IsCA:                  parent == nil,
+	}
+	if parent == nil {
+		parent, parentKey = template, key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, parent, &key.PublicKey, parentKey)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certPath := filepath.Join(dir, name+".crt")
+	keyPath := filepath.Join(dir, name+".key")
+	require.NoError(t, os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), 0644))
+	require.NoError(t, os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER}), 0600))
+	return cert, key, certPath, keyPath
+}
+
+func TestCertReloader(t *testing.T) {
+	dir := t.TempDir()
+	first, _, certPath, keyPath := writeCertificate(t, dir, "server", "first", nil, nil)
+
+	reloader, err := newCertReloader(certPath, keyPath)
+	require.NoError(t, err)
+	cert, err := reloader.GetCertificate(nil)
+	require.NoError(t, err)
+	assert.Equal(t, first.Raw, cert.Certificate[0])
+
+	// Replace the certificate on disk with a newer modification time
+	second, _, _, _ := writeCertificate(t, dir, "server", "second", nil, nil)
+	future := time.Now().Add(time.Minute)
+	require.NoError(t, os.Chtimes(certPath, future, future))
+	require.NoError(t, os.Chtimes(keyPath, future, future))
+
+	cert, err = reloader.GetCertificate(nil)
+	require.NoError(t, err)
+	assert.Equal(t, second.Raw, cert.Certificate[0])
+
+	// A broken certificate keeps the last good one
+	require.NoError(t, os.WriteFile(certPath, []byte("broken"), 0644))
+	later := future.Add(time.Minute)
+	require.NoError(t, os.Chtimes(certPath, later, later))
+	cert, err = reloader.GetCertificate(nil)
+	require.NoError(t, err)
+	assert.Equal(t, second.Raw, cert.Certificate[0])
+}
+
+func TestMutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca, caKey, caPath, _ := writeCertificate(t, dir, "ca", "ca", nil, nil)
+	_, _, serverCert, serverKey := writeCertificate(t, dir, "server", "localhost", ca, caKey)
+	_, _, clientCert, clientKey := writeCertificate(t, dir, "client", "proxy", ca, caKey)
+
+	config := DefaultServerConfig("0", writeProviders(t))
+	config.TLSCertFile = serverCert
+	config.TLSKeyFile = serverKey
+	config.ClientCAFile = caPath
+	tlsConfig, err := newTLSConfig(config)
+	require.NoError(t, err)
+
+	server := httptest.NewUnstartedServer(NewWithConfig(config).(*httpServer).server.Handler)
+	server.TLS = tlsConfig
+	server.StartTLS()
+	defer server.Close()
+
+	roots := x509.NewCertPool()
+	roots.AddCert(ca)
+	// httptest adds its own certificate, which is only used when the client sends no server name
+	const serverName = "localhost"
+
+	t.Run("Client without certificate is rejected", func(t *testing.T) {
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: roots, ServerName: serverName}}}
+		_, err := client.Get(server.URL + "/providers")
+		assert.Error(t, err)
+	})
+
+	t.Run("Client with certificate is accepted", func(t *testing.T) {
+		cert, err := tls.LoadX509KeyPair(clientCert, clientKey)
+		require.NoError(t, err)
+		client := &http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{
+			RootCAs:      roots,
+			ServerName:   serverName,
+			Certificates: []tls.Certificate{cert},
+		}}}
+		resp, err := client.Get(server.URL + "/providers")
+		require.NoError(t, err)
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		assert.Equal(t, http.StatusOK, resp.StatusCode)
+		assert.Contains(t, string(body), "infura")
+	})
+}
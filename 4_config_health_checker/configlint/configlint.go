@@ -0,0 +1,323 @@
+package configlint
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"net/url"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/go-playground/validator/v10"
+
+	"github.com/friofry/config-health-checker/chainconfig"
+	"github.com/friofry/config-health-checker/chainprotocol"
+	"github.com/friofry/config-health-checker/configreader"
+	"github.com/friofry/config-health-checker/rpcprovider"
+	"github.com/friofry/config-health-checker/rpctestsconfig"
+	"github.com/friofry/config-health-checker/strictjson"
+)
+
+// Severity defines how serious a lint issue is
+type Severity string
+
+const (
+	SeverityError   Severity = "error"   // The configuration is broken or a chain will never be served
+	SeverityWarning Severity = "warning" // The configuration works but is probably not what was meant
+)
+
+// Issue is a single problem found in the configuration files
+type Issue struct {
+	Severity Severity // How serious the issue is
+	File     string   // File the issue was found in
+	Path     string   // Location inside the file, e.g. "chains[0].providers[1]"
+	Message  string   // Description of the issue
+}
+
+// String formats the issue as "severity: file: path: message"
+func (i Issue) String() string {
+	location := i.File
+	if i.Path != "" {
+		location += ": " + i.Path
+	}
+	return fmt.Sprintf("%s: %s: %s", i.Severity, location, i.Message)
+}
+
+// Report contains all issues found by Lint
+type Report struct {
+	Issues []Issue
+}
+
+// HasErrors reports whether any issue is an error
+func (r Report) HasErrors() bool {
+	for _, issue := range r.Issues {
+		if issue.Severity == SeverityError {
+			return true
+		}
+	}
+	return false
+}
+
+// hostnamePattern matches DNS names and IPv4 addresses
+var hostnamePattern = regexp.MustCompile(`^[A-Za-z0-9]([A-Za-z0-9-]*[A-Za-z0-9])?(\.[A-Za-z0-9]([A-Za-z0-9-]*[A-Za-z0-9])?)*$`)
+
+// linter collects issues while the files are checked
+type linter struct {
+	report        Report
+	unknownFields Severity // Severity of unknown fields, warnings in strictjson.ModeWarn
+}
+
+// add records an issue
+func (l *linter) add(severity Severity, file, path, format string, args ...interface{}) {
+	l.report.Issues = append(l.report.Issues, Issue{
+		Severity: severity,
+		File:     file,
+		Path:     path,
+		Message:  fmt.Sprintf(format, args...),
+	})
+}
+
+// Lint statically checks the provider and test files referenced by the checker configuration.
+// No requests are sent and secret references are not resolved, so it can run in CI.
+func Lint(cfg configreader.CheckerConfig) Report {
+	l := &linter{unknownFields: SeverityError}
+	if cfg.UnknownFieldsMode() == strictjson.ModeWarn {
+		l.unknownFields = SeverityWarning
+	}
+
+	chains, chainsOK := l.readChains(cfg.DefaultProvidersPath)
+	references, referencesOK := l.readReferences(cfg.ReferenceProvidersPath)
+	methods := l.lintTests(cfg.TestsConfigPath)
+
+	if chainsOK {
+		l.lintChains(cfg.DefaultProvidersPath, chains, methods)
+	}
+	if referencesOK {
+		l.lintReferences(cfg.ReferenceProvidersPath, references)
+	}
+	if chainsOK && referencesOK {
+		l.lintCoverage(cfg, chains, references)
+	}
+	return l.report
+}
+
+// readChains decodes the default providers file
+func (l *linter) readChains(path string) (chainconfig.ChainsConfig, bool) {
+	var config chainconfig.ChainsConfig
+	if !l.decode(path, &config) {
+		return config, false
+	}
+	if len(config.Chains) == 0 {
+		l.add(SeverityError, path, "", "no chains configured")
+		return config, false
+	}
+	return config, true
+}
+
+// readReferences decodes the reference providers file
+func (l *linter) readReferences(path string) (chainconfig.ReferenceChainsConfig, bool) {
+	var config chainconfig.ReferenceChainsConfig
+	if !l.decode(path, &config) {
+		return config, false
+	}
+	if len(config.Chains) == 0 {
+		l.add(SeverityError, path, "", "no reference chains configured")
+		return config, false
+	}
+	return config, true
+}
+
+// decode reads and strictly decodes a JSON file, recording every unknown field as an issue
+func (l *linter) decode(path string, v interface{}) bool {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		l.add(SeverityError, path, "", "%v", err)
+		return false
+	}
+
+	err = strictjson.DecodeMode(data, v, strictjson.ModeStrict)
+	var unknownErr *strictjson.UnknownFieldsError
+	if errors.As(err, &unknownErr) {
+		for _, field := range unknownErr.Fields {
+			l.add(l.unknownFields, path, "", "%s", field)
+		}
+		// Keep linting the known fields
+		err = json.Unmarshal(data, v)
+	}
+	if err != nil {
+		l.add(SeverityError, path, "", "%v", err)
+		return false
+	}
+	return true
+}
+
+// lintChains checks the default chains and their providers
+func (l *linter) lintChains(file string, config chainconfig.ChainsConfig, methods map[chainprotocol.Protocol]int) {
+	chainIDs := make(map[int]string)
+	for i, chain := range config.Chains {
+		path := fmt.Sprintf("chains[%d] (%s/%s)", i, chain.Name, chain.Network)
+		chain.Normalize()
+
+		if first, exists := chainIDs[chain.ChainId]; exists {
+			l.add(SeverityError, file, path, "duplicate chainId %d, already used by %s", chain.ChainId, first)
+		} else {
+			chainIDs[chain.ChainId] = path
+		}
+
+		if err := chain.Validate(); err != nil {
+			l.validationIssues(file, path, err)
+		}
+		if protocol := chain.Protocol.OrDefault(); methods != nil && methods[protocol] == 0 {
+			l.add(SeverityWarning, file, path, "no test methods for protocol %s, providers are served unchecked", protocol)
+		}
+
+		l.lintProviders(file, path, chain.Providers)
+	}
+}
+
+// lintProviders checks the providers of a chain
+func (l *linter) lintProviders(file, chainPath string, providers []rpcprovider.RpcProvider) {
+	names := make(map[string]bool)
+	enabled := 0
+	for j, provider := range providers {
+		path := fmt.Sprintf("%s.providers[%d] (%s)", chainPath, j, provider.Name)
+		if names[provider.Name] {
+			l.add(SeverityError, file, path, "duplicate provider name %q", provider.Name)
+		}
+		names[provider.Name] = true
+
+		if err := checkURL(provider.URL); err != nil {
+			l.add(SeverityError, file, path, "malformed URL %s: %v", rpcprovider.SanitizeURL(provider.URL), err)
+		}
+		if provider.IsEnabled() {
+			enabled++
+		}
+	}
+	if len(providers) > 0 && enabled == 0 {
+		l.add(SeverityWarning, file, chainPath, "all providers are disabled")
+	}
+}
+
+// lintReferences checks the reference chains
+func (l *linter) lintReferences(file string, config chainconfig.ReferenceChainsConfig) {
+	chainIDs := make(map[int]string)
+	for i, chain := range config.Chains {
+		path := fmt.Sprintf("chains[%d] (%s/%s)", i, chain.Name, chain.Network)
+		chain.Normalize()
+
+		if first, exists := chainIDs[chain.ChainId]; exists {
+			l.add(SeverityError, file, path, "duplicate chainId %d, already used by %s", chain.ChainId, first)
+		} else {
+			chainIDs[chain.ChainId] = path
+		}
+
+		if err := chain.Validate(); err != nil {
+			l.validationIssues(file, path, err)
+		}
+		if err := checkURL(chain.Provider.URL); chain.Provider.URL != "" && err != nil {
+			l.add(SeverityError, file, path+".provider", "malformed URL %s: %v", rpcprovider.SanitizeURL(chain.Provider.URL), err)
+		}
+	}
+}
+
+// lintCoverage checks that every default chain has a reference and vice versa
+func (l *linter) lintCoverage(
+	cfg configreader.CheckerConfig,
+	chains chainconfig.ChainsConfig,
+	references chainconfig.ReferenceChainsConfig,
+) {
+	referenceIDs := make(map[int]bool)
+	for _, reference := range references.Chains {
+		referenceIDs[reference.ChainId] = true
+	}
+	chainIDs := make(map[int]bool)
+	for i, chain := range chains.Chains {
+		chainIDs[chain.ChainId] = true
+		if !referenceIDs[chain.ChainId] {
+			l.add(SeverityError, cfg.DefaultProvidersPath, fmt.Sprintf("chains[%d] (%s/%s)", i, chain.Name, chain.Network),
+				"no reference chain with chainId %d, the chain is never checked or served", chain.ChainId)
+		}
+	}
+	for i, reference := range references.Chains {
+		if !chainIDs[reference.ChainId] {
+			l.add(SeverityWarning, cfg.ReferenceProvidersPath, fmt.Sprintf("chains[%d] (%s/%s)", i, reference.Name, reference.Network),
+				"no default chain with chainId %d, the reference is unused", reference.ChainId)
+		}
+	}
+}
+
+// lintTests checks the test methods and returns the number of methods per protocol.
+// It returns nil if the file cannot be read.
+func (l *linter) lintTests(file string) map[chainprotocol.Protocol]int {
+	var tests []rpctestsconfig.EVMMethodTestJSON
+	if !l.decode(file, &tests) {
+		return nil
+	}
+	if len(tests) == 0 {
+		l.add(SeverityError, file, "", "no test methods configured")
+	}
+
+	methods := make(map[chainprotocol.Protocol]int)
+	for i, test := range tests {
+		path := fmt.Sprintf("[%d] (%s)", i, test.Method)
+		if test.Method == "" {
+			l.add(SeverityError, file, path, "method name cannot be empty")
+		}
+		if maxDiff, ok := new(big.Int).SetString(test.MaxDifference, 10); !ok {
+			l.add(SeverityError, file, path, "invalid maxDifference %q, expected a decimal integer", test.MaxDifference)
+		} else if maxDiff.Sign() < 0 {
+			l.add(SeverityError, file, path, "maxDifference %s is negative, no result can match", maxDiff)
+		}
+		protocol, err := chainprotocol.Parse(test.Protocol)
+		if err != nil {
+			l.add(SeverityError, file, path, "%v", err)
+			continue
+		}
+		methods[protocol]++
+	}
+	return methods
+}
+
+// validationIssues records every field error of a validation error
+func (l *linter) validationIssues(file, path string, err error) {
+	var fieldErrors validator.ValidationErrors
+	if !errors.As(err, &fieldErrors) {
+		l.add(SeverityError, file, path, "%v", err)
+		return
+	}
+
+	messages := make([]string, 0, len(fieldErrors))
+	for _, fieldErr := range fieldErrors {
+		messages = append(messages, fmt.Sprintf("%s fails %q", fieldErr.Namespace(), fieldErr.Tag()))
+	}
+	sort.Strings(messages)
+	for _, message := range messages {
+		l.add(SeverityError, file, path, "%s", message)
+	}
+}
+
+// checkURL checks that a provider URL has a supported scheme and a well-formed host
+func checkURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return err
+	}
+	switch strings.ToLower(u.Scheme) {
+	case "http", "https", "ws", "wss":
+	default:
+		return fmt.Errorf("unsupported scheme %q", u.Scheme)
+	}
+
+	host := u.Hostname()
+	if host == "" {
+		return errors.New("missing host")
+	}
+	if net.ParseIP(host) == nil && !hostnamePattern.MatchString(host) {
+		return fmt.Errorf("invalid host %q", host)
+	}
+	return nil
+}
@@ -0,0 +1,154 @@
+package configlint
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/friofry/config-health-checker/configreader"
+	"github.com/friofry/config-health-checker/strictjson"
+)
+
+const lintDefaultProviders = `{
+  "chains": [
+    {
+      "name": "Ethereum",
+      "network": "mainnet",
+      "chainId": 1,
+      "providers": [
+        {"name": "infura", "url": "https://mainnet.infura.io/v3", "authType": "token-auth", "authToken": "env:INFURA_KEY"},
+        {"name": "infura", "url": "https://another-provider.example,io/v2", "authType": "no-auth"}
+      ]
+    },
+    {
+      "name": "ethereum",
+      "network": "holesky",
+      "chainId": 1,
+      "providers": [
+        {"name": "public", "url": "https://holesky.example.io", "authType": "no-auth", "authtoken": "x"}
+      ]
+    },
+    {
+      "name": "optimism",
+      "network": "mainnet",
+      "chainId": 10,
+      "providers": [
+        {"name": "public", "url": "https://optimism.example.io", "authType": "no-auth"}
+      ]
+    }
+  ]
+}`
+
+const lintReferenceProviders = `{
+  "chains": [
+    {
+      "name": "ethereum",
+      "network": "mainnet",
+      "chainId": 1,
+      "provider": {"name": "reference", "url": "https://reference.example.io", "authType": "no-auth"}
+    },
+    {
+      "name": "polygon",
+      "network": "mainnet",
+      "chainId": 137,
+      "provider": {"name": "reference", "url": "https://polygon.example.io", "authType": "no-auth"}
+    }
+  ]
+}`
+
+const lintTests = `[
+  {"method": "eth_blockNumber", "params": [], "maxDifference": "0"},
+  {"method": "eth_getBalance", "params": [], "maxDifference": "1e18"}
+]`
+
+func writeLintFiles(t *testing.T, defaults, references, tests string) configreader.CheckerConfig {
+	dir := t.TempDir()
+	cfg := configreader.CheckerConfig{
+		DefaultProvidersPath:   filepath.Join(dir, "default_providers.json"),
+		ReferenceProvidersPath: filepath.Join(dir, "reference_providers.json"),
+		TestsConfigPath:        filepath.Join(dir, "test_methods.json"),
+	}
+	require.NoError(t, os.WriteFile(cfg.DefaultProvidersPath, []byte(defaults), 0644))
+	require.NoError(t, os.WriteFile(cfg.ReferenceProvidersPath, []byte(references), 0644))
+	require.NoError(t, os.WriteFile(cfg.TestsConfigPath, []byte(tests), 0644))
+	return cfg
+}
+
+func TestLint(t *testing.T) {
+	cfg := writeLintFiles(t, lintDefaultProviders, lintReferenceProviders, lintTests)
+
+	report := Lint(cfg)
+	require.True(t, report.HasErrors())
+
+	var lines []string
+	for _, issue := range report.Issues {
+		lines = append(lines, issue.String())
+	}
+	output := strings.Join(lines, "\n")
+
+	expected := []string{
+		`unknown field "authtoken" in chains[1].providers[0] ("public"), did you mean "authToken"?`,
+		`test_methods.json: [1] (eth_getBalance): invalid maxDifference "1e18"`,
+		`chains[0] (Ethereum/mainnet).providers[1] (infura): duplicate provider name "infura"`,
+		`chains[0] (Ethereum/mainnet).providers[1] (infura): malformed URL https://another-provider.example,io/v2`,
+		`chains[1] (ethereum/holesky): duplicate chainId 1, already used by chains[0] (Ethereum/mainnet)`,
+		`error: ` + cfg.DefaultProvidersPath + `: chains[2] (optimism/mainnet): no reference chain with chainId 10`,
+		`warning: ` + cfg.ReferenceProvidersPath + `: chains[1] (polygon/mainnet): no default chain with chainId 137`,
+	}
+	for _, message := range expected {
+		assert.Contains(t, output, message)
+	}
+	// Secret references are not resolved, so a missing INFURA_KEY is not an issue
+	assert.NotContains(t, output, "INFURA_KEY")
+}
+
+func TestLintUnknownFieldsWarn(t *testing.T) {
+	cfg := writeLintFiles(t, lintDefaultProviders, lintReferenceProviders, lintTests)
+	cfg.UnknownFields = string(strictjson.ModeWarn)
+
+	for _, issue := range Lint(cfg).Issues {
+		if strings.Contains(issue.Message, "unknown field") {
+			assert.Equal(t, SeverityWarning, issue.Severity)
+			return
+		}
+	}
+	t.Fatal("unknown field not reported")
+}
+
+func TestLintClean(t *testing.T) {
+	defaults := `{"chains": [{"name": "ethereum", "network": "mainnet", "chainId": 1,
+		"providers": [{"name": "public", "url": "wss://rpc.example.io/ws", "authType": "no-auth"}]}]}`
+	references := `{"chains": [{"name": "ethereum", "network": "mainnet", "chainId": 1,
+		"provider": {"name": "reference", "url": "https://127.0.0.1:8545", "authType": "no-auth"}}]}`
+	cfg := writeLintFiles(t, defaults, references, lintTests[:strings.Index(lintTests, ",\n")]+"]")
+
+	report := Lint(cfg)
+	assert.Empty(t, report.Issues)
+	assert.False(t, report.HasErrors())
+}
+
+func TestCheckURL(t *testing.T) {
+	valid := []string{
+		"https://mainnet.infura.io/v3",
+		"https://rpc.example.io/v2/{token}?key={token}",
+		"ws://localhost:8546",
+		"http://[::1]:8545",
+	}
+	for _, raw := range valid {
+		assert.NoError(t, checkURL(raw), raw)
+	}
+
+	invalid := []string{
+		"https://another-provider.example,io/v2",
+		"ftp://rpc.example.io",
+		"https:///v3",
+		"rpc.example.io",
+	}
+	for _, raw := range invalid {
+		assert.Error(t, checkURL(raw), raw)
+	}
+}
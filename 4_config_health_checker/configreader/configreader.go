@@ -6,6 +6,9 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
+
+	"github.com/friofry/config-health-checker/strictjson"
 )
 
 const (
@@ -15,16 +18,114 @@ const (
 
 // CheckerConfig represents the configuration for the health checker
 type CheckerConfig struct {
-	IntervalSeconds        int    `json:"interval_seconds"`         // Interval between health checks in seconds
-	DefaultProvidersPath   string `json:"default_providers_path"`   // Path to default providers JSON file
-	ReferenceProvidersPath string `json:"reference_providers_path"` // Path to reference providers JSON file
-	OutputProvidersPath    string `json:"output_providers_path"`    // Path to output providers JSON file
-	TestsConfigPath        string `json:"tests_config_path"`        // Path to tests configuration JSON file
-	LogsPath               string `json:"logs_path"`                // Path to store log files
+	IntervalSeconds        int                  `json:"interval_seconds"`         // Interval between health checks in seconds
+	DefaultProvidersPath   string               `json:"default_providers_path"`   // Path to default providers JSON file
+	ReferenceProvidersPath string               `json:"reference_providers_path"` // Path to reference providers JSON file
+	OutputProvidersPath    string               `json:"output_providers_path"`    // Path to output providers JSON file
+	TestsConfigPath        string               `json:"tests_config_path"`        // Path to tests configuration JSON file
+	LogsPath               string               `json:"logs_path"`                // Path to store log files
+	UnknownFields          string               `json:"unknown_fields"`           // Handling of unknown fields in JSON files, including this one: "strict" (default) or "warn"
+	Retry                  RetryConfig          `json:"retry"`                    // Retry policy for provider requests
+	CircuitBreaker         CircuitBreakerConfig `json:"circuit_breaker"`          // Circuit breaker for unhealthy providers
+	HTTPServer             HTTPServerConfig     `json:"http_server"`              // HTTP server serving the valid providers
+	WebSocket              WebSocketConfig      `json:"websocket"`                // Checks specific to ws:// and wss:// providers
+	History                HistoryConfig        `json:"history"`                  // Store of validation results over time
+	Alerting               AlertingConfig       `json:"alerting"`                 // Alerts on health state changes
+	Overrides              OverridesConfig      `json:"overrides"`                // Manual provider states set through the admin API
+	Sources                SourcesConfig        `json:"sources"`                  // Remote provider files
+}
+
+// SourcesConfig represents the settings of remote provider files. The provider paths accept https:// URLs,
+// s3://bucket/key and git+<repository>//<path>?ref=<ref> locations besides local paths.
+type SourcesConfig struct {
+	CacheDir       string            `json:"cache_dir"`       // Directory of the last good copies of remote files, "sources_cache" if empty
+	TimeoutSeconds int               `json:"timeout_seconds"` // Timeout of a single fetch, zero means 30
+	HTTPHeaders    map[string]string `json:"http_headers"`    // Headers sent to https:// sources, values accept env:/file: references
+	S3             S3SourceConfig    `json:"s3"`              // Settings of s3:// sources
+}
+
+// S3SourceConfig represents the settings of S3-compatible object storage
+type S3SourceConfig struct {
+	Endpoint        string `json:"endpoint"`          // Endpoint URL, e.g. of MinIO; the AWS endpoint of the region if empty
+	Region          string `json:"region"`            // Signing region, "us-east-1" if empty
+	AccessKeyID     string `json:"access_key_id"`     // Accepts env:/file: references, requests are anonymous if empty
+	SecretAccessKey string `json:"secret_access_key"` // Accepts env:/file: references
+}
+
+// OverridesConfig represents the settings of manual provider overrides
+type OverridesConfig struct {
+	Path          string `json:"path"`            // JSON file the active overrides are kept in; the admin API is off when empty
+	AuditPath     string `json:"audit_path"`      // JSON lines file every change is appended to, changes are only logged when empty
+	MaxTTLSeconds int    `json:"max_ttl_seconds"` // Longest allowed override, zero means 7 days
+}
+
+// AlertingConfig represents the alerting thresholds and targets
+type AlertingConfig struct {
+	MinValidProviders int                 `json:"min_valid_providers"` // Alert when a chain has fewer valid providers, zero means 1
+	CooldownSeconds   int                 `json:"cooldown_seconds"`    // Minimum seconds between two alerts for the same condition, zero means 600
+	Source            string              `json:"source"`              // Source reported to PagerDuty, the host name if empty
+	Targets           []AlertTargetConfig `json:"targets"`             // Alerting is off when empty
+}
+
+// AlertTargetConfig represents a single alerting target
+type AlertTargetConfig struct {
+	Type       string            `json:"type"`        // "webhook", "slack" or "pagerduty"
+	URL        string            `json:"url"`         // Webhook URL, accepts env:/file: references; optional for pagerduty
+	Headers    map[string]string `json:"headers"`     // Additional webhook headers, values accept env:/file: references
+	Template   string            `json:"template"`    // Go template of the webhook payload, the JSON event if empty
+	RoutingKey string            `json:"routing_key"` // PagerDuty integration key, accepts env:/file: references
+	Events     []string          `json:"events"`      // Event types to send, all if empty
+}
+
+// HistoryConfig represents the settings of the validation history store
+type HistoryConfig struct {
+	Path           string `json:"path"`            // Database file the results are stored in; history is off when empty
+	RetentionHours int    `json:"retention_hours"` // Hours results are kept, zero means 7 days
 }
 
-// ReadConfig reads and validates the configuration from the specified path
-func ReadConfig(path string) (*CheckerConfig, error) {
+// WebSocketConfig represents the settings of websocket provider checks
+type WebSocketConfig struct {
+	NewHeadsTimeoutMs int `json:"new_heads_timeout_ms"` // Deadline for the first newHeads notification in milliseconds, zero means the check interval
+}
+
+// HTTPServerConfig represents the settings of the HTTP server
+type HTTPServerConfig struct {
+	Port                 string `json:"port"`                  // Port to listen on, "8080" if empty; the PORT environment variable is honored too
+	ProvidersCredentials string `json:"providers_credentials"` // How /providers serves credentials to unauthenticated clients: "full" (default), "mask" or "omit"
+	AuthType             string `json:"auth_type"`             // Auth required for every route but /health: "" (none), "bearer" or "basic"
+	AuthToken            string `json:"auth_token"`            // Token for bearer auth, accepts env:/file: references
+	AuthTokenName        string `json:"auth_token_name"`       // Actor recorded for admin changes made with the bearer token, "bearer" if empty
+	AuthLogin            string `json:"auth_login"`            // Login for basic auth
+	AuthPassword         string `json:"auth_password"`         // Password for basic auth, accepts env:/file: references
+	TLSCertPath          string `json:"tls_cert_path"`         // TLS certificate, reloaded on change; TLS is off when empty
+	TLSKeyPath           string `json:"tls_key_path"`          // TLS private key, reloaded on change
+	ClientCAPath         string `json:"client_ca_path"`        // CA bundle to require and verify client certificates (mTLS)
+}
+
+// CircuitBreakerConfig represents the per-provider circuit breaker settings.
+// Zero values fall back to the requests runner defaults.
+type CircuitBreakerConfig struct {
+	Disabled         bool   `json:"disabled"`          // Disables circuit breaking
+	FailureThreshold int    `json:"failure_threshold"` // Consecutive transport failures that open the circuit
+	OpenSeconds      int    `json:"open_seconds"`      // Seconds an open circuit fails fast before a probe is sent
+	ProbeMethod      string `json:"probe_method"`      // Cheap method used to probe an open circuit, per protocol if empty
+	StatePath        string `json:"state_path"`        // Optional file to persist circuit states across restarts
+}
+
+// RetryConfig represents the retry policy for provider requests.
+// Zero values fall back to the requests runner defaults.
+type RetryConfig struct {
+	MaxAttempts      int      `json:"max_attempts"`       // Total attempts per request, including the first one
+	BaseBackoffMs    int      `json:"base_backoff_ms"`    // Wait before the first retry in milliseconds, doubled for every next retry
+	MaxBackoffMs     int      `json:"max_backoff_ms"`     // Upper bound for a single backoff in milliseconds
+	RetryOn          []string `json:"retry_on"`           // Error classes to retry, e.g. "timeout", "http_429", "http_5xx"
+	MethodDeadlineMs int      `json:"method_deadline_ms"` // Overall deadline for all attempts of one method in milliseconds
+}
+
+// ReadConfig reads and validates the configuration from the specified path.
+// The values are applied in order over the file before defaults are set, so later values take precedence,
+// e.g. ReadConfig(path, envValues, flagValues) gives flags precedence over CHC_* variables and the file.
+func ReadConfig(path string, values ...Values) (*CheckerConfig, error) {
 	if path == "" {
 		return nil, errors.New("config path cannot be empty")
 	}
@@ -34,10 +135,20 @@ func ReadConfig(path string) (*CheckerConfig, error) {
 		return nil, fmt.Errorf("failed to read config file: %w", err)
 	}
 
+	// unknown_fields applies to the config file itself, so it is looked up before the file is decoded
+	mode, err := unknownFieldsMode(configData, values)
+	if err != nil {
+		return nil, err
+	}
 	var config CheckerConfig
-	if err := json.Unmarshal(configData, &config); err != nil {
+	if err := strictjson.DecodeMode(configData, &config, mode); err != nil {
 		return nil, fmt.Errorf("failed to parse config JSON: %w", err)
 	}
+	for _, v := range values {
+		if err := config.Apply(v); err != nil {
+			return nil, err
+		}
+	}
 
 	// Set default values and validate
 	if config.IntervalSeconds <= 0 {
@@ -57,12 +168,41 @@ func ReadConfig(path string) (*CheckerConfig, error) {
 	return &config, nil
 }
 
+// unknownFieldsMode returns the unknown_fields mode of the config file with the values applied
+func unknownFieldsMode(configData []byte, values []Values) (strictjson.Mode, error) {
+	var config CheckerConfig
+	// Syntax and type errors are reported when the whole file is decoded
+	_ = json.Unmarshal(configData, &struct {
+		UnknownFields *string `json:"unknown_fields"`
+	}{&config.UnknownFields})
+	for _, v := range values {
+		if err := config.Apply(v); err != nil {
+			return "", err
+		}
+	}
+	mode, err := strictjson.ParseMode(config.UnknownFields)
+	if err != nil {
+		return "", fmt.Errorf("invalid unknown_fields: %w", err)
+	}
+	return mode, nil
+}
+
+// UnknownFieldsMode returns how unknown fields in JSON files are handled
+func (c CheckerConfig) UnknownFieldsMode() strictjson.Mode {
+	// Invalid values are rejected by ReadConfig
+	mode, _ := strictjson.ParseMode(c.UnknownFields)
+	return mode
+}
+
 // resolvePath returns the default path if the provided path is empty,
-// otherwise returns the absolute path of the provided path
+// otherwise returns the cleaned path. Locations with a URL scheme, e.g. of remote sources, are kept as they are.
 func resolvePath(path, defaultPath string) string {
 	if path == "" {
 		return filepath.Join(defaultConfigDir, defaultPath)
 	}
+	if strings.Contains(path, "://") {
+		return path
+	}
 	return filepath.Clean(path)
 }
 
@@ -72,6 +212,43 @@ func validateConfig(config *CheckerConfig) error {
 		return errors.New("interval_seconds must be positive")
 	}
 
+	if config.Retry.MaxAttempts < 0 || config.Retry.BaseBackoffMs < 0 ||
+		config.Retry.MaxBackoffMs < 0 || config.Retry.MethodDeadlineMs < 0 {
+		return errors.New("retry settings must not be negative")
+	}
+
+	if config.CircuitBreaker.FailureThreshold < 0 || config.CircuitBreaker.OpenSeconds < 0 {
+		return errors.New("circuit breaker settings must not be negative")
+	}
+
+	if config.WebSocket.NewHeadsTimeoutMs < 0 {
+		return errors.New("websocket new_heads_timeout_ms must not be negative")
+	}
+
+	if config.History.RetentionHours < 0 {
+		return errors.New("history retention_hours must not be negative")
+	}
+
+	if config.Alerting.MinValidProviders < 0 || config.Alerting.CooldownSeconds < 0 {
+		return errors.New("alerting settings must not be negative")
+	}
+
+	if config.Overrides.MaxTTLSeconds < 0 {
+		return errors.New("overrides max_ttl_seconds must not be negative")
+	}
+
+	if config.Sources.TimeoutSeconds < 0 {
+		return errors.New("sources timeout_seconds must not be negative")
+	}
+
+	if (config.HTTPServer.TLSCertPath == "") != (config.HTTPServer.TLSKeyPath == "") {
+		return errors.New("http_server tls_cert_path and tls_key_path must be set together")
+	}
+
+	if config.HTTPServer.ClientCAPath != "" && config.HTTPServer.TLSCertPath == "" {
+		return errors.New("http_server client_ca_path requires TLS")
+	}
+
 	if config.DefaultProvidersPath == "" || config.ReferenceProvidersPath == "" ||
 		config.OutputProvidersPath == "" || config.TestsConfigPath == "" || config.LogsPath == "" {
 		return errors.New("all paths must be specified")
@@ -54,6 +54,11 @@ func TestReadConfig(t *testing.T) {
 			configJSON:  `{invalid}`,
 			expectError: true,
 		},
+		{
+			name:        "unknown field",
+			configJSON:  `{"intervalSeconds": 30}`,
+			expectError: true,
+		},
 		{
 			name:        "empty path",
 			configJSON:  "",
@@ -134,6 +139,45 @@ func TestValidateConfig(t *testing.T) {
 			},
 			expectError: true,
 		},
+		{
+			name: "negative history retention",
+			config: &CheckerConfig{
+				IntervalSeconds:        60,
+				DefaultProvidersPath:   "default.json",
+				ReferenceProvidersPath: "reference.json",
+				OutputProvidersPath:    "output.json",
+				TestsConfigPath:        "tests.json",
+				LogsPath:               "logs",
+				History:                HistoryConfig{Path: "history.db", RetentionHours: -1},
+			},
+			expectError: true,
+		},
+		{
+			name: "negative override max ttl",
+			config: &CheckerConfig{
+				IntervalSeconds:        60,
+				DefaultProvidersPath:   "default.json",
+				ReferenceProvidersPath: "reference.json",
+				OutputProvidersPath:    "output.json",
+				TestsConfigPath:        "tests.json",
+				LogsPath:               "logs",
+				Overrides:              OverridesConfig{Path: "overrides.json", MaxTTLSeconds: -1},
+			},
+			expectError: true,
+		},
+		{
+			name: "negative sources timeout",
+			config: &CheckerConfig{
+				IntervalSeconds:        60,
+				DefaultProvidersPath:   "https://example.com/default.json",
+				ReferenceProvidersPath: "reference.json",
+				OutputProvidersPath:    "output.json",
+				TestsConfigPath:        "tests.json",
+				LogsPath:               "logs",
+				Sources:                SourcesConfig{TimeoutSeconds: -1},
+			},
+			expectError: true,
+		},
 		{
 			name: "missing paths",
 			config: &CheckerConfig{
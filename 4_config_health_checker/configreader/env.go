@@ -0,0 +1,183 @@
+package configreader
+
+import (
+	"flag"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/friofry/config-health-checker/strictjson"
+)
+
+// EnvPrefix prefixes the environment variables that set configuration fields
+const EnvPrefix = "CHC_"
+
+// legacyEnv maps environment variables older than the CHC_* ones to the fields they set.
+// They apply only if the CHC_* variable of the field is not set.
+var legacyEnv = map[string]string{
+	"PORT": "http_server.port",
+}
+
+// Values holds configuration values set outside the config file, keyed by the JSON path of the field,
+// e.g. "http_server.auth_type"
+type Values map[string]string
+
+// Field is a configuration field that can be set by an environment variable or a flag.
+// Numbers, booleans and strings take plain values, string lists are comma-separated
+// and other fields, e.g. maps and alerting targets, take JSON.
+type Field struct {
+	Path  string // JSON path, e.g. "http_server.auth_type"
+	Env   string // Environment variable, e.g. "CHC_HTTP_SERVER_AUTH_TYPE"
+	Flag  string // Flag name, e.g. "http-server.auth-type"
+	index []int  // Index of the struct field in CheckerConfig
+}
+
+// Fields returns every settable field of CheckerConfig
+func Fields() []Field {
+	return structFields(reflect.TypeOf(CheckerConfig{}), nil, nil)
+}
+
+// structFields lists the fields of a struct, descending into nested structs
+func structFields(typ reflect.Type, path []string, index []int) []Field {
+	var fields []Field
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+		fieldPath := append(append([]string{}, path...), name)
+		fieldIndex := append(append([]int{}, index...), i)
+		if field.Type.Kind() == reflect.Struct {
+			fields = append(fields, structFields(field.Type, fieldPath, fieldIndex)...)
+			continue
+		}
+		fields = append(fields, Field{
+			Path:  strings.Join(fieldPath, "."),
+			Env:   EnvPrefix + strings.ToUpper(strings.Join(fieldPath, "_")),
+			Flag:  FlagName(strings.Join(fieldPath, ".")),
+			index: fieldIndex,
+		})
+	}
+	return fields
+}
+
+// FlagName returns the name of the flag setting the field at the JSON path
+func FlagName(path string) string {
+	return strings.ReplaceAll(path, "_", "-")
+}
+
+// set parses value into the field of config
+func (f Field) set(config *CheckerConfig, value string) error {
+	target := reflect.ValueOf(config).Elem().FieldByIndex(f.index)
+	switch target.Kind() {
+	case reflect.String:
+		target.SetString(value)
+	case reflect.Int:
+		number, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil {
+			return fmt.Errorf("expected an integer, got %q", value)
+		}
+		target.SetInt(int64(number))
+	case reflect.Bool:
+		enabled, err := strconv.ParseBool(strings.TrimSpace(value))
+		if err != nil {
+			return fmt.Errorf("expected a boolean, got %q", value)
+		}
+		target.SetBool(enabled)
+	case reflect.Slice:
+		if target.Type().Elem().Kind() == reflect.String {
+			var items []string
+			for _, item := range strings.Split(value, ",") {
+				if item = strings.TrimSpace(item); item != "" {
+					items = append(items, item)
+				}
+			}
+			target.Set(reflect.ValueOf(items))
+			return nil
+		}
+		return f.setJSON(target, value)
+	default:
+		return f.setJSON(target, value)
+	}
+	return nil
+}
+
+// setJSON decodes a JSON value into target
+func (f Field) setJSON(target reflect.Value, value string) error {
+	decoded := reflect.New(target.Type())
+	if err := strictjson.Decode([]byte(value), decoded.Interface()); err != nil {
+		return fmt.Errorf("expected JSON: %w", err)
+	}
+	target.Set(decoded.Elem())
+	return nil
+}
+
+// fieldsByPath indexes Fields by their JSON path
+func fieldsByPath() map[string]Field {
+	fields := Fields()
+	byPath := make(map[string]Field, len(fields))
+	for _, field := range fields {
+		byPath[field.Path] = field
+	}
+	return byPath
+}
+
+// EnvValues returns the values set by CHC_* environment variables, looked up with lookup, e.g. os.LookupEnv.
+// Legacy variables such as PORT are honored if the CHC_* variable of their field is not set.
+func EnvValues(lookup func(name string) (string, bool)) (Values, error) {
+	legacy := make(map[string]string, len(legacyEnv))
+	for name, path := range legacyEnv {
+		legacy[path] = name
+	}
+
+	values := Values{}
+	for _, field := range Fields() {
+		name := field.Env
+		value, ok := lookup(name)
+		if !ok && legacy[field.Path] != "" {
+			name = legacy[field.Path]
+			value, ok = lookup(name)
+		}
+		if !ok {
+			continue
+		}
+		if err := field.set(&CheckerConfig{}, value); err != nil {
+			return nil, fmt.Errorf("invalid %s: %w", name, err)
+		}
+		values[field.Path] = value
+	}
+	return values, nil
+}
+
+// RegisterFlags defines a flag for every field on flags. The returned values are filled in when flags are parsed.
+func RegisterFlags(flags *flag.FlagSet) Values {
+	values := Values{}
+	for _, field := range Fields() {
+		field := field
+		flags.Func(field.Flag, fmt.Sprintf("sets %s of the checker config (env %s)", field.Path, field.Env), func(value string) error {
+			if err := field.set(&CheckerConfig{}, value); err != nil {
+				return err
+			}
+			values[field.Path] = value
+			return nil
+		})
+	}
+	return values
+}
+
+// Apply sets the fields of the config to the values
+func (c *CheckerConfig) Apply(values Values) error {
+	byPath := fieldsByPath()
+	for path, value := range values {
+		field, ok := byPath[path]
+		if !ok {
+			return fmt.Errorf("unknown config field %s", path)
+		}
+		if err := field.set(c, value); err != nil {
+			return fmt.Errorf("invalid %s: %w", path, err)
+		}
+	}
+	return nil
+}
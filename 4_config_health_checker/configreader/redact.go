@@ -0,0 +1,74 @@
+package configreader
+
+import (
+	"net/url"
+	"strings"
+
+	"github.com/friofry/config-health-checker/rpcprovider"
+)
+
+// Redacted returns a copy of the config with secrets replaced by rpcprovider.RedactedValue, so that it can be printed.
+// Secret references (env:, file:) are kept, as they do not contain the secrets.
+func (c CheckerConfig) Redacted() CheckerConfig {
+	redacted := c
+	redacted.DefaultProvidersPath = redactLocation(c.DefaultProvidersPath)
+	redacted.ReferenceProvidersPath = redactLocation(c.ReferenceProvidersPath)
+	redacted.HTTPServer.AuthToken = redactSecret(c.HTTPServer.AuthToken)
+	redacted.HTTPServer.AuthPassword = redactSecret(c.HTTPServer.AuthPassword)
+	redacted.Sources.HTTPHeaders = redactValues(c.Sources.HTTPHeaders)
+	redacted.Sources.S3.AccessKeyID = redactSecret(c.Sources.S3.AccessKeyID)
+	redacted.Sources.S3.SecretAccessKey = redactSecret(c.Sources.S3.SecretAccessKey)
+	if len(c.Alerting.Targets) > 0 {
+		redacted.Alerting.Targets = make([]AlertTargetConfig, len(c.Alerting.Targets))
+		for i, target := range c.Alerting.Targets {
+			// Webhook URLs of Slack and others contain their token
+			target.URL = redactSecret(target.URL)
+			target.Headers = redactValues(target.Headers)
+			target.RoutingKey = redactSecret(target.RoutingKey)
+			redacted.Alerting.Targets[i] = target
+		}
+	}
+	return redacted
+}
+
+// redactSecret replaces a secret that is not a reference
+func redactSecret(value string) string {
+	if value == "" || rpcprovider.IsSecretReference(value) {
+		return value
+	}
+	return rpcprovider.RedactedValue
+}
+
+// redactValues replaces the secrets of a header map
+func redactValues(values map[string]string) map[string]string {
+	if len(values) == 0 {
+		return values
+	}
+	redacted := make(map[string]string, len(values))
+	for name, value := range values {
+		redacted[name] = redactSecret(value)
+	}
+	return redacted
+}
+
+// redactLocation masks the user info and query of a remote provider file location, which may hold credentials
+func redactLocation(location string) string {
+	u, err := url.Parse(location)
+	if err != nil || u.Scheme == "" {
+		return location
+	}
+	if u.User == nil && u.RawQuery == "" {
+		return location
+	}
+	masked := *u
+	masked.User = nil
+	masked.RawQuery = ""
+	redacted := masked.String()
+	if u.User != nil {
+		redacted = u.Scheme + "://" + rpcprovider.RedactedValue + "@" + strings.TrimPrefix(redacted, u.Scheme+"://")
+	}
+	if u.RawQuery != "" {
+		redacted += "?" + rpcprovider.RedactedValue
+	}
+	return redacted
+}
@@ -0,0 +1,105 @@
+package events
+
+import (
+	"sync"
+	"time"
+)
+
+// Type defines the kind of an event
+type Type string
+
+const (
+	TypeSnapshotPublished    Type = "snapshot_published"     // Valid providers were written to the output
+	TypeProviderStateChanged Type = "provider_state_changed" // Provider became valid or invalid
+	TypeCycleFinished        Type = "cycle_finished"         // Validation run finished
+)
+
+// Event is a published event, IDs increase by one for every event
+type Event struct {
+	ID   uint64      `json:"id"`
+	Type Type        `json:"type"`
+	Time time.Time   `json:"time"`
+	Data interface{} `json:"data"`
+}
+
+// DefaultHistorySize is the number of events kept for subscribers that reconnect
+const DefaultHistorySize = 256
+
+// subscriberBuffer is the number of events a subscriber may lag behind before it is dropped
+const subscriberBuffer = 64
+
+// Broker fans out events to subscribers and keeps the latest events,
+// so that subscribers can resume after a reconnect without missing any
+type Broker struct {
+	mu          sync.Mutex
+	nextID      uint64
+	history     []Event // Latest events, oldest first
+	historySize int
+	subscribers map[chan Event]struct{}
+	now         func() time.Time
+}
+
+// NewBroker creates a broker keeping the given number of events, zero means DefaultHistorySize
+func NewBroker(historySize int) *Broker {
+	if historySize <= 0 {
+		historySize = DefaultHistorySize
+	}
+	return &Broker{
+		nextID:      1,
+		historySize: historySize,
+		subscribers: make(map[chan Event]struct{}),
+		now:         time.Now,
+	}
+}
+
+// Publish sends an event to all subscribers. Subscribers that lag too far behind are dropped;
+// their channel is closed and they can resubscribe from the last event they received.
+func (b *Broker) Publish(eventType Type, data interface{}) Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	event := Event{ID: b.nextID, Type: eventType, Time: b.now(), Data: data}
+	b.nextID++
+	b.history = append(b.history, event)
+	if len(b.history) > b.historySize {
+		b.history = append([]Event(nil), b.history[len(b.history)-b.historySize:]...)
+	}
+
+	for ch := range b.subscribers {
+		select {
+		case ch <- event:
+		default:
+			delete(b.subscribers, ch)
+			close(ch)
+		}
+	}
+	return event
+}
+
+// Subscribe registers a subscriber and returns its channel, the kept events published after
+// lastEventID (none if it is zero) and a function to unsubscribe
+func (b *Broker) Subscribe(lastEventID uint64) (<-chan Event, []Event, func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var missed []Event
+	if lastEventID > 0 {
+		for _, event := range b.history {
+			if event.ID > lastEventID {
+				missed = append(missed, event)
+			}
+		}
+	}
+
+	ch := make(chan Event, subscriberBuffer)
+	b.subscribers[ch] = struct{}{}
+	unsubscribe := func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if _, ok := b.subscribers[ch]; ok {
+			delete(b.subscribers, ch)
+			close(ch)
+		}
+	}
+	return ch, missed, unsubscribe
+}
@@ -0,0 +1,126 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/friofry/config-health-checker/chainconfig"
+	"github.com/friofry/config-health-checker/checker"
+	requestsrunner "github.com/friofry/config-health-checker/requests-runner"
+	"github.com/friofry/config-health-checker/rpcprovider"
+)
+
+func TestBrokerReplaysMissedEvents(t *testing.T) {
+	broker := NewBroker(2)
+	for i := 0; i < 3; i++ {
+		broker.Publish(TypeCycleFinished, i)
+	}
+
+	_, missed, unsubscribe := broker.Subscribe(0)
+	assert.Empty(t, missed)
+	unsubscribe()
+
+	// Only the two latest events are kept
+	_, missed, unsubscribe = broker.Subscribe(1)
+	defer unsubscribe()
+	require.Len(t, missed, 2)
+	assert.Equal(t, []uint64{2, 3}, []uint64{missed[0].ID, missed[1].ID})
+	assert.Equal(t, 2, missed[1].Data)
+}
+
+func TestBrokerDropsSlowSubscribers(t *testing.T) {
+	broker := NewBroker(0)
+	slow, _, unsubscribe := broker.Subscribe(0)
+	defer unsubscribe()
+
+	for i := 0; i <= subscriberBuffer; i++ {
+		broker.Publish(TypeCycleFinished, i)
+	}
+	received := 0
+	for range slow {
+		received++
+	}
+	assert.Equal(t, subscriberBuffer, received)
+}
+
+// cycleWith returns a published run of a chain with providers a and b and their validation results
+func cycleWith(results map[string]checker.ProviderValidationResult) checker.CycleResult {
+	chain := checker.ChainResult{
+		Chain: chainconfig.ChainConfig{
+			Name: "ethereum", Network: "mainnet", ChainId: 1,
+			Providers: []rpcprovider.RpcProvider{
+				{Name: "a", URL: "https://a.example/v3/secret"},
+				{Name: "b", URL: "https://b.example"},
+			},
+		},
+		Reference: &chainconfig.ReferenceChainConfig{ChainId: 1, Provider: rpcprovider.RpcProvider{Name: "reference"}},
+		Providers: results,
+	}
+	for _, provider := range chain.Chain.Providers {
+		if results[provider.Name].Valid {
+			chain.ValidProviders = append(chain.ValidProviders, provider)
+		}
+	}
+	return checker.CycleResult{Chains: []checker.ChainResult{chain}, Duration: time.Second, Published: true}
+}
+
+func types(events []Event) []Type {
+	var result []Type
+	for _, event := range events {
+		result = append(result, event.Type)
+	}
+	return result
+}
+
+func TestCyclePublisher(t *testing.T) {
+	broker := NewBroker(0)
+	publisher := NewCyclePublisher(broker)
+	healthy := map[string]checker.ProviderValidationResult{"a": {Valid: true}, "b": {Valid: true}}
+
+	publisher.ObserveCycle(context.Background(), cycleWith(healthy))
+	// Providers validated for the first time are reported as changed, event 1 is the change of provider a
+	_, published, unsubscribe := broker.Subscribe(1)
+	unsubscribe()
+	assert.Equal(t, []Type{TypeProviderStateChanged, TypeSnapshotPublished, TypeCycleFinished}, types(published))
+	assert.Equal(t, ProviderStateChanged{ChainID: 1, Chain: "ethereum/mainnet", Provider: "b", Valid: true}, published[0].Data)
+	assert.Equal(t, SnapshotPublished{Changed: true, Chains: []SnapshotChain{
+		{ChainID: 1, Name: "ethereum", Network: "mainnet", Providers: []string{"a", "b"}},
+	}}, published[1].Data)
+	assert.Equal(t, CycleFinished{Health: checker.HealthHealthy, Chains: 1, ValidProviders: 2, Published: true, DurationMs: 1000},
+		published[2].Data)
+
+	last := published[2].ID
+	publisher.ObserveCycle(context.Background(), cycleWith(map[string]checker.ProviderValidationResult{
+		"a": {Valid: true},
+		"b": {FailedMethods: map[string]checker.FailedMethodResult{
+			"eth_blockNumber": {Error: &requestsrunner.ProviderError{Class: requestsrunner.ErrorClassTimeout, Err: errors.New("timeout")}},
+		}},
+	}))
+	_, published, unsubscribe = broker.Subscribe(last)
+	unsubscribe()
+	require.Equal(t, []Type{TypeProviderStateChanged, TypeSnapshotPublished, TypeCycleFinished}, types(published))
+	assert.Equal(t, ProviderStateChanged{
+		ChainID: 1, Chain: "ethereum/mainnet", Provider: "b", Reason: "eth_blockNumber (timeout)",
+	}, published[0].Data)
+
+	// An unchanged snapshot is published without provider state changes
+	last = published[2].ID
+	unpublished := cycleWith(healthy)
+	unpublished.Published = false
+	publisher.ObserveCycle(context.Background(), cycleWith(healthy))
+	publisher.ObserveCycle(context.Background(), cycleWith(healthy))
+	publisher.ObserveCycle(context.Background(), unpublished)
+	_, published, unsubscribe = broker.Subscribe(last)
+	unsubscribe()
+	assert.Equal(t, []Type{
+		TypeProviderStateChanged, TypeSnapshotPublished, TypeCycleFinished,
+		TypeSnapshotPublished, TypeCycleFinished,
+		TypeCycleFinished,
+	}, types(published))
+	assert.False(t, published[3].Data.(SnapshotPublished).Changed)
+}
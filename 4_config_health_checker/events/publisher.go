@@ -0,0 +1,163 @@
+package events
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/friofry/config-health-checker/checker"
+	"github.com/friofry/config-health-checker/rpcprovider"
+)
+
+// ProviderStateChanged is the payload of TypeProviderStateChanged.
+// The first validation of a provider is reported as a change too.
+type ProviderStateChanged struct {
+	ChainID  int64  `json:"chainId"`
+	Chain    string `json:"chain"` // "name/network" of the chain
+	Provider string `json:"provider"`
+	Valid    bool   `json:"valid"`
+	Reason   string `json:"reason,omitempty"` // Failed methods of an invalid provider
+}
+
+// SnapshotChain lists the valid providers of a chain in the order the proxy tries them
+type SnapshotChain struct {
+	ChainID   int64    `json:"chainId"`
+	Name      string   `json:"name"`
+	Network   string   `json:"network"`
+	Providers []string `json:"providers"`
+}
+
+// SnapshotPublished is the payload of TypeSnapshotPublished. Provider credentials are not included.
+type SnapshotPublished struct {
+	Changed bool            `json:"changed"` // The valid providers differ from the previous snapshot
+	Chains  []SnapshotChain `json:"chains"`
+}
+
+// CycleFinished is the payload of TypeCycleFinished
+type CycleFinished struct {
+	StartedAt      time.Time      `json:"startedAt"`
+	DurationMs     int64          `json:"durationMs"`
+	Health         checker.Health `json:"health"`
+	Chains         int            `json:"chains"`
+	ValidProviders int            `json:"validProviders"`
+	Published      bool           `json:"published"`         // Valid providers were written to the output
+	ChainID        int64          `json:"chainId,omitempty"` // Only chain validated, omitted for a run of all chains
+}
+
+// CyclePublisher publishes the results of validation runs to a broker
+type CyclePublisher struct {
+	mu       sync.Mutex
+	broker   *Broker
+	states   map[string]bool // Validity of providers by chain ID and name
+	snapshot []SnapshotChain // Last published snapshot
+}
+
+// NewCyclePublisher creates a publisher. It keeps provider states across runs, so a single
+// publisher must observe all runs.
+func NewCyclePublisher(broker *Broker) *CyclePublisher {
+	return &CyclePublisher{broker: broker, states: make(map[string]bool)}
+}
+
+// ObserveCycle implements checker.CycleObserver. Provider state changes are published first,
+// followed by the snapshot, if it was written, and the summary of the run.
+func (p *CyclePublisher) ObserveCycle(ctx context.Context, cycle checker.CycleResult) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	snapshot := make([]SnapshotChain, 0, len(cycle.Chains))
+	validProviders := 0
+	for _, chain := range cycle.Chains {
+		if !chain.Checked() {
+			continue
+		}
+		p.publishStateChanges(chain)
+
+		if len(chain.ValidProviders) == 0 {
+			continue
+		}
+		snapshotChain := SnapshotChain{
+			ChainID: int64(chain.Chain.ChainId),
+			Name:    chain.Chain.Name,
+			Network: chain.Chain.Network,
+		}
+		for _, provider := range chain.ValidProviders {
+			snapshotChain.Providers = append(snapshotChain.Providers, provider.Name)
+		}
+		validProviders += len(chain.ValidProviders)
+		snapshot = append(snapshot, snapshotChain)
+	}
+
+	if cycle.ChainID != 0 {
+		// A run of a single chain replaces only that chain in the output
+		for _, chain := range p.snapshot {
+			if chain.ChainID != cycle.ChainID {
+				snapshot = append(snapshot, chain)
+			}
+		}
+		sort.Slice(snapshot, func(i, j int) bool {
+			return snapshot[i].ChainID < snapshot[j].ChainID
+		})
+	}
+
+	if cycle.Published {
+		p.broker.Publish(TypeSnapshotPublished, SnapshotPublished{
+			Changed: !reflect.DeepEqual(snapshot, p.snapshot),
+			Chains:  snapshot,
+		})
+		p.snapshot = snapshot
+	}
+
+	p.broker.Publish(TypeCycleFinished, CycleFinished{
+		StartedAt:      cycle.StartedAt,
+		DurationMs:     cycle.Duration.Milliseconds(),
+		Health:         cycle.Health(),
+		Chains:         len(cycle.Chains),
+		ValidProviders: validProviders,
+		Published:      cycle.Published,
+		ChainID:        cycle.ChainID,
+	})
+}
+
+// publishStateChanges publishes the enabled providers of a chain whose validity changed
+func (p *CyclePublisher) publishStateChanges(chain checker.ChainResult) {
+	valid := make(map[string]bool, len(chain.ValidProviders))
+	for _, provider := range chain.ValidProviders {
+		valid[provider.Name] = true
+	}
+
+	chainID := int64(chain.Chain.ChainId)
+	for _, provider := range rpcprovider.EnabledProviders(chain.Chain.Providers) {
+		key := fmt.Sprintf("%d/%s", chainID, provider.Name)
+		previous, known := p.states[key]
+		isValid := valid[provider.Name]
+		p.states[key] = isValid
+		if known && previous == isValid {
+			continue
+		}
+
+		change := ProviderStateChanged{
+			ChainID:  chainID,
+			Chain:    chain.Chain.Name + "/" + chain.Chain.Network,
+			Provider: provider.Name,
+			Valid:    isValid,
+		}
+		if !isValid {
+			change.Reason = failedMethods(chain.Providers[provider.Name])
+		}
+		p.broker.Publish(TypeProviderStateChanged, change)
+	}
+}
+
+// failedMethods lists the failed methods of a provider with their error classes
+func failedMethods(result checker.ProviderValidationResult) string {
+	methods := make([]string, 0, len(result.FailedMethods))
+	for method, failed := range result.FailedMethods {
+		methods = append(methods, fmt.Sprintf("%s (%s)", method, failed.ErrorClass()))
+	}
+	sort.Strings(methods)
+	return strings.Join(methods, ", ")
+}
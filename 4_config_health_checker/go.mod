@@ -2,7 +2,12 @@ module github.com/friofry/config-health-checker
 
 go 1.21.13
 
-require github.com/stretchr/testify v1.10.0
+require (
+	github.com/go-playground/validator/v10 v10.23.0
+	github.com/stretchr/testify v1.10.0
+	go.etcd.io/bbolt v1.3.9
+	golang.org/x/net v0.21.0
+)
 
 require (
 	github.com/benbjohnson/clock v1.3.5 // indirect
@@ -10,11 +15,9 @@ require (
 	github.com/gabriel-vasile/mimetype v1.4.3 // indirect
 	github.com/go-playground/locales v0.14.1 // indirect
 	github.com/go-playground/universal-translator v0.18.1 // indirect
-	github.com/go-playground/validator/v10 v10.23.0 // indirect
 	github.com/leodido/go-urn v1.4.0 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
 	golang.org/x/crypto v0.19.0 // indirect
-	golang.org/x/net v0.21.0 // indirect
 	golang.org/x/sys v0.17.0 // indirect
 	golang.org/x/text v0.14.0 // indirect
 	gopkg.in/yaml.v3 v3.0.1 // indirect
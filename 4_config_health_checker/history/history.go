@@ -0,0 +1,301 @@
+package history
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"sort"
+	"time"
+
+	"go.etcd.io/bbolt"
+
+	requestsrunner "github.com/friofry/config-health-checker/requests-runner"
+)
+
+// DefaultRetention is used when no retention is configured
+const DefaultRetention = 7 * 24 * time.Hour
+
+// Record is the outcome of a single method of a provider in one validation run
+type Record struct {
+	Time       time.Time                 `json:"time"`                 // Start of the validation run
+	ChainID    int64                     `json:"chainId"`              // Chain of the provider
+	Provider   string                    `json:"provider"`             // Provider name
+	Method     string                    `json:"method"`               // Checked method
+	Success    bool                      `json:"success"`              // Method passed validation
+	Throttled  bool                      `json:"throttled,omitempty"`  // Provider was rate-limited, the method was not validated
+	LatencyMs  int64                     `json:"latencyMs"`            // Time taken by the request, including retries
+	ErrorClass requestsrunner.ErrorClass `json:"errorClass,omitempty"` // Class of the failure
+	Error      string                    `json:"error,omitempty"`      // Reason of the failure, credentials are redacted
+}
+
+// Query selects records, zero values match everything
+type Query struct {
+	ChainID  int64     // Chain to match
+	Provider string    // Provider to match
+	Method   string    // Method to match
+	From     time.Time // Inclusive start of the time range
+	To       time.Time // Exclusive end of the time range
+}
+
+// matches reports whether the record is selected by the query
+func (q Query) matches(record Record) bool {
+	return (q.ChainID == 0 || record.ChainID == q.ChainID) &&
+		(q.Provider == "" || record.Provider == q.Provider) &&
+		(q.Method == "" || record.Method == q.Method) &&
+		(q.From.IsZero() || !record.Time.Before(q.From)) &&
+		(q.To.IsZero() || record.Time.Before(q.To))
+}
+
+// Buckets of the history database
+var (
+	recordsBucket   = []byte("records")   // Time key -> JSON record, ordered by time
+	providersBucket = []byte("providers") // Chain, provider, method and time key -> empty, ordered by provider and time
+)
+
+// timeKeyLength is the length of a time key: the record time in Unix nanoseconds and a sequence number
+const timeKeyLength = 16
+
+// Store keeps validation records in an embedded database, indexed by time and by provider, method and time.
+// Records older than the retention are deleted when records are appended.
+type Store struct {
+	db        *bbolt.DB
+	retention time.Duration
+	now       func() time.Time
+}
+
+// Open opens the database at path, creating it if needed.
+// A zero retention means DefaultRetention.
+func Open(path string, retention time.Duration) (*Store, error) {
+	return open(path, retention, time.Now)
+}
+
+// open opens the store using now as the clock for the retention
+func open(path string, retention time.Duration, now func() time.Time) (*Store, error) {
+	if retention <= 0 {
+		retention = DefaultRetention
+	}
+
+	// Another process holding the database fails the open instead of blocking it
+	db, err := bbolt.Open(path, 0644, &bbolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open history database: %w", err)
+	}
+	s := &Store{db: db, retention: retention, now: now}
+
+	err = db.Update(func(tx *bbolt.Tx) error {
+		for _, name := range [][]byte{recordsBucket, providersBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return s.prune(tx)
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to initialize history database: %w", err)
+	}
+	return s, nil
+}
+
+// timeKey returns the key of a record in the records bucket
+func timeKey(t time.Time, seq uint64) []byte {
+	key := make([]byte, timeKeyLength)
+	binary.BigEndian.PutUint64(key, uint64(t.UnixNano()))
+	binary.BigEndian.PutUint64(key[8:], seq)
+	return key
+}
+
+// keyTime returns the record time of a time key
+func keyTime(key []byte) time.Time {
+	return time.Unix(0, int64(binary.BigEndian.Uint64(key)))
+}
+
+// providerPrefix returns the prefix of the index keys of a provider, and of one of its methods if method is set
+func providerPrefix(chainID int64, provider, method string) []byte {
+	prefix := binary.BigEndian.AppendUint64(nil, uint64(chainID))
+	prefix = append(append(prefix, provider...), 0)
+	if method != "" {
+		prefix = append(append(prefix, method...), 0)
+	}
+	return prefix
+}
+
+// indexKey returns the key of a record in the providers bucket
+func indexKey(record Record, key []byte) []byte {
+	return append(providerPrefix(record.ChainID, record.Provider, record.Method), key...)
+}
+
+// Append stores records and deletes the records that are past the retention
+func (s *Store) Append(records ...Record) error {
+	if len(records) == 0 {
+		return nil
+	}
+
+	err := s.db.Update(func(tx *bbolt.Tx) error {
+		recordsB, providersB := tx.Bucket(recordsBucket), tx.Bucket(providersBucket)
+		for _, record := range records {
+			data, err := json.Marshal(record)
+			if err != nil {
+				return fmt.Errorf("failed to marshal history record: %w", err)
+			}
+			seq, err := recordsB.NextSequence()
+			if err != nil {
+				return err
+			}
+			key := timeKey(record.Time, seq)
+			if err := recordsB.Put(key, data); err != nil {
+				return err
+			}
+			if err := providersB.Put(indexKey(record, key), nil); err != nil {
+				return err
+			}
+		}
+		return s.prune(tx)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to write history records: %w", err)
+	}
+	return nil
+}
+
+// prune deletes the records that are past the retention, along with their index keys
+func (s *Store) prune(tx *bbolt.Tx) error {
+	cutoff := s.now().Add(-s.retention)
+	recordsB, providersB := tx.Bucket(recordsBucket), tx.Bucket(providersBucket)
+
+	// Keys are collected first, deleting while iterating would skip keys
+	var recordKeys, indexKeys [][]byte
+	cursor := recordsB.Cursor()
+	for key, data := cursor.First(); key != nil && keyTime(key).Before(cutoff); key, data = cursor.Next() {
+		var record Record
+		if err := json.Unmarshal(data, &record); err == nil {
+			indexKeys = append(indexKeys, indexKey(record, key))
+		}
+		recordKeys = append(recordKeys, append([]byte(nil), key...))
+	}
+	for _, key := range indexKeys {
+		if err := providersB.Delete(key); err != nil {
+			return err
+		}
+	}
+	for _, key := range recordKeys {
+		if err := recordsB.Delete(key); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Close closes the history database
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Records returns the records selected by the query ordered by time.
+// Queries of a chain and provider use the provider index, all others scan the time range.
+func (s *Store) Records(q Query) []Record {
+	var records []Record
+	err := s.db.View(func(tx *bbolt.Tx) error {
+		if q.ChainID != 0 && q.Provider != "" {
+			return scanProvider(tx, q, &records)
+		}
+		return scanTime(tx, q, &records)
+	})
+	if err != nil {
+		slog.Warn("failed to read history records", "error", err)
+	}
+	return records
+}
+
+// scanTime appends the records of the query time range that match the query
+func scanTime(tx *bbolt.Tx, q Query, records *[]Record) error {
+	cursor := tx.Bucket(recordsBucket).Cursor()
+	key, data := cursor.First()
+	if !q.From.IsZero() {
+		key, data = cursor.Seek(timeKey(q.From, 0))
+	}
+	for ; key != nil; key, data = cursor.Next() {
+		if !q.To.IsZero() && !keyTime(key).Before(q.To) {
+			break
+		}
+		var record Record
+		if err := json.Unmarshal(data, &record); err != nil {
+			return fmt.Errorf("failed to parse history record: %w", err)
+		}
+		if q.matches(record) {
+			*records = append(*records, record)
+		}
+	}
+	return nil
+}
+
+// scanProvider appends the records of the query provider, and method if set, that match the query
+func scanProvider(tx *bbolt.Tx, q Query, records *[]Record) error {
+	recordsB := tx.Bucket(recordsBucket)
+	prefix := providerPrefix(q.ChainID, q.Provider, q.Method)
+	cursor := tx.Bucket(providersBucket).Cursor()
+	seek := prefix
+	if q.Method != "" && !q.From.IsZero() {
+		// Keys of a single method are ordered by time
+		seek = append(append([]byte(nil), prefix...), timeKey(q.From, 0)...)
+	}
+	for key, _ := cursor.Seek(seek); key != nil && bytes.HasPrefix(key, prefix); key, _ = cursor.Next() {
+		if len(key) < len(prefix)+timeKeyLength {
+			continue
+		}
+		recordKey := key[len(key)-timeKeyLength:]
+		var record Record
+		if err := json.Unmarshal(recordsB.Get(recordKey), &record); err != nil {
+			return fmt.Errorf("failed to parse history record: %w", err)
+		}
+		if q.matches(record) {
+			*records = append(*records, record)
+		}
+	}
+	// Records of several methods are merged by time
+	sortByTime(*records)
+	return nil
+}
+
+// sortByTime orders records by time, keeping the order of records of the same run
+func sortByTime(records []Record) {
+	sort.SliceStable(records, func(i, j int) bool {
+		return records[i].Time.Before(records[j].Time)
+	})
+}
+
+// Uptime summarises the records selected by the query
+func (s *Store) Uptime(q Query) Uptime {
+	return SummarizeUptime(s.Records(q))
+}
+
+// UptimeByProvider summarises the records selected by the query per provider
+func (s *Store) UptimeByProvider(q Query) map[string]Uptime {
+	byProvider := make(map[string][]Record)
+	for _, record := range s.Records(q) {
+		byProvider[record.Provider] = append(byProvider[record.Provider], record)
+	}
+
+	uptimes := make(map[string]Uptime, len(byProvider))
+	for provider, records := range byProvider {
+		uptimes[provider] = SummarizeUptime(records)
+	}
+	return uptimes
+}
+
+// percentile returns the nearest-rank percentile of the values, zero if there are none
+func percentile(values []int64, p float64) int64 {
+	if len(values) == 0 {
+		return 0
+	}
+	sorted := append([]int64(nil), values...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+	if rank < 1 {
+		rank = 1
+	}
+	return sorted[rank-1]
+}
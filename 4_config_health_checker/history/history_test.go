@@ -0,0 +1,146 @@
+package history
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	requestsrunner "github.com/friofry/config-health-checker/requests-runner"
+)
+
+var now = time.Date(2026, 3, 10, 12, 0, 0, 0, time.UTC)
+
+// openAt opens a store whose clock is fixed at the given time
+func openAt(t *testing.T, path string, retention time.Duration, at time.Time) *Store {
+	t.Helper()
+	store, err := open(path, retention, func() time.Time { return at })
+	require.NoError(t, err)
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func TestStoreAppendAndReopen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.db")
+	store := openAt(t, path, 0, now)
+
+	require.NoError(t, store.Append(
+		Record{Time: now.Add(-time.Minute), ChainID: 1, Provider: "infura", Method: "eth_blockNumber", Success: true, LatencyMs: 120},
+		Record{Time: now.Add(-time.Minute), ChainID: 1, Provider: "alchemy", Method: "eth_blockNumber", LatencyMs: 300,
+			ErrorClass: requestsrunner.ErrorClassResultMismatch, Error: "result differs"},
+	))
+	require.NoError(t, store.Close())
+
+	reopened := openAt(t, path, 0, now)
+	records := reopened.Records(Query{ChainID: 1, Provider: "alchemy"})
+	require.Len(t, records, 1)
+	assert.Equal(t, "result differs", records[0].Error)
+	assert.True(t, records[0].Time.Equal(now.Add(-time.Minute)))
+	assert.Len(t, reopened.Records(Query{}), 2)
+}
+
+func TestStoreRetention(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.db")
+	store := openAt(t, path, time.Hour, now)
+
+	require.NoError(t, store.Append(
+		Record{Time: now.Add(-2 * time.Hour), ChainID: 1, Provider: "old", Method: "eth_blockNumber", Success: true},
+		Record{Time: now.Add(-30 * time.Minute), ChainID: 1, Provider: "recent", Method: "eth_blockNumber", Success: true},
+	))
+	records := store.Records(Query{})
+	require.Len(t, records, 1)
+	assert.Equal(t, "recent", records[0].Provider)
+	assert.Empty(t, store.Records(Query{ChainID: 1, Provider: "old"}), "expired records are removed from the provider index")
+
+	// Records expire once the clock passes the retention, also across reopening
+	require.NoError(t, store.Append(Record{Time: now, ChainID: 1, Provider: "latest", Method: "eth_blockNumber"}))
+	require.NoError(t, store.Close())
+	assert.Len(t, openAt(t, path, time.Hour, now).Records(Query{}), 2)
+	later := openAt(t, filepath.Join(t.TempDir(), "later.db"), time.Hour, now.Add(40*time.Minute))
+	require.NoError(t, later.Append(
+		Record{Time: now.Add(-30 * time.Minute), ChainID: 1, Provider: "recent", Method: "eth_blockNumber"},
+		Record{Time: now, ChainID: 1, Provider: "latest", Method: "eth_blockNumber"},
+	))
+	assert.Len(t, later.Records(Query{}), 1)
+}
+
+func TestRecordsByProvider(t *testing.T) {
+	store := openAt(t, filepath.Join(t.TempDir(), "history.db"), 0, now)
+	require.NoError(t, store.Append(
+		Record{Time: now.Add(-3 * time.Hour), ChainID: 1, Provider: "infura", Method: "eth_getBalance"},
+		Record{Time: now.Add(-2 * time.Hour), ChainID: 1, Provider: "infura", Method: "eth_blockNumber"},
+		Record{Time: now.Add(-2 * time.Hour), ChainID: 1, Provider: "infura-archive", Method: "eth_blockNumber"},
+		Record{Time: now.Add(-2 * time.Hour), ChainID: 10, Provider: "infura", Method: "eth_blockNumber"},
+		Record{Time: now.Add(-time.Hour), ChainID: 1, Provider: "infura", Method: "eth_getBalance"},
+	))
+
+	records := store.Records(Query{ChainID: 1, Provider: "infura"})
+	require.Len(t, records, 3, "other chains and providers sharing the name prefix are not selected")
+	assert.Equal(t, []string{"eth_getBalance", "eth_blockNumber", "eth_getBalance"},
+		[]string{records[0].Method, records[1].Method, records[2].Method}, "records of all methods are ordered by time")
+
+	recent := store.Records(Query{ChainID: 1, Provider: "infura", Method: "eth_getBalance", From: now.Add(-2 * time.Hour), To: now})
+	require.Len(t, recent, 1)
+	assert.True(t, recent[0].Time.Equal(now.Add(-time.Hour)))
+}
+
+func TestOpenRejectsOtherFiles(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.jsonl")
+	require.NoError(t, os.WriteFile(path, []byte(`{"time":"2026-03-10T11:59:00Z","chainId":1}`+"\n"), 0644))
+
+	_, err := Open(path, 0)
+	assert.Error(t, err)
+}
+
+func TestStoreUptime(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "history.db")
+	store := openAt(t, path, 0, now)
+
+	var records []Record
+	for i := 0; i < 20; i++ {
+		record := Record{
+			Time:      now.Add(-time.Duration(20-i) * time.Hour),
+			ChainID:   1,
+			Provider:  "infura",
+			Method:    "eth_blockNumber",
+			Success:   i%5 != 0,
+			LatencyMs: int64(i+1) * 10,
+		}
+		if !record.Success {
+			record.ErrorClass, record.Error = requestsrunner.ErrorClassTimeout, "request timed out"
+		}
+		records = append(records, record)
+	}
+	records = append(records,
+		Record{Time: now.Add(-time.Hour), ChainID: 1, Provider: "infura", Method: "eth_chainId", Throttled: true, Success: false},
+		Record{Time: now.Add(-time.Hour), ChainID: 10, Provider: "infura", Method: "eth_blockNumber", Success: true, LatencyMs: 5},
+	)
+	require.NoError(t, store.Append(records...))
+
+	uptime := store.Uptime(Query{ChainID: 1, Provider: "infura"})
+	assert.Equal(t, 21, uptime.Checks)
+	assert.Equal(t, 4, uptime.Unavailable)
+	assert.Equal(t, 1, uptime.Throttled)
+	assert.InDelta(t, 80, uptime.AvailabilityPercent, 0.001)
+	assert.EqualValues(t, 200, uptime.Latency.P95Ms)
+	require.NotNil(t, uptime.LastFailure)
+	assert.True(t, uptime.LastFailure.Time.Equal(now.Add(-5*time.Hour)))
+
+	// Only the last 10 hours
+	recent := store.Uptime(Query{ChainID: 1, Method: "eth_blockNumber", From: now.Add(-10 * time.Hour), To: now})
+	assert.Equal(t, 10, recent.Checks)
+	assert.Equal(t, 2, recent.Unavailable)
+
+	byProvider := store.UptimeByProvider(Query{ChainID: 10})
+	assert.Equal(t, map[string]Uptime{"infura": {
+		Checks:              1,
+		AvailabilityPercent: 100,
+		CorrectnessPercent:  100,
+		Latency:             Percentiles{P50Ms: 5, P95Ms: 5, P99Ms: 5},
+	}}, byProvider)
+
+	assert.Equal(t, Uptime{}, store.Uptime(Query{Provider: "unknown"}))
+}
@@ -0,0 +1,123 @@
+package history
+
+import (
+	"sort"
+	"time"
+
+	requestsrunner "github.com/friofry/config-health-checker/requests-runner"
+)
+
+// Uptime describes the availability, correctness and latency of one or more providers.
+// The uptime of a provider is its availability: the share of checks it answered, whether or not
+// the answer matched the reference. Throttled checks and checks without a working reference are excluded from the rates.
+type Uptime struct {
+	Checks              int         `json:"checks"`                // Number of records
+	Throttled           int         `json:"throttled"`             // Checks skipped because of rate limiting
+	Unjudged            int         `json:"unjudged"`              // Checks skipped because the reference failed
+	Unavailable         int         `json:"unavailable"`           // Checks without a usable answer: transport, HTTP or JSON-RPC errors
+	Mismatches          int         `json:"mismatches"`            // Answers differing from the reference
+	AvailabilityPercent float64     `json:"availabilityPercent"`   // Share of judged checks the provider answered
+	CorrectnessPercent  float64     `json:"correctnessPercent"`    // Share of answers that matched the reference
+	Latency             Percentiles `json:"latency"`               // Request latencies of answered checks
+	LastFailure         *Record     `json:"lastFailure,omitempty"` // Most recent failure
+}
+
+// Percentiles of request latencies in milliseconds
+type Percentiles struct {
+	P50Ms int64 `json:"p50Ms"`
+	P95Ms int64 `json:"p95Ms"`
+	P99Ms int64 `json:"p99Ms"`
+}
+
+// ProviderUptime is the uptime of a single provider of a chain
+type ProviderUptime struct {
+	Name string `json:"name"`
+	Uptime
+}
+
+// ChainUptime is the uptime of a chain, summarised over all providers and per provider
+type ChainUptime struct {
+	ChainID   int64            `json:"chainId"`
+	Summary   Uptime           `json:"summary"`   // All providers of the chain together
+	Providers []ProviderUptime `json:"providers"` // Ordered by name
+}
+
+// UptimeReport is the uptime of the chains in a time range
+type UptimeReport struct {
+	From   time.Time     `json:"from"`
+	To     time.Time     `json:"to"`
+	Chains []ChainUptime `json:"chains"` // Ordered by chain ID
+}
+
+// UptimeReport computes the uptime per chain and per provider of the records selected by the query
+func (s *Store) UptimeReport(q Query) UptimeReport {
+	byChain := make(map[int64]map[string][]Record)
+	for _, record := range s.Records(q) {
+		if byChain[record.ChainID] == nil {
+			byChain[record.ChainID] = make(map[string][]Record)
+		}
+		byChain[record.ChainID][record.Provider] = append(byChain[record.ChainID][record.Provider], record)
+	}
+
+	report := UptimeReport{From: q.From, To: q.To, Chains: make([]ChainUptime, 0, len(byChain))}
+	for chainID, byProvider := range byChain {
+		chain := ChainUptime{ChainID: chainID, Providers: make([]ProviderUptime, 0, len(byProvider))}
+		var chainRecords []Record
+		for name, records := range byProvider {
+			chain.Providers = append(chain.Providers, ProviderUptime{Name: name, Uptime: SummarizeUptime(records)})
+			chainRecords = append(chainRecords, records...)
+		}
+		sort.Slice(chain.Providers, func(i, j int) bool {
+			return chain.Providers[i].Name < chain.Providers[j].Name
+		})
+		sortByTime(chainRecords)
+		chain.Summary = SummarizeUptime(chainRecords)
+		report.Chains = append(report.Chains, chain)
+	}
+	sort.Slice(report.Chains, func(i, j int) bool {
+		return report.Chains[i].ChainID < report.Chains[j].ChainID
+	})
+	return report
+}
+
+// SummarizeUptime computes the uptime of records ordered by time
+func SummarizeUptime(records []Record) Uptime {
+	var uptime Uptime
+	var answered int
+	latencies := make([]int64, 0, len(records))
+	for i, record := range records {
+		uptime.Checks++
+		switch {
+		case record.Throttled:
+			uptime.Throttled++
+			continue
+		case record.ErrorClass == requestsrunner.ErrorClassReferenceFailure:
+			uptime.Unjudged++
+			continue
+		case record.Success:
+			answered++
+			latencies = append(latencies, record.LatencyMs)
+			continue
+		case record.ErrorClass == requestsrunner.ErrorClassResultMismatch:
+			answered++
+			uptime.Mismatches++
+			latencies = append(latencies, record.LatencyMs)
+		default:
+			uptime.Unavailable++
+		}
+		uptime.LastFailure = &records[i]
+	}
+
+	if judged := answered + uptime.Unavailable; judged > 0 {
+		uptime.AvailabilityPercent = float64(answered) * 100 / float64(judged)
+	}
+	if answered > 0 {
+		uptime.CorrectnessPercent = float64(answered-uptime.Mismatches) * 100 / float64(answered)
+	}
+	uptime.Latency = Percentiles{
+		P50Ms: percentile(latencies, 50),
+		P95Ms: percentile(latencies, 95),
+		P99Ms: percentile(latencies, 99),
+	}
+	return uptime
+}
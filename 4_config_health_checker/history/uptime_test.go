@@ -0,0 +1,67 @@
+package history
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	requestsrunner "github.com/friofry/config-health-checker/requests-runner"
+)
+
+func TestSummarizeUptime(t *testing.T) {
+	at := now.Add(-time.Hour)
+	uptime := SummarizeUptime([]Record{
+		{Time: at, Success: true, LatencyMs: 100},
+		{Time: at, Success: true, LatencyMs: 200},
+		{Time: at, Success: true, LatencyMs: 300},
+		{Time: at, ErrorClass: requestsrunner.ErrorClassResultMismatch, LatencyMs: 400},
+		{Time: at, ErrorClass: requestsrunner.ErrorClassTimeout, LatencyMs: 5000},
+		{Time: at, ErrorClass: requestsrunner.ErrorClassReferenceFailure, LatencyMs: 50},
+		{Time: at, Throttled: true, Success: false, LatencyMs: 10},
+	})
+
+	assert.Equal(t, 7, uptime.Checks)
+	assert.Equal(t, 1, uptime.Throttled)
+	assert.Equal(t, 1, uptime.Unjudged)
+	assert.Equal(t, 1, uptime.Unavailable)
+	assert.Equal(t, 1, uptime.Mismatches)
+	assert.InDelta(t, 80, uptime.AvailabilityPercent, 0.001)
+	assert.InDelta(t, 75, uptime.CorrectnessPercent, 0.001)
+	assert.Equal(t, Percentiles{P50Ms: 200, P95Ms: 400, P99Ms: 400}, uptime.Latency, "timeouts are not answers")
+	require.NotNil(t, uptime.LastFailure)
+	assert.Equal(t, requestsrunner.ErrorClassTimeout, uptime.LastFailure.ErrorClass)
+
+	assert.Equal(t, Uptime{}, SummarizeUptime(nil))
+}
+
+func TestUptimeReport(t *testing.T) {
+	store := openAt(t, filepath.Join(t.TempDir(), "history.db"), 0, now)
+	require.NoError(t, store.Append(
+		Record{Time: now.Add(-3 * time.Hour), ChainID: 10, Provider: "public", Method: "eth_blockNumber", Success: true, LatencyMs: 30},
+		Record{Time: now.Add(-2 * time.Hour), ChainID: 1, Provider: "infura", Method: "eth_blockNumber", Success: true, LatencyMs: 10},
+		Record{Time: now.Add(-2 * time.Hour), ChainID: 1, Provider: "alchemy", Method: "eth_blockNumber", ErrorClass: requestsrunner.ErrorClassServerError},
+		Record{Time: now.Add(-time.Hour), ChainID: 1, Provider: "alchemy", Method: "eth_blockNumber", Success: true, LatencyMs: 20},
+		Record{Time: now.Add(-48 * time.Hour), ChainID: 1, Provider: "infura", Method: "eth_blockNumber", ErrorClass: requestsrunner.ErrorClassTimeout},
+	))
+
+	report := store.UptimeReport(Query{From: now.Add(-24 * time.Hour), To: now})
+	require.Len(t, report.Chains, 2)
+
+	mainnet := report.Chains[0]
+	assert.EqualValues(t, 1, mainnet.ChainID)
+	assert.Equal(t, 3, mainnet.Summary.Checks)
+	assert.InDelta(t, 200.0/3, mainnet.Summary.AvailabilityPercent, 0.001)
+	require.Len(t, mainnet.Providers, 2)
+	assert.Equal(t, "alchemy", mainnet.Providers[0].Name)
+	assert.InDelta(t, 50, mainnet.Providers[0].AvailabilityPercent, 0.001)
+	assert.Equal(t, "infura", mainnet.Providers[1].Name)
+	assert.InDelta(t, 100, mainnet.Providers[1].AvailabilityPercent, 0.001, "failures before the range are ignored")
+	assert.InDelta(t, 100, mainnet.Providers[1].CorrectnessPercent, 0.001)
+
+	filtered := store.UptimeReport(Query{ChainID: 10, From: now.Add(-24 * time.Hour), To: now})
+	require.Len(t, filtered.Chains, 1)
+	assert.EqualValues(t, 10, filtered.Chains[0].ChainID)
+}
@@ -0,0 +1,47 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+
+	"github.com/friofry/config-health-checker/configlint"
+)
+
+// runLint implements the lint subcommand and returns the process exit code:
+// 0 without errors, 1 if errors were found, 2 for invalid usage or an unreadable checker config
+func runLint(args []string, stdout, stderr io.Writer) int {
+	flags := flag.NewFlagSet("lint", flag.ContinueOnError)
+	flags.SetOutput(stderr)
+	configFlags := registerConfigFlags(flags)
+	if err := flags.Parse(args); err != nil {
+		return 2
+	}
+
+	loaded, err := loadConfig(configFlags)
+	if err != nil {
+		fmt.Fprintf(stderr, "error: %v\n", err)
+		return 2
+	}
+	config, err := loaded.local(context.Background())
+	if err != nil {
+		fmt.Fprintf(stderr, "error: %v\n", err)
+		return 2
+	}
+
+	report := configlint.Lint(config)
+	errorCount := 0
+	for _, issue := range report.Issues {
+		fmt.Fprintln(stdout, issue)
+		if issue.Severity == configlint.SeverityError {
+			errorCount++
+		}
+	}
+	fmt.Fprintf(stdout, "%d error(s), %d warning(s)\n", errorCount, len(report.Issues)-errorCount)
+
+	if report.HasErrors() {
+		return 1
+	}
+	return 0
+}
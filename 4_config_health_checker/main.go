@@ -2,83 +2,155 @@ package main
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
 	"flag"
+	"fmt"
 	"log"
 	"os"
 	"time"
 
+	"github.com/friofry/config-health-checker/alerting"
 	"github.com/friofry/config-health-checker/checker"
 	"github.com/friofry/config-health-checker/confighttpserver"
-	"github.com/friofry/config-health-checker/configreader"
+	"github.com/friofry/config-health-checker/events"
+	"github.com/friofry/config-health-checker/history"
+	"github.com/friofry/config-health-checker/overrides"
 	"github.com/friofry/config-health-checker/periodictask"
 	requestsrunner "github.com/friofry/config-health-checker/requests-runner"
+	"github.com/friofry/config-health-checker/sources"
 )
 
 func main() {
-	// Parse command line flags
-	checkerConfigPath := flag.String("checker-config", "checker_config.json", "path to checker config")
-	defaultProvidersPath := flag.String("default-providers", "", "path to default providers JSON")
-	referenceProvidersPath := flag.String("reference-providers", "", "path to reference providers JSON")
+	// Subcommands
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "lint":
+			os.Exit(runLint(os.Args[2:], os.Stdout, os.Stderr))
+		case "check":
+			os.Exit(runCheck(os.Args[2:], os.Stdout, os.Stderr))
+		}
+	}
+
+	// Parse command line flags. Every config field also has a flag, e.g. -http-server.auth-type
+	configFlags := registerConfigFlags(flag.CommandLine)
+	printConfig := flag.Bool("print-config", false, "print the effective config with secrets redacted and exit")
 	flag.Parse()
 
-	// Read configuration
-	config, err := configreader.ReadConfig(*checkerConfigPath)
+	loaded, err := loadConfig(configFlags)
 	if err != nil {
 		log.Fatalf("failed to read checker configuration: %v", err)
 	}
+	config := loaded.CheckerConfig
+	if *printConfig {
+		data, err := json.MarshalIndent(config.Redacted(), "", "  ")
+		if err != nil {
+			log.Fatalf("failed to print configuration: %v", err)
+		}
+		fmt.Println(string(data))
+		return
+	}
 
-	// Set provider paths from flags if provided
-	if *defaultProvidersPath != "" {
-		config.DefaultProvidersPath = *defaultProvidersPath
+	// Create EVM method caller using RequestsRunner
+	runnerOptions, err := checker.RunnerOptionsFromConfig(*config)
+	if err != nil {
+		log.Fatalf("invalid requests runner configuration: %v", err)
 	}
-	if *referenceProvidersPath != "" {
-		config.ReferenceProvidersPath = *referenceProvidersPath
+	caller := requestsrunner.NewRequestsRunnerWithOptions(runnerOptions)
+
+	// Restore circuit breaker states saved by a previous run
+	circuitStatePath := config.CircuitBreaker.StatePath
+	if circuitStatePath != "" {
+		if err := caller.LoadCircuitStates(circuitStatePath); err != nil && !errors.Is(err, os.ErrNotExist) {
+			log.Printf("failed to restore circuit states: %v", err)
+		}
 	}
-	if err != nil {
-		log.Fatalf("failed to read configuration: %v", err)
+
+	// Open the store recording validation results over time
+	var historyStore *history.Store
+	if config.History.Path != "" {
+		historyStore, err = history.Open(config.History.Path, time.Duration(config.History.RetentionHours)*time.Hour)
+		if err != nil {
+			log.Fatalf("failed to open history: %v", err)
+		}
+		defer historyStore.Close()
 	}
 
-	// Create EVM method caller using RequestsRunner
-	caller := requestsrunner.NewRequestsRunner()
+	// Alerts keep their state across validation runs
+	var alerter *alerting.Alerter
+	if len(config.Alerting.Targets) > 0 {
+		if alerter, err = alerting.FromConfig(*config); err != nil {
+			log.Fatalf("invalid alerting configuration: %v", err)
+		}
+	}
 
-	// Create validation function
-	validationFunc := func() {
-		// Create fresh runner for each execution
-		// Create a copy of config with updated provider paths
-		runnerConfig := *config
-		if *defaultProvidersPath != "" {
-			runnerConfig.DefaultProvidersPath = *defaultProvidersPath
+	// Manual provider overrides are honored by every validation run
+	var overrideStore *overrides.Store
+	if config.Overrides.Path != "" {
+		overrideStore, err = overrides.Open(config.Overrides.Path, config.Overrides.AuditPath,
+			time.Duration(config.Overrides.MaxTTLSeconds)*time.Second)
+		if err != nil {
+			log.Fatalf("failed to open overrides: %v", err)
 		}
-		if *referenceProvidersPath != "" {
-			runnerConfig.ReferenceProvidersPath = *referenceProvidersPath
+	}
+
+	// Health changes are streamed to /events subscribers
+	eventBroker := events.NewBroker(events.DefaultHistorySize)
+	eventPublisher := events.NewCyclePublisher(eventBroker)
+
+	// Create validation function, validating one chain or all chains if chainID is zero
+	validate := func(ctx context.Context, chainID int64) (checker.CycleResult, error) {
+		// Provider files may contain credentials, so only their redacted locations are logged
+		log.Printf("default providers: %s, reference providers: %s",
+			sources.Redact(config.DefaultProvidersPath), sources.Redact(config.ReferenceProvidersPath))
+
+		// Create fresh runner for each execution, fetching remote provider files or using their last good copies
+		runnerConfig, err := loaded.local(ctx)
+		if err != nil {
+			return checker.CycleResult{}, err
 		}
 
 		// Verify provider files exist
 		if _, err := os.Stat(runnerConfig.ReferenceProvidersPath); err != nil {
-			log.Printf("reference providers file not found: %s", runnerConfig.ReferenceProvidersPath)
-			return
+			return checker.CycleResult{}, fmt.Errorf("reference providers file not found: %s", runnerConfig.ReferenceProvidersPath)
 		}
 		if _, err := os.Stat(runnerConfig.DefaultProvidersPath); err != nil {
-			log.Printf("default providers file not found: %s", runnerConfig.DefaultProvidersPath)
-			return
+			return checker.CycleResult{}, fmt.Errorf("default providers file not found: %s", runnerConfig.DefaultProvidersPath)
 		}
-		// print content of reference providers
-		referenceProviders, err := os.ReadFile(runnerConfig.ReferenceProvidersPath)
-		if err != nil {
-			log.Printf("failed to read reference providers file: %v", err)
-			return
-		}
-		// write content of reference providers as json string to log
-		log.Printf("reference providers: %s", referenceProviders)
 
-		// log config
-		log.Printf("config: %v", runnerConfig)
 		runner, err := checker.NewRunnerFromConfig(runnerConfig, caller)
 		if err != nil {
-			log.Printf("failed to create runner: %v", err)
-			return
+			return checker.CycleResult{}, fmt.Errorf("failed to create runner: %w", err)
+		}
+		runner.SetHistory(historyStore)
+		runner.SetOverrides(overrideStore)
+		runner.AddObserver(eventPublisher)
+		if alerter != nil {
+			runner.AddObserver(alerter)
+		}
+		var cycle checker.CycleResult
+		if chainID == 0 {
+			cycle, err = runner.Check(ctx)
+		} else {
+			cycle, err = runner.CheckChain(ctx, chainID)
+		}
+
+		// Persist circuit breaker states across restarts
+		if circuitStatePath != "" {
+			if err := caller.SaveCircuitStates(circuitStatePath); err != nil {
+				log.Printf("failed to save circuit states: %v", err)
+			}
+		}
+		return cycle, err
+	}
+
+	// Periodic and on-demand validations never overlap
+	coordinator := checker.NewCoordinator(validate)
+	validationFunc := func() {
+		run, _ := coordinator.Request(0)
+		if _, err := run.Wait(context.Background()); err != nil {
+			log.Printf("validation failed: %v", err)
 		}
-		runner.Run(context.Background())
 	}
 
 	// Create periodic task for running validation
@@ -95,15 +167,68 @@ func main() {
 	defer validationTask.Stop()
 
 	// Start HTTP server
-	port := os.Getenv("PORT")
+	port := config.HTTPServer.Port
 	if port == "" {
 		port = "8080"
 	}
 
-	server := confighttpserver.New(
-		port,
-		config.OutputProvidersPath,
-	)
+	serverConfig, err := confighttpserver.ServerConfigFromConfig(port, *config)
+	if err != nil {
+		log.Fatalf("invalid HTTP server configuration: %v", err)
+	}
+	server := confighttpserver.NewWithConfig(serverConfig)
+	server.Handle("/status", confighttpserver.JSONHandler(func() interface{} {
+		status := map[string]interface{}{
+			"circuits":  caller.CircuitStates(),
+			"throttles": caller.ThrottleStats(),
+			"batch":     caller.BatchSupport(),
+		}
+		// The same uptime as /reports/uptime without a time range
+		if historyStore != nil {
+			now := time.Now()
+			status["uptime"] = historyStore.UptimeReport(history.Query{From: now.Add(-confighttpserver.DefaultUptimeRange), To: now})
+		}
+		return status
+	}))
+	server.Handle("/events", confighttpserver.EventsHandler(eventBroker))
+	if historyStore != nil {
+		server.Handle("/reports/uptime", confighttpserver.UptimeHandler(historyStore))
+	}
+	// Admin endpoints change what is served, so they require authenticated clients
+	if serverConfig.AuthenticatesClients() {
+		checkHandler := confighttpserver.CheckHandler(coordinator)
+		server.Handle("/check", checkHandler)
+		server.Handle("/check/", checkHandler)
+		if overrideStore != nil {
+			// Overrides refer to the providers of the current provider files
+			currentRunner := func() (*checker.ChainValidationRunner, error) {
+				runnerConfig, err := loaded.local(context.Background())
+				if err != nil {
+					return nil, err
+				}
+				return checker.NewRunnerFromConfig(runnerConfig, caller)
+			}
+			overrideStore.SetKnownProviders(func(chainID int64, provider string) (bool, error) {
+				runner, err := currentRunner()
+				if err != nil {
+					return false, err
+				}
+				return runner.HasProvider(chainID, provider), nil
+			})
+			// Overrides are written to the served providers right away instead of with the next run
+			applyOverrides := func() error {
+				runner, err := currentRunner()
+				if err != nil {
+					return err
+				}
+				runner.SetOverrides(overrideStore)
+				return runner.ApplyOverrides()
+			}
+			server.Handle("/admin/overrides", confighttpserver.OverridesHandler(overrideStore, serverConfig.Auth, applyOverrides))
+		}
+	} else {
+		log.Printf("/check and /admin/overrides are disabled: http_server auth or client certificates are required")
+	}
 	if err := server.Start(); err != nil {
 		log.Fatalf("server failed: %v", err)
 	}
@@ -0,0 +1,362 @@
+package overrides
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// State is the validation outcome forced on a provider
+type State string
+
+const (
+	StateHealthy   State = "healthy"   // Provider is served even if it fails validation
+	StateUnhealthy State = "unhealthy" // Provider is not served even if it passes validation
+)
+
+// ParseState converts a configuration value into a State
+func ParseState(value string) (State, error) {
+	switch state := State(value); state {
+	case StateHealthy, StateUnhealthy:
+		return state, nil
+	default:
+		return "", fmt.Errorf("unknown override state %q, expected healthy or unhealthy", value)
+	}
+}
+
+// DefaultMaxTTL is the longest allowed override when no maximum is configured
+const DefaultMaxTTL = 7 * 24 * time.Hour
+
+// Override pins the state of a provider until it expires
+type Override struct {
+	ChainID   int64     `json:"chainId"`
+	Provider  string    `json:"provider"` // Provider name
+	State     State     `json:"state"`
+	Reason    string    `json:"reason"`         // Why the override was set, e.g. an incident reference
+	Actor     string    `json:"actor"`          // Authenticated client that set the override
+	Note      string    `json:"note,omitempty"` // Free text given by the client, e.g. the person behind a shared token
+	CreatedAt time.Time `json:"createdAt"`
+	ExpiresAt time.Time `json:"expiresAt"`
+}
+
+// key identifies the provider of an override
+func key(chainID int64, provider string) string {
+	return fmt.Sprintf("%d/%s", chainID, provider)
+}
+
+// Action is a change of the overrides recorded in the audit log
+type Action string
+
+const (
+	ActionSet    Action = "set"    // Override was created or replaced
+	ActionRemove Action = "remove" // Override was removed before it expired
+	ActionExpire Action = "expire" // Override reached its expiry time
+)
+
+// AuditEntry records a change of the overrides
+type AuditEntry struct {
+	Time     time.Time `json:"time"`
+	Action   Action    `json:"action"`
+	Actor    string    `json:"actor,omitempty"` // Authenticated client that made the change, empty for expiries
+	Note     string    `json:"note,omitempty"`  // Free text given by the client
+	Error    string    `json:"error,omitempty"` // Why the change could not be saved, empty for applied changes
+	Override Override  `json:"override"`
+}
+
+// overridesFile is the persisted form of the active overrides
+type overridesFile struct {
+	Overrides []Override `json:"overrides"`
+}
+
+// KnownProvider reports whether a chain has a provider of the given name
+type KnownProvider func(chainID int64, provider string) (bool, error)
+
+// Store keeps the active overrides in a JSON file and appends every change to an audit log.
+// Changes that cannot be saved are audited as failed, changes that cannot be audited are refused.
+type Store struct {
+	mu        sync.Mutex
+	path      string
+	auditPath string // JSON lines audit log, changes are only logged when empty
+	maxTTL    time.Duration
+	known     KnownProvider       // Providers overrides may be set for, any provider when nil
+	overrides map[string]Override // Active overrides by chain ID and provider
+	logger    *slog.Logger
+	now       func() time.Time
+}
+
+// Open loads the overrides of the file at path. A zero maxTTL means DefaultMaxTTL.
+func Open(path, auditPath string, maxTTL time.Duration) (*Store, error) {
+	return open(path, auditPath, maxTTL, time.Now)
+}
+
+// open loads the store using now as the clock for expiries
+func open(path, auditPath string, maxTTL time.Duration, now func() time.Time) (*Store, error) {
+	if maxTTL <= 0 {
+		maxTTL = DefaultMaxTTL
+	}
+	s := &Store{
+		path:      path,
+		auditPath: auditPath,
+		maxTTL:    maxTTL,
+		overrides: make(map[string]Override),
+		logger:    slog.Default(),
+		now:       now,
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to read overrides file: %w", err)
+	}
+	if len(data) > 0 {
+		var file overridesFile
+		if err := json.Unmarshal(data, &file); err != nil {
+			return nil, fmt.Errorf("failed to parse overrides file %s: %w", path, err)
+		}
+		for _, override := range file.Overrides {
+			s.overrides[key(override.ChainID, override.Provider)] = override
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.expire(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// ErrInvalid is returned by Set for overrides with missing or invalid fields
+var ErrInvalid = errors.New("invalid override")
+
+// SetKnownProviders restricts new overrides to the providers known reports
+func (s *Store) SetKnownProviders(known KnownProvider) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.known = known
+}
+
+// Set creates or replaces the override of a provider, expiring after ttl
+func (s *Store) Set(override Override, ttl time.Duration) (Override, error) {
+	switch {
+	case override.ChainID <= 0:
+		return Override{}, fmt.Errorf("%w: chainId must be positive", ErrInvalid)
+	case strings.TrimSpace(override.Provider) == "":
+		return Override{}, fmt.Errorf("%w: provider is required", ErrInvalid)
+	case strings.TrimSpace(override.Reason) == "":
+		return Override{}, fmt.Errorf("%w: reason is required", ErrInvalid)
+	case ttl <= 0:
+		return Override{}, fmt.Errorf("%w: ttl must be positive", ErrInvalid)
+	case ttl > s.maxTTL:
+		return Override{}, fmt.Errorf("%w: ttl must not exceed %s", ErrInvalid, s.maxTTL)
+	}
+	if _, err := ParseState(string(override.State)); err != nil {
+		return Override{}, fmt.Errorf("%w: %v", ErrInvalid, err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.known != nil {
+		known, err := s.known(override.ChainID, override.Provider)
+		if err != nil {
+			return Override{}, fmt.Errorf("failed to look up provider: %w", err)
+		}
+		if !known {
+			return Override{}, fmt.Errorf("%w: chain %d has no provider %q", ErrInvalid, override.ChainID, override.Provider)
+		}
+	}
+	if err := s.expire(); err != nil {
+		return Override{}, err
+	}
+
+	override.CreatedAt = s.now().UTC()
+	override.ExpiresAt = override.CreatedAt.Add(ttl)
+	k := key(override.ChainID, override.Provider)
+	previous, existed := s.overrides[k]
+	s.overrides[k] = override
+	rollback := func() {
+		if existed {
+			s.overrides[k] = previous
+		} else {
+			delete(s.overrides, k)
+		}
+	}
+	if err := s.commit(rollback, AuditEntry{Action: ActionSet, Actor: override.Actor, Note: override.Note, Override: override}); err != nil {
+		return Override{}, err
+	}
+	return override, nil
+}
+
+// Remove deletes the override of a provider and reports whether it existed
+func (s *Store) Remove(chainID int64, provider, actor, note string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.expire(); err != nil {
+		return false, err
+	}
+
+	k := key(chainID, provider)
+	override, exists := s.overrides[k]
+	if !exists {
+		return false, nil
+	}
+	delete(s.overrides, k)
+	rollback := func() { s.overrides[k] = override }
+	if err := s.commit(rollback, AuditEntry{Action: ActionRemove, Actor: actor, Note: note, Override: override}); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// Active returns the overrides that have not expired, ordered by chain and provider
+func (s *Store) Active() []Override {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.expire(); err != nil {
+		// Expired overrides are still left out, the next change retries to persist them
+		s.logger.Error("failed to expire overrides", "error", err)
+	}
+
+	now := s.now()
+	active := make([]Override, 0, len(s.overrides))
+	for _, override := range s.overrides {
+		if now.Before(override.ExpiresAt) {
+			active = append(active, override)
+		}
+	}
+	sort.Slice(active, func(i, j int) bool {
+		if active[i].ChainID != active[j].ChainID {
+			return active[i].ChainID < active[j].ChainID
+		}
+		return active[i].Provider < active[j].Provider
+	})
+	return active
+}
+
+// expire removes the overrides past their expiry time and records them in the audit log
+func (s *Store) expire() error {
+	now := s.now()
+	var expired []Override
+	for _, override := range s.overrides {
+		if !now.Before(override.ExpiresAt) {
+			expired = append(expired, override)
+		}
+	}
+	if len(expired) == 0 {
+		return nil
+	}
+
+	sort.Slice(expired, func(i, j int) bool {
+		return key(expired[i].ChainID, expired[i].Provider) < key(expired[j].ChainID, expired[j].Provider)
+	})
+	entries := make([]AuditEntry, 0, len(expired))
+	for _, override := range expired {
+		delete(s.overrides, key(override.ChainID, override.Provider))
+		entries = append(entries, AuditEntry{Action: ActionExpire, Override: override})
+	}
+	rollback := func() {
+		for _, override := range expired {
+			s.overrides[key(override.ChainID, override.Provider)] = override
+		}
+	}
+	return s.commit(rollback, entries...)
+}
+
+// commit saves changes already applied in memory and audits them. If saving fails, rollback restores
+// the previous overrides and the changes are audited as failed. If auditing fails, the previous
+// overrides are restored and saved again.
+func (s *Store) commit(rollback func(), entries ...AuditEntry) error {
+	if err := s.save(); err != nil {
+		rollback()
+		for i := range entries {
+			entries[i].Error = err.Error()
+		}
+		if auditErr := s.audit(entries); auditErr != nil {
+			s.logger.Error("failed to audit failed override change", "error", auditErr)
+		}
+		return err
+	}
+	if err := s.audit(entries); err != nil {
+		rollback()
+		if saveErr := s.save(); saveErr != nil {
+			s.logger.Error("failed to restore overrides file", "error", saveErr)
+		}
+		return err
+	}
+	return nil
+}
+
+// audit logs changes and appends them to the audit log
+func (s *Store) audit(entries []AuditEntry) error {
+	now := s.now().UTC()
+	var data []byte
+	for i := range entries {
+		entries[i].Time = now
+		o := entries[i].Override
+		message := "provider override changed"
+		if entries[i].Error != "" {
+			message = "provider override change failed"
+		}
+		s.logger.Info(message, "action", entries[i].Action, "actor", entries[i].Actor, "note", entries[i].Note,
+			"chainId", o.ChainID, "provider", o.Provider, "state", o.State, "reason", o.Reason, "expiresAt", o.ExpiresAt,
+			"error", entries[i].Error)
+
+		line, err := json.Marshal(entries[i])
+		if err != nil {
+			return fmt.Errorf("failed to marshal audit entry: %w", err)
+		}
+		data = append(append(data, line...), '\n')
+	}
+	if s.auditPath == "" {
+		return nil
+	}
+
+	file, err := os.OpenFile(s.auditPath, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open audit log: %w", err)
+	}
+	defer file.Close()
+	if _, err := file.Write(data); err != nil {
+		return fmt.Errorf("failed to write audit log: %w", err)
+	}
+	return nil
+}
+
+// save writes the overrides to a temporary file and renames it, so that readers never see a partial file
+func (s *Store) save() error {
+	file := overridesFile{Overrides: make([]Override, 0, len(s.overrides))}
+	for _, override := range s.overrides {
+		file.Overrides = append(file.Overrides, override)
+	}
+	sort.Slice(file.Overrides, func(i, j int) bool {
+		a, b := file.Overrides[i], file.Overrides[j]
+		return key(a.ChainID, a.Provider) < key(b.ChainID, b.Provider)
+	})
+
+	data, err := json.MarshalIndent(file, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal overrides: %w", err)
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("failed to create overrides file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write overrides file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write overrides file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), s.path); err != nil {
+		return fmt.Errorf("failed to replace overrides file: %w", err)
+	}
+	return nil
+}
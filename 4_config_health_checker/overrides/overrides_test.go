@@ -0,0 +1,151 @@
+package overrides
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// readAudit returns the actions recorded in the audit log
+func readAudit(t *testing.T, path string) []Action {
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer file.Close()
+
+	var actions []Action
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var entry AuditEntry
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &entry))
+		actions = append(actions, entry.Action)
+	}
+	return actions
+}
+
+func TestStore(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "overrides.json")
+	auditPath := filepath.Join(dir, "audit.jsonl")
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+
+	store, err := open(path, auditPath, time.Hour, clock)
+	require.NoError(t, err)
+
+	override, err := store.Set(Override{ChainID: 1, Provider: "infura", State: StateUnhealthy, Reason: "INC-1", Actor: "alice"}, 30*time.Minute)
+	require.NoError(t, err)
+	assert.Equal(t, now.Add(30*time.Minute), override.ExpiresAt)
+	_, err = store.Set(Override{ChainID: 1, Provider: "alchemy", State: StateHealthy, Reason: "INC-1", Actor: "alice"}, time.Hour)
+	require.NoError(t, err)
+
+	active := store.Active()
+	require.Len(t, active, 2)
+	assert.Equal(t, "alchemy", active[0].Provider)
+
+	// Overrides survive a restart
+	reopened, err := open(path, auditPath, time.Hour, clock)
+	require.NoError(t, err)
+	assert.Equal(t, active, reopened.Active())
+
+	removed, err := store.Remove(1, "alchemy", "bob", "")
+	require.NoError(t, err)
+	assert.True(t, removed)
+	removed, err = store.Remove(1, "alchemy", "bob", "")
+	require.NoError(t, err)
+	assert.False(t, removed)
+
+	now = now.Add(30 * time.Minute)
+	assert.Empty(t, store.Active())
+	assert.Equal(t, []Action{ActionSet, ActionSet, ActionRemove, ActionExpire}, readAudit(t, auditPath))
+
+	reopened, err = open(path, auditPath, time.Hour, clock)
+	require.NoError(t, err)
+	assert.Empty(t, reopened.Active())
+}
+
+func TestStoreRejectsInvalidOverrides(t *testing.T) {
+	store, err := Open(filepath.Join(t.TempDir(), "overrides.json"), "", time.Hour)
+	require.NoError(t, err)
+	valid := Override{ChainID: 1, Provider: "infura", State: StateHealthy, Reason: "INC-1"}
+
+	tests := []struct {
+		name   string
+		modify func(*Override)
+		ttl    time.Duration
+	}{
+		{name: "chain", modify: func(o *Override) { o.ChainID = 0 }, ttl: time.Minute},
+		{name: "provider", modify: func(o *Override) { o.Provider = " " }, ttl: time.Minute},
+		{name: "reason", modify: func(o *Override) { o.Reason = "" }, ttl: time.Minute},
+		{name: "state", modify: func(o *Override) { o.State = "down" }, ttl: time.Minute},
+		{name: "no ttl", modify: func(o *Override) {}, ttl: 0},
+		{name: "ttl above maximum", modify: func(o *Override) {}, ttl: 2 * time.Hour},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			override := valid
+			tt.modify(&override)
+			_, err := store.Set(override, tt.ttl)
+			assert.ErrorIs(t, err, ErrInvalid)
+		})
+	}
+	assert.Empty(t, store.Active())
+}
+
+func TestStoreRejectsUnknownProviders(t *testing.T) {
+	store, err := Open(filepath.Join(t.TempDir(), "overrides.json"), "", time.Hour)
+	require.NoError(t, err)
+	store.SetKnownProviders(func(chainID int64, provider string) (bool, error) {
+		return chainID == 1 && provider == "infura", nil
+	})
+
+	_, err = store.Set(Override{ChainID: 1, Provider: "infura", State: StateHealthy, Reason: "INC-1"}, time.Minute)
+	require.NoError(t, err)
+	_, err = store.Set(Override{ChainID: 1, Provider: "infra", State: StateHealthy, Reason: "INC-1"}, time.Minute)
+	assert.ErrorIs(t, err, ErrInvalid)
+	_, err = store.Set(Override{ChainID: 10, Provider: "infura", State: StateHealthy, Reason: "INC-1"}, time.Minute)
+	assert.ErrorIs(t, err, ErrInvalid)
+	assert.Len(t, store.Active(), 1)
+}
+
+func TestStoreCommit(t *testing.T) {
+	valid := Override{ChainID: 1, Provider: "infura", State: StateHealthy, Reason: "INC-1", Actor: "alice"}
+
+	t.Run("changes that cannot be saved are audited as failed", func(t *testing.T) {
+		dir := t.TempDir()
+		auditPath := filepath.Join(dir, "audit.jsonl")
+		store, err := Open(filepath.Join(dir, "missing", "overrides.json"), auditPath, time.Hour)
+		require.NoError(t, err)
+
+		_, err = store.Set(valid, time.Minute)
+		require.Error(t, err)
+		assert.Empty(t, store.Active())
+
+		data, err := os.ReadFile(auditPath)
+		require.NoError(t, err)
+		var entry AuditEntry
+		require.NoError(t, json.Unmarshal(data, &entry))
+		assert.Equal(t, ActionSet, entry.Action)
+		assert.NotEmpty(t, entry.Error)
+	})
+
+	t.Run("changes that cannot be audited are not saved", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "overrides.json")
+		store, err := Open(path, dir, time.Hour)
+		require.NoError(t, err)
+
+		_, err = store.Set(valid, time.Minute)
+		require.Error(t, err)
+		assert.Empty(t, store.Active())
+
+		reopened, err := Open(path, "", time.Hour)
+		require.NoError(t, err)
+		assert.Empty(t, reopened.Active())
+	})
+}
@@ -0,0 +1,57 @@
+package report
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"time"
+)
+
+// htmlTemplate renders a standalone report page, values are escaped by html/template
+var htmlTemplate = template.Must(template.New("report").Funcs(template.FuncMap{
+	"duration": func(ms int64) time.Duration { return time.Duration(ms) * time.Millisecond },
+	"details":  providerDetails,
+}).Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Provider validation report</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; margin-bottom: 1em; }
+th, td { border: 1px solid #ccc; padding: 4px 8px; text-align: left; }
+pre { background: #f4f4f4; padding: 8px; overflow-x: auto; }
+.healthy, .valid { color: #1a7f37; }
+.degraded, .disabled { color: #9a6700; }
+.unhealthy, .invalid { color: #cf222e; }
+</style>
+</head>
+<body>
+<h1>Provider validation report</h1>
+<p><strong class="{{.Health}}">Overall: {{.Health}}</strong> · {{len .Chains}} chains · {{duration .DurationMs}} · started {{.StartedAt.UTC.Format "2006-01-02T15:04:05Z07:00"}}</p>
+{{range .Chains}}
+<h2>{{.Name}}/{{.Network}} ({{.ChainID}}): <span class="{{.Health}}">{{.Health}}</span></h2>
+{{if .Reason}}<p>{{.Reason}}</p>
+{{end}}{{if and .Checked .Providers}}<table>
+<tr><th>Provider</th><th>URL</th><th>Status</th><th>Details</th></tr>
+{{range .Providers}}<tr><td>{{.Name}}</td><td>{{.URL}}</td><td class="{{.Status}}">{{.Status}}</td><td>{{details .}}</td></tr>
+{{end}}</table>
+{{range $provider := .Providers}}{{range .FailedMethods}}<details>
+<summary>{{$provider.Name}}: {{.Method}}{{if .ErrorClass}} ({{.ErrorClass}}){{end}}</summary>
+{{if .Error}}<p>{{.Error}}</p>
+{{end}}{{if .Response}}<p>Provider response:</p>
+<pre>{{.Response}}</pre>
+{{end}}{{if .ReferenceResponse}}<p>Reference response:</p>
+<pre>{{.ReferenceResponse}}</pre>
+{{end}}</details>
+{{end}}{{end}}{{end}}{{end}}</body>
+</html>
+`))
+
+// WriteHTML writes the report as a standalone HTML page with a table per chain and collapsible failure details
+func WriteHTML(w io.Writer, report Report) error {
+	if err := htmlTemplate.Execute(w, report); err != nil {
+		return fmt.Errorf("failed to write HTML report: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,153 @@
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// junitTestSuites is the root element of a JUnit XML report
+type junitTestSuites struct {
+	XMLName  xml.Name         `xml:"testsuites"`
+	Name     string           `xml:"name,attr"`
+	Tests    int              `xml:"tests,attr"`
+	Failures int              `xml:"failures,attr"`
+	Skipped  int              `xml:"skipped,attr"`
+	Time     string           `xml:"time,attr"`
+	Suites   []junitTestSuite `xml:"testsuite"`
+}
+
+// junitTestSuite contains the test cases of a chain
+type junitTestSuite struct {
+	Name      string          `xml:"name,attr"`
+	Tests     int             `xml:"tests,attr"`
+	Failures  int             `xml:"failures,attr"`
+	Skipped   int             `xml:"skipped,attr"`
+	Timestamp string          `xml:"timestamp,attr"`
+	Cases     []junitTestCase `xml:"testcase"`
+}
+
+// junitTestCase is the result of a single method of a provider
+type junitTestCase struct {
+	Name      string        `xml:"name,attr"`
+	ClassName string        `xml:"classname,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	Skipped   *junitSkipped `xml:"skipped,omitempty"`
+}
+
+// junitFailure describes a failed test case
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr,omitempty"`
+	Text    string `xml:",chardata"`
+}
+
+// junitSkipped marks a skipped test case
+type junitSkipped struct {
+	Message string `xml:"message,attr"`
+}
+
+// WriteJUnit writes the report as JUnit XML with a test suite per chain and a test case per provider and method.
+// Failures contain the responses of the provider and the reference provider.
+func WriteJUnit(w io.Writer, report Report) error {
+	suites := junitTestSuites{
+		Name: "config-health-checker",
+		Time: fmt.Sprintf("%.3f", float64(report.DurationMs)/1000),
+	}
+
+	for _, chain := range report.Chains {
+		className := chain.Name + "." + chain.Network
+		suite := junitTestSuite{
+			Name:      fmt.Sprintf("%s/%s (%d)", chain.Name, chain.Network, chain.ChainID),
+			Timestamp: report.StartedAt.UTC().Format("2006-01-02T15:04:05"),
+		}
+
+		if !chain.Checked {
+			// A chain without reference is never served, which fails the whole suite
+			suite.Cases = append(suite.Cases, junitTestCase{
+				Name:      "reference",
+				ClassName: className,
+				Failure:   &junitFailure{Message: chain.Reason},
+			})
+		} else {
+			for _, provider := range chain.Providers {
+				suite.Cases = append(suite.Cases, providerTestCases(className, chain.Methods, provider)...)
+			}
+		}
+
+		for _, testCase := range suite.Cases {
+			suite.Tests++
+			if testCase.Failure != nil {
+				suite.Failures++
+			}
+			if testCase.Skipped != nil {
+				suite.Skipped++
+			}
+		}
+		suites.Tests += suite.Tests
+		suites.Failures += suite.Failures
+		suites.Skipped += suite.Skipped
+		suites.Suites = append(suites.Suites, suite)
+	}
+
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return fmt.Errorf("failed to write JUnit report: %w", err)
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(suites); err != nil {
+		return fmt.Errorf("failed to write JUnit report: %w", err)
+	}
+	if _, err := io.WriteString(w, "\n"); err != nil {
+		return fmt.Errorf("failed to write JUnit report: %w", err)
+	}
+	return nil
+}
+
+// providerTestCases converts a provider summary into a test case per method.
+// A provider of a chain without test methods gets a single test case.
+func providerTestCases(className string, methods []string, provider Provider) []junitTestCase {
+	results := methodResults(methods, provider)
+	if len(results) == 0 {
+		testCase := junitTestCase{Name: provider.Name, ClassName: className}
+		if provider.Status == StatusDisabled {
+			testCase.Skipped = &junitSkipped{Message: "provider disabled"}
+		}
+		return []junitTestCase{testCase}
+	}
+
+	testCases := make([]junitTestCase, 0, len(results))
+	for _, result := range results {
+		testCase := junitTestCase{Name: provider.Name + "/" + result.Method, ClassName: className}
+		switch result.Outcome {
+		case methodDisabled:
+			testCase.Skipped = &junitSkipped{Message: "provider disabled"}
+		case methodThrottled:
+			testCase.Skipped = &junitSkipped{Message: "provider rate-limited, method not validated"}
+		case methodFailed:
+			testCase.Failure = &junitFailure{
+				Message: result.Failure.Error,
+				Type:    result.Failure.ErrorClass,
+				Text:    failureText(*result.Failure),
+			}
+		}
+		testCases = append(testCases, testCase)
+	}
+	return testCases
+}
+
+// failureText describes a failed method with the responses of the provider and the reference provider
+func failureText(failure MethodFailure) string {
+	var lines []string
+	if failure.Error != "" {
+		lines = append(lines, "error: "+failure.Error)
+	}
+	if failure.Response != "" {
+		lines = append(lines, "provider response: "+failure.Response)
+	}
+	if failure.ReferenceResponse != "" {
+		lines = append(lines, "reference response: "+failure.ReferenceResponse)
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,81 @@
+package report
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+)
+
+// WriteMarkdown writes the report as Markdown with a table per chain and collapsible failure details
+func WriteMarkdown(w io.Writer, report Report) error {
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "# Provider validation report\n\n")
+	fmt.Fprintf(&buf, "**Overall: %s** · %d chains · %s · started %s\n",
+		report.Health, len(report.Chains), time.Duration(report.DurationMs)*time.Millisecond,
+		report.StartedAt.UTC().Format(time.RFC3339))
+
+	for _, chain := range report.Chains {
+		fmt.Fprintf(&buf, "\n## %s/%s (%d): %s\n\n", chain.Name, chain.Network, chain.ChainID, chain.Health)
+		if chain.Reason != "" {
+			fmt.Fprintf(&buf, "%s\n\n", chain.Reason)
+		}
+		if len(chain.Providers) == 0 || !chain.Checked {
+			continue
+		}
+
+		buf.WriteString("| Provider | URL | Status | Details |\n| --- | --- | --- | --- |\n")
+		for _, provider := range chain.Providers {
+			fmt.Fprintf(&buf, "| %s | %s | %s | %s |\n", markdownCell(provider.Name), markdownCell(provider.URL),
+				provider.Status, markdownCell(providerDetails(provider)))
+		}
+
+		for _, provider := range chain.Providers {
+			for _, failure := range provider.FailedMethods {
+				writeMarkdownFailure(&buf, provider.Name, failure)
+			}
+		}
+	}
+
+	if _, err := w.Write(buf.Bytes()); err != nil {
+		return fmt.Errorf("failed to write Markdown report: %w", err)
+	}
+	return nil
+}
+
+// writeMarkdownFailure writes a collapsible section with the error and the responses of a failed method
+func writeMarkdownFailure(buf *bytes.Buffer, providerName string, failure MethodFailure) {
+	summary := providerName + ": " + failure.Method
+	if failure.ErrorClass != "" {
+		summary += " (" + failure.ErrorClass + ")"
+	}
+	fmt.Fprintf(buf, "\n<details>\n<summary>%s</summary>\n\n", markdownEscaper.Replace(summary))
+	if failure.Error != "" {
+		fmt.Fprintf(buf, "%s\n\n", markdownEscaper.Replace(failure.Error))
+	}
+	if failure.Response != "" {
+		fmt.Fprintf(buf, "Provider response:\n\n%s\n\n", markdownCodeBlock(failure.Response))
+	}
+	if failure.ReferenceResponse != "" {
+		fmt.Fprintf(buf, "Reference response:\n\n%s\n\n", markdownCodeBlock(failure.ReferenceResponse))
+	}
+	buf.WriteString("</details>\n")
+}
+
+// markdownEscaper escapes characters that would be interpreted as HTML or table syntax
+var markdownEscaper = strings.NewReplacer("<", "&lt;", ">", "&gt;", "|", "\\|")
+
+// markdownCell escapes a value for a single table cell
+func markdownCell(value string) string {
+	return strings.ReplaceAll(markdownEscaper.Replace(value), "\n", " ")
+}
+
+// markdownCodeBlock fences text with a fence longer than any backtick run it contains
+func markdownCodeBlock(text string) string {
+	fence := "```"
+	for strings.Contains(text, fence) {
+		fence += "`"
+	}
+	return fence + "\n" + text + "\n" + fence
+}
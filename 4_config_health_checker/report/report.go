@@ -0,0 +1,300 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/friofry/config-health-checker/checker"
+)
+
+// Format defines the output format of a report
+type Format string
+
+const (
+	FormatTable    Format = "table"    // Human-readable table
+	FormatJSON     Format = "json"     // JSON document, see Report
+	FormatJUnit    Format = "junit"    // JUnit XML, one suite per chain and one test case per provider and method
+	FormatMarkdown Format = "markdown" // Markdown for pull request comments
+	FormatHTML     Format = "html"     // Standalone HTML page
+)
+
+// ParseFormat converts a command line value into a Format, empty means FormatTable
+func ParseFormat(value string) (Format, error) {
+	switch format := Format(value); format {
+	case "":
+		return FormatTable, nil
+	case FormatTable, FormatJSON, FormatJUnit, FormatMarkdown, FormatHTML:
+		return format, nil
+	default:
+		return "", fmt.Errorf("unknown report format %q, expected %s, %s, %s, %s or %s",
+			value, FormatTable, FormatJSON, FormatJUnit, FormatMarkdown, FormatHTML)
+	}
+}
+
+// ContentType returns the MIME type of reports in the format
+func (f Format) ContentType() string {
+	switch f {
+	case FormatJSON:
+		return "application/json"
+	case FormatJUnit:
+		return "application/xml"
+	case FormatMarkdown:
+		return "text/markdown; charset=utf-8"
+	case FormatHTML:
+		return "text/html; charset=utf-8"
+	default:
+		return "text/plain; charset=utf-8"
+	}
+}
+
+// Provider statuses
+const (
+	StatusValid    = "valid"
+	StatusInvalid  = "invalid"
+	StatusDisabled = "disabled"
+)
+
+// Report is the serializable summary of a validation run. Provider credentials are redacted.
+type Report struct {
+	StartedAt  time.Time      `json:"startedAt"`
+	DurationMs int64          `json:"durationMs"`
+	Health     checker.Health `json:"health"`
+	Chains     []Chain        `json:"chains"`
+}
+
+// Chain is the summary of a single chain
+type Chain struct {
+	ChainID   int            `json:"chainId"`
+	Name      string         `json:"name"`
+	Network   string         `json:"network"`
+	Protocol  string         `json:"protocol"`
+	Health    checker.Health `json:"health"`
+	Checked   bool           `json:"checked"`          // False if the chain has no reference and was skipped
+	Reason    string         `json:"reason,omitempty"` // Why the chain is unhealthy
+	Methods   []string       `json:"methods"`
+	Providers []Provider     `json:"providers"`
+}
+
+// Provider is the summary of a single provider
+type Provider struct {
+	Name             string          `json:"name"`
+	URL              string          `json:"url"` // Request URL with credentials redacted
+	Status           string          `json:"status"`
+	FailedMethods    []MethodFailure `json:"failedMethods,omitempty"`
+	ThrottledMethods []string        `json:"throttledMethods,omitempty"`
+	BatchSupported   *bool           `json:"batchSupported,omitempty"` // Whether the provider answers JSON-RPC batches, if it was sent one
+}
+
+// MethodFailure describes why a provider failed a method
+type MethodFailure struct {
+	Method            string `json:"method"`
+	ErrorClass        string `json:"errorClass,omitempty"`
+	Error             string `json:"error,omitempty"`
+	Response          string `json:"response,omitempty"`          // Raw response of the provider, truncated
+	ReferenceResponse string `json:"referenceResponse,omitempty"` // Raw response of the reference provider, truncated
+}
+
+// maxResponseLength limits the size of the responses included in a report
+const maxResponseLength = 2048
+
+// Build converts the result of a validation run into a Report
+func Build(cycle checker.CycleResult) Report {
+	report := Report{
+		StartedAt:  cycle.StartedAt,
+		DurationMs: cycle.Duration.Milliseconds(),
+		Health:     cycle.Health(),
+		Chains:     make([]Chain, 0, len(cycle.Chains)),
+	}
+
+	for _, chainResult := range cycle.Chains {
+		chain := Chain{
+			ChainID:   chainResult.Chain.ChainId,
+			Name:      chainResult.Chain.Name,
+			Network:   chainResult.Chain.Network,
+			Protocol:  string(chainResult.Chain.Protocol.OrDefault()),
+			Health:    chainResult.Health(),
+			Checked:   chainResult.Checked(),
+			Methods:   chainResult.Methods,
+			Providers: make([]Provider, 0, len(chainResult.Chain.Providers)),
+		}
+		switch {
+		case !chainResult.Checked():
+			chain.Reason = "no reference provider configured"
+		case len(chainResult.ValidProviders) == 0:
+			chain.Reason = "no valid providers"
+		}
+
+		for _, provider := range chainResult.Chain.Providers {
+			summary := Provider{Name: provider.Name, URL: provider.RedactedURL()}
+			result, validated := chainResult.Providers[provider.Name]
+			switch {
+			case !provider.IsEnabled():
+				summary.Status = StatusDisabled
+			case validated && result.Valid:
+				summary.Status = StatusValid
+			default:
+				summary.Status = StatusInvalid
+			}
+			if validated {
+				summary.FailedMethods = methodFailures(result)
+				summary.ThrottledMethods = result.ThrottledMethods
+				summary.BatchSupported = result.BatchSupported
+			}
+			chain.Providers = append(chain.Providers, summary)
+		}
+		report.Chains = append(report.Chains, chain)
+	}
+	return report
+}
+
+// methodFailures returns the failed methods of a provider ordered by method name
+func methodFailures(result checker.ProviderValidationResult) []MethodFailure {
+	var failures []MethodFailure
+	for method, failed := range result.FailedMethods {
+		failure := MethodFailure{
+			Method:            method,
+			Response:          truncateResponse(failed.Result.Response),
+			ReferenceResponse: truncateResponse(failed.ReferenceResult.Response),
+		}
+		if failed.Error != nil {
+			failure.ErrorClass = string(failed.ErrorClass())
+			failure.Error = failed.Error.Error()
+		}
+		failures = append(failures, failure)
+	}
+	sort.Slice(failures, func(i, j int) bool {
+		return failures[i].Method < failures[j].Method
+	})
+	return failures
+}
+
+// truncateResponse converts a raw response to text, cutting it at maxResponseLength bytes
+func truncateResponse(response []byte) string {
+	text := strings.TrimSpace(string(response))
+	if len(text) > maxResponseLength {
+		return fmt.Sprintf("%s... (%d bytes truncated)", text[:maxResponseLength], len(text)-maxResponseLength)
+	}
+	return text
+}
+
+// Write writes the report in the given format
+func Write(w io.Writer, format Format, report Report) error {
+	switch format {
+	case FormatJSON:
+		return WriteJSON(w, report)
+	case FormatJUnit:
+		return WriteJUnit(w, report)
+	case FormatMarkdown:
+		return WriteMarkdown(w, report)
+	case FormatHTML:
+		return WriteHTML(w, report)
+	default:
+		return WriteTable(w, report)
+	}
+}
+
+// WriteJSON writes the report as indented JSON
+func WriteJSON(w io.Writer, report Report) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(report); err != nil {
+		return fmt.Errorf("failed to write JSON report: %w", err)
+	}
+	return nil
+}
+
+// WriteTable writes the report as a table with one row per provider
+func WriteTable(w io.Writer, report Report) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "CHAIN\tNETWORK\tCHAIN ID\tPROVIDER\tSTATUS\tDETAILS")
+	for _, chain := range report.Chains {
+		if len(chain.Providers) == 0 || !chain.Checked {
+			fmt.Fprintf(tw, "%s\t%s\t%d\t-\t%s\t%s\n", chain.Name, chain.Network, chain.ChainID, chain.Health, chain.Reason)
+			continue
+		}
+		for _, provider := range chain.Providers {
+			fmt.Fprintf(tw, "%s\t%s\t%d\t%s\t%s\t%s\n",
+				chain.Name, chain.Network, chain.ChainID, provider.Name, provider.Status, providerDetails(provider))
+		}
+	}
+	fmt.Fprintf(tw, "\nOverall: %s (%d chains, %s)\n", report.Health, len(report.Chains), time.Duration(report.DurationMs)*time.Millisecond)
+	if err := tw.Flush(); err != nil {
+		return fmt.Errorf("failed to write table report: %w", err)
+	}
+	return nil
+}
+
+// Outcomes of a single method of a provider
+const (
+	methodPassed    = "passed"
+	methodFailed    = "failed"
+	methodThrottled = "throttled"
+	methodDisabled  = "disabled"
+)
+
+// methodResult is the outcome of a single method of a provider
+type methodResult struct {
+	Method  string
+	Outcome string
+	Failure *MethodFailure // Set if the method failed
+}
+
+// methodResults returns the outcome of every checked method of a provider, followed by
+// failures of methods not listed in the chain, such as newHeads subscriptions
+func methodResults(methods []string, provider Provider) []methodResult {
+	failures := make(map[string]*MethodFailure, len(provider.FailedMethods))
+	for i := range provider.FailedMethods {
+		failures[provider.FailedMethods[i].Method] = &provider.FailedMethods[i]
+	}
+	throttled := make(map[string]bool, len(provider.ThrottledMethods))
+	for _, method := range provider.ThrottledMethods {
+		throttled[method] = true
+	}
+
+	results := make([]methodResult, 0, len(methods))
+	listed := make(map[string]bool, len(methods))
+	for _, method := range methods {
+		listed[method] = true
+		result := methodResult{Method: method, Outcome: methodPassed}
+		switch {
+		case provider.Status == StatusDisabled:
+			result.Outcome = methodDisabled
+		case failures[method] != nil:
+			result.Outcome = methodFailed
+			result.Failure = failures[method]
+		case throttled[method]:
+			result.Outcome = methodThrottled
+		}
+		results = append(results, result)
+	}
+	for i := range provider.FailedMethods {
+		if failure := &provider.FailedMethods[i]; !listed[failure.Method] {
+			results = append(results, methodResult{Method: failure.Method, Outcome: methodFailed, Failure: failure})
+		}
+	}
+	return results
+}
+
+// providerDetails summarises failed and throttled methods and missing batch support in a single line
+func providerDetails(provider Provider) string {
+	var details []string
+	for _, failure := range provider.FailedMethods {
+		detail := failure.Method
+		if failure.ErrorClass != "" {
+			detail += " (" + failure.ErrorClass + ")"
+		}
+		details = append(details, detail)
+	}
+	if len(provider.ThrottledMethods) > 0 {
+		details = append(details, "throttled: "+strings.Join(provider.ThrottledMethods, ", "))
+	}
+	if provider.BatchSupported != nil && !*provider.BatchSupported {
+		details = append(details, "batch not supported")
+	}
+	return strings.Join(details, "; ")
+}
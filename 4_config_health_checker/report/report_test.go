@@ -0,0 +1,189 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"encoding/xml"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/friofry/config-health-checker/chainconfig"
+	"github.com/friofry/config-health-checker/checker"
+	requestsrunner "github.com/friofry/config-health-checker/requests-runner"
+	"github.com/friofry/config-health-checker/rpcprovider"
+)
+
+func testCycle() checker.CycleResult {
+	disabled := false
+	batchSupported := false
+	primary := rpcprovider.RpcProvider{Name: "primary", URL: "https://rpc.example.io/{token}", AuthType: rpcprovider.TokenAuth, AuthToken: "secret"}
+	return checker.CycleResult{
+		StartedAt: time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC),
+		Duration:  1500 * time.Millisecond,
+		Chains: []checker.ChainResult{
+			{
+				Chain: chainconfig.ChainConfig{
+					Name: "ethereum", Network: "mainnet", ChainId: 1,
+					Providers: []rpcprovider.RpcProvider{
+						primary,
+						{Name: "lagging", URL: "https://lagging.example.io"},
+						{Name: "off", URL: "https://off.example.io", Enabled: &disabled},
+					},
+				},
+				Reference: &chainconfig.ReferenceChainConfig{ChainId: 1},
+				Methods:   []string{"eth_blockNumber", "eth_chainId"},
+				Providers: map[string]checker.ProviderValidationResult{
+					"primary": {Valid: true, ThrottledMethods: []string{"eth_chainId"}, BatchSupported: &batchSupported},
+					"lagging": {FailedMethods: map[string]checker.FailedMethodResult{
+						"eth_blockNumber": {
+							Result:          requestsrunner.ProviderResult{Response: []byte(`{"jsonrpc":"2.0","id":1,"result":"0x63"}`)},
+							ReferenceResult: requestsrunner.ProviderResult{Response: []byte(`{"jsonrpc":"2.0","id":1,"result":"0x64"}`)},
+							Error: &requestsrunner.ProviderError{
+								Class: requestsrunner.ErrorClassResultMismatch,
+								Err:   errors.New("result 99 differs from reference result 100"),
+							},
+						},
+					}},
+				},
+				ValidProviders: []rpcprovider.RpcProvider{primary},
+			},
+			{
+				Chain: chainconfig.ChainConfig{
+					Name: "optimism", Network: "mainnet", ChainId: 10,
+					Providers: []rpcprovider.RpcProvider{{Name: "public", URL: "https://optimism.example.io"}},
+				},
+			},
+		},
+	}
+}
+
+func TestBuild(t *testing.T) {
+	report := Build(testCycle())
+
+	assert.Equal(t, checker.HealthUnhealthy, report.Health)
+	assert.EqualValues(t, 1500, report.DurationMs)
+	require.Len(t, report.Chains, 2)
+
+	ethereum := report.Chains[0]
+	assert.Equal(t, checker.HealthDegraded, ethereum.Health)
+	assert.Equal(t, "evm", ethereum.Protocol)
+	require.Len(t, ethereum.Providers, 3)
+	assert.Equal(t, StatusValid, ethereum.Providers[0].Status)
+	assert.Equal(t, "https://rpc.example.io/[REDACTED]", ethereum.Providers[0].URL)
+	require.NotNil(t, ethereum.Providers[0].BatchSupported)
+	assert.False(t, *ethereum.Providers[0].BatchSupported)
+	assert.Nil(t, ethereum.Providers[1].BatchSupported)
+	assert.Equal(t, StatusInvalid, ethereum.Providers[1].Status)
+	assert.Equal(t, []MethodFailure{{
+		Method:            "eth_blockNumber",
+		ErrorClass:        "result_mismatch",
+		Error:             "result 99 differs from reference result 100",
+		Response:          `{"jsonrpc":"2.0","id":1,"result":"0x63"}`,
+		ReferenceResponse: `{"jsonrpc":"2.0","id":1,"result":"0x64"}`,
+	}}, ethereum.Providers[1].FailedMethods)
+	assert.Equal(t, StatusDisabled, ethereum.Providers[2].Status)
+
+	optimism := report.Chains[1]
+	assert.False(t, optimism.Checked)
+	assert.Equal(t, checker.HealthUnhealthy, optimism.Health)
+	assert.Equal(t, "no reference provider configured", optimism.Reason)
+}
+
+func TestWriteFormats(t *testing.T) {
+	report := Build(testCycle())
+
+	t.Run("JSON", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, Write(&buf, FormatJSON, report))
+		var decoded Report
+		require.NoError(t, json.Unmarshal(buf.Bytes(), &decoded))
+		assert.Equal(t, report.Chains[0].Providers, decoded.Chains[0].Providers)
+		assert.NotContains(t, buf.String(), "secret")
+	})
+
+	t.Run("JUnit", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, Write(&buf, FormatJUnit, report))
+
+		var suites junitTestSuites
+		require.NoError(t, xml.Unmarshal(buf.Bytes(), &suites))
+		assert.Equal(t, 7, suites.Tests)
+		assert.Equal(t, 2, suites.Failures)
+		assert.Equal(t, 3, suites.Skipped)
+		require.Len(t, suites.Suites, 2)
+		assert.Equal(t, "ethereum/mainnet (1)", suites.Suites[0].Name)
+
+		cases := suites.Suites[0].Cases
+		require.Len(t, cases, 6)
+		assert.Equal(t, "primary/eth_blockNumber", cases[0].Name)
+		assert.Nil(t, cases[0].Failure)
+		assert.Equal(t, "primary/eth_chainId", cases[1].Name)
+		assert.NotNil(t, cases[1].Skipped, "throttled methods are skipped")
+		assert.Equal(t, "lagging/eth_blockNumber", cases[2].Name)
+		require.NotNil(t, cases[2].Failure)
+		assert.Equal(t, "result_mismatch", cases[2].Failure.Type)
+		assert.Equal(t, "result 99 differs from reference result 100", cases[2].Failure.Message)
+		assert.Contains(t, cases[2].Failure.Text, `provider response: {"jsonrpc":"2.0","id":1,"result":"0x63"}`)
+		assert.Contains(t, cases[2].Failure.Text, `reference response: {"jsonrpc":"2.0","id":1,"result":"0x64"}`)
+		assert.Nil(t, cases[3].Failure)
+		assert.NotNil(t, cases[4].Skipped, "disabled providers are skipped")
+
+		assert.Equal(t, "no reference provider configured", suites.Suites[1].Cases[0].Failure.Message)
+	})
+
+	t.Run("Table", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, Write(&buf, FormatTable, report))
+		assert.Contains(t, buf.String(), "eth_blockNumber (result_mismatch)")
+		assert.Contains(t, buf.String(), "throttled: eth_chainId; batch not supported")
+		assert.Contains(t, buf.String(), "Overall: unhealthy (2 chains, 1.5s)")
+	})
+
+	t.Run("Markdown", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, Write(&buf, FormatMarkdown, report))
+		output := buf.String()
+		assert.Contains(t, output, "**Overall: unhealthy** · 2 chains · 1.5s")
+		assert.Contains(t, output, "## ethereum/mainnet (1): degraded")
+		assert.Contains(t, output, "| lagging | https://lagging.example.io | invalid | eth_blockNumber (result_mismatch) |")
+		assert.Contains(t, output, "<summary>lagging: eth_blockNumber (result_mismatch)</summary>")
+		assert.Contains(t, output, "Reference response:\n\n```\n{\"jsonrpc\":\"2.0\",\"id\":1,\"result\":\"0x64\"}\n```")
+		assert.True(t, strings.HasSuffix(output, "## optimism/mainnet (10): unhealthy\n\nno reference provider configured\n\n"))
+		assert.NotContains(t, output, "secret")
+	})
+
+	t.Run("HTML", func(t *testing.T) {
+		var buf bytes.Buffer
+		require.NoError(t, Write(&buf, FormatHTML, report))
+		output := buf.String()
+		assert.Contains(t, output, `<td class="invalid">invalid</td>`)
+		assert.Contains(t, output, "<summary>lagging: eth_blockNumber (result_mismatch)</summary>")
+		assert.NotContains(t, output, "optimism.example.io", "providers of unchecked chains are not listed")
+		assert.Contains(t, output, "<pre>{&#34;jsonrpc&#34;:&#34;2.0&#34;,&#34;id&#34;:1,&#34;result&#34;:&#34;0x63&#34;}</pre>")
+		assert.NotContains(t, output, "secret")
+	})
+}
+
+func TestTruncateResponse(t *testing.T) {
+	response := bytes.Repeat([]byte("a"), maxResponseLength+10)
+	assert.Equal(t, strings.Repeat("a", maxResponseLength)+"... (10 bytes truncated)", truncateResponse(response))
+	assert.Equal(t, "{}", truncateResponse([]byte(" {}\n")))
+}
+
+func TestParseFormat(t *testing.T) {
+	format, err := ParseFormat("")
+	require.NoError(t, err)
+	assert.Equal(t, FormatTable, format)
+
+	format, err = ParseFormat("markdown")
+	require.NoError(t, err)
+	assert.Equal(t, FormatMarkdown, format)
+
+	_, err = ParseFormat("xml")
+	assert.Error(t, err)
+}
@@ -0,0 +1,267 @@
+package requestsrunner
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	rpcprovider "github.com/friofry/config-health-checker/rpcprovider"
+)
+
+// ErrBatchNotSupported is returned when a provider answers a batch request with a single response object
+var ErrBatchNotSupported = errors.New("provider does not support JSON-RPC batch requests")
+
+// MethodCall describes a single method invocation inside a JSON-RPC batch
+type MethodCall struct {
+	Method string
+	Params []interface{}
+}
+
+// BatchResult contains the outcome of a JSON-RPC batch request to one provider
+type BatchResult struct {
+	Supported   bool             // Indicates if the provider answered with a batch (array) response
+	Results     []ProviderResult // Per-call results, in the same order as the submitted calls
+	Error       error            // Error if the batch request as a whole failed
+	Response    []byte           // Raw response body from the provider
+	ElapsedTime time.Duration    // Duration taken to perform the whole batch
+	Attempts    []Attempt        // Every attempt made for the batch, including retries
+}
+
+// Rejected reports whether the provider refused the batch itself rather than failing as a whole,
+// e.g. by answering with a single object or an error status. The calls can still be sent one by one.
+func (b BatchResult) Rejected() bool {
+	if errors.Is(b.Error, ErrBatchNotSupported) {
+		return true
+	}
+	switch ClassifyError(b.Error) {
+	case ErrorClassHTTPStatus, ErrorClassRPCError, ErrorClassMalformedJSON:
+		return true
+	default:
+		return false
+	}
+}
+
+// batchSupport remembers whether providers answer batch requests, keyed by ProviderKey
+type batchSupport struct {
+	mu        sync.Mutex
+	supported map[string]bool
+}
+
+func newBatchSupport() *batchSupport {
+	return &batchSupport{
+		supported: make(map[string]bool),
+	}
+}
+
+// record registers the outcome of a batch request to the provider. Failures of the provider
+// as a whole, such as timeouts, say nothing about batch support and are ignored.
+func (b *batchSupport) record(provider string, result BatchResult) {
+	if result.Error != nil && !result.Rejected() {
+		return
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.supported[provider] = result.Error == nil
+}
+
+// snapshot returns a copy of the recorded batch support
+func (b *batchSupport) snapshot() map[string]bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	snapshot := make(map[string]bool, len(b.supported))
+	for name, supported := range b.supported {
+		snapshot[name] = supported
+	}
+	return snapshot
+}
+
+// BatchSupport returns whether the providers sent a batch so far answer batch requests, keyed by ProviderKey
+func (r *RequestsRunner) BatchSupport() map[string]bool {
+	return r.batches.snapshot()
+}
+
+// CallEVMMethodBatch sends all calls to the provider as one JSON-RPC batch request.
+// Responses are matched back to calls by id, so out-of-order replies are handled and
+// calls missing from the reply are reported individually.
+// Implements the BatchEVMMethodCaller interface
+func (r *RequestsRunner) CallEVMMethodBatch(
+	ctx context.Context,
+	provider rpcprovider.RpcProvider,
+	calls []MethodCall,
+	timeout time.Duration,
+) BatchResult {
+	startTime := time.Now()
+
+	if len(calls) == 0 {
+		return BatchResult{
+			Error:       errors.New("empty batch"),
+			ElapsedTime: time.Since(startTime),
+		}
+	}
+
+	// Ids start from 1 so that a zero id in a response is never mistaken for a valid match
+	requests := make([]jsonRPCRequest, len(calls))
+	for i, call := range calls {
+		params := call.Params
+		if params == nil {
+			params = []interface{}{}
+		}
+		requests[i] = jsonRPCRequest{
+			JSONRPC: r.protocol.JSONRPCVersion(),
+			Method:  call.Method,
+			Params:  params,
+			ID:      i + 1,
+		}
+	}
+
+	jsonBody, err := json.Marshal(requests)
+	if err != nil {
+		return BatchResult{
+			Error:       fmt.Errorf("failed to marshal batch request body: %w", err),
+			ElapsedTime: time.Since(startTime),
+		}
+	}
+
+	ctx, cancel := r.retryPolicy.withDeadline(ctx, timeout)
+	defer cancel()
+
+	if err := r.guard(ctx, provider, timeout); err != nil {
+		return BatchResult{
+			Error:       err,
+			ElapsedTime: time.Since(startTime),
+		}
+	}
+
+	var body []byte
+	attempts, err := r.withRetry(ctx, provider, timeout, func(ctx context.Context) error {
+		var err error
+		body, err = r.send(ctx, provider, jsonBody)
+		return err
+	})
+	r.recordOutcome(provider, err)
+	if err != nil {
+		result := BatchResult{
+			Error:       err,
+			Response:    body,
+			ElapsedTime: time.Since(startTime),
+			Attempts:    attempts,
+		}
+		r.batches.record(ProviderKey(provider), result)
+		return result
+	}
+
+	result := parseBatchResponse(body, len(calls))
+	result.ElapsedTime = time.Since(startTime)
+	result.Attempts = attempts
+	for i := range result.Results {
+		result.Results[i].ElapsedTime = result.ElapsedTime
+		result.Results[i].Attempts = attempts
+	}
+	r.batches.record(ProviderKey(provider), result)
+	return result
+}
+
+// parseBatchResponse splits a JSON-RPC batch response into per-call results.
+// A single response object instead of an array means the provider does not support batching.
+func parseBatchResponse(body []byte, callsCount int) BatchResult {
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) == 0 || trimmed[0] != '[' {
+		err := ErrBatchNotSupported
+		if single := parseResponse(trimmed); single.Error != nil && len(trimmed) > 0 {
+			err = fmt.Errorf("%w: %v", ErrBatchNotSupported, single.Error)
+		}
+		return BatchResult{
+			Supported: false,
+			Error:     err,
+			Response:  body,
+		}
+	}
+
+	var items []json.RawMessage
+	if err := json.Unmarshal(trimmed, &items); err != nil {
+		return BatchResult{
+			Supported: true,
+			Error: &ProviderError{
+				Class: ErrorClassMalformedJSON,
+				Err:   fmt.Errorf("failed to parse JSON batch response: %w", err),
+			},
+			Response: body,
+		}
+	}
+
+	// Index responses by id
+	byID := make(map[int]json.RawMessage, len(items))
+	for _, item := range items {
+		var envelope struct {
+			ID *int `json:"id"`
+		}
+		if err := json.Unmarshal(item, &envelope); err != nil || envelope.ID == nil {
+			// Responses without a usable id cannot be matched to a call
+			continue
+		}
+		if *envelope.ID < 1 || *envelope.ID > callsCount {
+			continue
+		}
+		byID[*envelope.ID] = item
+	}
+
+	results := make([]ProviderResult, callsCount)
+	for i := range results {
+		item, ok := byID[i+1]
+		if !ok {
+			results[i] = ProviderResult{
+				Success: false,
+				Error: &ProviderError{
+					Class: ErrorClassMalformedJSON,
+					Err:   fmt.Errorf("missing response for batch id %d", i+1),
+				},
+			}
+			continue
+		}
+		results[i] = parseResponse(item)
+	}
+
+	return BatchResult{
+		Supported: true,
+		Results:   results,
+		Response:  body,
+	}
+}
+
+// ParallelCallEVMMethodsBatch sends the calls as one batch to each provider in parallel
+func ParallelCallEVMMethodsBatch(
+	ctx context.Context,
+	providers []rpcprovider.RpcProvider,
+	calls []MethodCall,
+	timeout time.Duration,
+	caller BatchEVMMethodCaller,
+) map[string]BatchResult {
+	// timeout bounds every attempt, the caller's deadline covers retries and backoffs
+	ctx, cancel := context.WithTimeout(ctx, callDeadline(caller, timeout))
+	defer cancel()
+
+	var (
+		mu      sync.Mutex
+		wg      sync.WaitGroup
+		results = make(map[string]BatchResult, len(providers))
+	)
+
+	for _, provider := range providers {
+		wg.Add(1)
+		go func(p rpcprovider.RpcProvider) {
+			defer wg.Done()
+			result := caller.CallEVMMethodBatch(ctx, p, calls, timeout)
+			mu.Lock()
+			results[p.Name] = result
+			mu.Unlock()
+		}(provider)
+	}
+
+	wg.Wait()
+	return results
+}
@@ -0,0 +1,183 @@
+package requestsrunner_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	requestsrunner "github.com/friofry/config-health-checker/requests-runner"
+	"github.com/friofry/config-health-checker/rpcprovider"
+)
+
+func TestCallEVMMethodBatch(t *testing.T) {
+	calls := []requestsrunner.MethodCall{
+		{Method: "eth_blockNumber"},
+		{Method: "eth_getBalance", Params: []interface{}{"0x123", "latest"}},
+		{Method: "eth_chainId"},
+	}
+
+	tests := []struct {
+		name          string
+		handler       func(http.ResponseWriter, *http.Request)
+		wantSupported bool
+		wantError     string
+		wantSuccess   []bool
+		wantResults   []string
+	}{
+		{
+			name: "Out of order responses",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				var req []map[string]interface{}
+				assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+				assert.Len(t, req, 3)
+				assert.Equal(t, "eth_blockNumber", req[0]["method"])
+				assert.Equal(t, float64(1), req[0]["id"])
+				assert.Equal(t, []interface{}{}, req[0]["params"])
+				assert.Equal(t, "eth_getBalance", req[1]["method"])
+				assert.Equal(t, float64(2), req[1]["id"])
+
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte(`[
+					{"jsonrpc":"2.0","id":3,"result":"0x1"},
+					{"jsonrpc":"2.0","id":1,"result":"0x10"},
+					{"jsonrpc":"2.0","id":2,"result":"0x100"}
+				]`))
+			},
+			wantSupported: true,
+			wantSuccess:   []bool{true, true, true},
+			wantResults:   []string{"0x10", "0x100", "0x1"},
+		},
+		{
+			name: "Partial batch errors",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte(`[
+					{"jsonrpc":"2.0","id":2,"error":{"code":-32000,"message":"header not found"}},
+					{"jsonrpc":"2.0","id":1,"result":"0x10"}
+				]`))
+			},
+			wantSupported: true,
+			wantSuccess:   []bool{true, false, false},
+			wantResults:   []string{"0x10", "", ""},
+		},
+		{
+			name: "Batch not supported",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte(`{"jsonrpc":"2.0","id":null,"error":{"code":-32600,"message":"batch requests are not supported"}}`))
+			},
+			wantSupported: false,
+			wantError:     "batch requests are not supported",
+		},
+		{
+			name: "Server error response",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusInternalServerError)
+			},
+			wantSupported: false,
+			wantError:     "500",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(tt.handler))
+			defer server.Close()
+
+			provider := rpcprovider.RpcProvider{
+				Name:     "test",
+				URL:      server.URL,
+				AuthType: rpcprovider.NoAuth,
+			}
+
+			runner := requestsrunner.NewRequestsRunner()
+			result := runner.CallEVMMethodBatch(context.Background(), provider, calls, 1*time.Second)
+
+			assert.Equal(t, tt.wantSupported, result.Supported)
+			if tt.wantError != "" {
+				require.Error(t, result.Error)
+				assert.Contains(t, result.Error.Error(), tt.wantError)
+				return
+			}
+
+			require.NoError(t, result.Error)
+			require.Len(t, result.Results, len(calls))
+			for i, res := range result.Results {
+				assert.Equal(t, tt.wantSuccess[i], res.Success, "call %d success mismatch", i)
+				assert.Equal(t, tt.wantResults[i], res.Result, "call %d result mismatch", i)
+				if !res.Success {
+					assert.Error(t, res.Error, "call %d should have an error", i)
+				}
+			}
+		})
+	}
+}
+
+func TestCallEVMMethodBatchNotSupportedError(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x1"}`))
+	}))
+	defer server.Close()
+
+	provider := rpcprovider.RpcProvider{Name: "test", URL: server.URL, AuthType: rpcprovider.NoAuth}
+	result := requestsrunner.NewRequestsRunner().CallEVMMethodBatch(
+		context.Background(),
+		provider,
+		[]requestsrunner.MethodCall{{Method: "eth_blockNumber"}},
+		1*time.Second,
+	)
+
+	assert.False(t, result.Supported)
+	assert.True(t, errors.Is(result.Error, requestsrunner.ErrBatchNotSupported))
+}
+
+func TestParallelCallEVMMethodsBatch(t *testing.T) {
+	batchServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`[{"jsonrpc":"2.0","id":1,"result":"0x1"},{"jsonrpc":"2.0","id":2,"result":"0x2"}]`))
+	}))
+	defer batchServer.Close()
+
+	singleServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x1"}`))
+	}))
+	defer singleServer.Close()
+
+	providers := []rpcprovider.RpcProvider{
+		{Name: "Batching", URL: batchServer.URL, AuthType: rpcprovider.NoAuth},
+		{Name: "NonBatching", URL: singleServer.URL, AuthType: rpcprovider.NoAuth},
+	}
+	calls := []requestsrunner.MethodCall{{Method: "eth_blockNumber"}, {Method: "eth_chainId"}}
+
+	runner := requestsrunner.NewRequestsRunner()
+	results := requestsrunner.ParallelCallEVMMethodsBatch(
+		context.Background(),
+		providers,
+		calls,
+		1*time.Second,
+		runner,
+	)
+
+	require.Len(t, results, 2)
+	assert.True(t, results["Batching"].Supported)
+	require.Len(t, results["Batching"].Results, 2)
+	assert.Equal(t, "0x1", results["Batching"].Results[0].Result)
+	assert.Equal(t, "0x2", results["Batching"].Results[1].Result)
+	assert.False(t, results["NonBatching"].Supported)
+	assert.Error(t, results["NonBatching"].Error)
+	assert.True(t, results["NonBatching"].Rejected())
+
+	assert.Equal(t, map[string]bool{
+		requestsrunner.ProviderKey(providers[0]): true,
+		requestsrunner.ProviderKey(providers[1]): false,
+	}, runner.BatchSupport())
+}
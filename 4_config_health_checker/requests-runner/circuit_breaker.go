@@ -0,0 +1,261 @@
+package requestsrunner
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	rpcprovider "github.com/friofry/config-health-checker/rpcprovider"
+)
+
+const (
+	defaultFailureThreshold = 3
+	defaultOpenDuration     = 10 * time.Second
+)
+
+// CircuitState defines the state of a provider circuit breaker
+type CircuitState string
+
+const (
+	CircuitClosed   CircuitState = "closed"    // Requests are sent normally
+	CircuitOpen     CircuitState = "open"      // Requests fail fast, only a probe is sent after the open duration
+	CircuitHalfOpen CircuitState = "half-open" // A probe is in flight
+)
+
+// CircuitBreakerConfig configures the per-provider circuit breakers of RequestsRunner
+type CircuitBreakerConfig struct {
+	Disabled         bool          // Disables circuit breaking
+	FailureThreshold int           // Consecutive transport failures that open the circuit
+	OpenDuration     time.Duration // Time an open circuit fails fast before a probe is allowed
+	ProbeMethod      string        // Cheap method sent to probe an open circuit, chainprotocol.Protocol.ProbeMethod if empty
+}
+
+// CircuitSnapshot contains the observable state of a provider circuit breaker
+type CircuitSnapshot struct {
+	State               CircuitState `json:"state"`
+	ConsecutiveFailures int          `json:"consecutiveFailures"`
+	LastFailureClass    ErrorClass   `json:"lastFailureClass,omitempty"`
+	OpenedAt            time.Time    `json:"openedAt,omitempty"`
+}
+
+// DefaultCircuitBreakerConfig returns the circuit breaker configuration used by NewRequestsRunner
+func DefaultCircuitBreakerConfig() CircuitBreakerConfig {
+	return CircuitBreakerConfig{
+		FailureThreshold: defaultFailureThreshold,
+		OpenDuration:     defaultOpenDuration,
+	}
+}
+
+// tripsCircuit reports whether errors of the class mean the provider is unreachable
+func tripsCircuit(class ErrorClass) bool {
+	switch class {
+	case ErrorClassTimeout, ErrorClassDNS, ErrorClassTLS, ErrorClassConnectionRefused,
+		ErrorClassNetwork, ErrorClassServerError:
+		return true
+	default:
+		return false
+	}
+}
+
+// ProviderKey identifies a provider in circuit and throttle states. Provider names repeat across chains,
+// so the key also contains the request URL, with credentials redacted.
+func ProviderKey(provider rpcprovider.RpcProvider) string {
+	return provider.Name + " " + provider.RedactedURL()
+}
+
+// circuitBreakers keeps a circuit per provider, keyed by ProviderKey
+type circuitBreakers struct {
+	config   CircuitBreakerConfig
+	mu       sync.Mutex
+	circuits map[string]*CircuitSnapshot
+}
+
+func newCircuitBreakers(config CircuitBreakerConfig) *circuitBreakers {
+	return &circuitBreakers{
+		config:   config,
+		circuits: make(map[string]*CircuitSnapshot),
+	}
+}
+
+// circuit returns the circuit of the provider, creating a closed one if needed. Must be called with mu held.
+func (b *circuitBreakers) circuit(provider string) *CircuitSnapshot {
+	c, ok := b.circuits[provider]
+	if !ok {
+		c = &CircuitSnapshot{State: CircuitClosed}
+		b.circuits[provider] = c
+	}
+	return c
+}
+
+// acquire decides whether a request to the provider may be sent.
+// It returns probe=true when the caller must probe the provider first.
+func (b *circuitBreakers) acquire(provider string) (allowed bool, probe bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c := b.circuit(provider)
+	switch c.State {
+	case CircuitOpen:
+		if time.Since(c.OpenedAt) < b.config.OpenDuration {
+			return false, false
+		}
+		c.State = CircuitHalfOpen
+		return true, true
+	case CircuitHalfOpen:
+		// Another request is probing the provider
+		return false, false
+	default:
+		return true, false
+	}
+}
+
+// record updates the circuit of the provider with the class of a request outcome
+func (b *circuitBreakers) record(provider string, class ErrorClass) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	c := b.circuit(provider)
+	if class == ErrorClassCanceled {
+		// A canceled request says nothing about the provider; an interrupted probe is retried later
+		if c.State == CircuitHalfOpen {
+			c.State = CircuitOpen
+		}
+		return
+	}
+	if !tripsCircuit(class) {
+		c.State = CircuitClosed
+		c.ConsecutiveFailures = 0
+		c.OpenedAt = time.Time{}
+		return
+	}
+
+	c.ConsecutiveFailures++
+	c.LastFailureClass = class
+	if c.State == CircuitHalfOpen || c.ConsecutiveFailures >= b.config.FailureThreshold {
+		c.State = CircuitOpen
+		c.OpenedAt = time.Now()
+	}
+}
+
+// snapshot returns a copy of all circuits
+func (b *circuitBreakers) snapshot() map[string]CircuitSnapshot {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	snapshot := make(map[string]CircuitSnapshot, len(b.circuits))
+	for name, c := range b.circuits {
+		snapshot[name] = *c
+	}
+	return snapshot
+}
+
+// restore replaces the circuits with previously saved ones.
+// A circuit saved while probing is restored as open so that it is probed again.
+func (b *circuitBreakers) restore(circuits map[string]CircuitSnapshot) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.circuits = make(map[string]*CircuitSnapshot, len(circuits))
+	for name, c := range circuits {
+		c := c
+		if c.State == CircuitHalfOpen {
+			c.State = CircuitOpen
+		}
+		b.circuits[name] = &c
+	}
+}
+
+// guard checks the circuit of the provider before a request.
+// An open circuit fails fast; once the open duration has passed, a cheap probe decides whether
+// the provider may be used again, bounded by timeout. A nil error means the request may be sent.
+func (r *RequestsRunner) guard(ctx context.Context, provider rpcprovider.RpcProvider, timeout time.Duration) error {
+	if r.breakers.config.Disabled {
+		return nil
+	}
+
+	allowed, probe := r.breakers.acquire(ProviderKey(provider))
+	if !allowed {
+		return &ProviderError{
+			Class: ErrorClassCircuitOpen,
+			Err:   fmt.Errorf("circuit open for provider %s", provider.Name),
+		}
+	}
+	if !probe {
+		return nil
+	}
+
+	probeBody, err := json.Marshal(jsonRPCRequest{
+		JSONRPC: r.protocol.JSONRPCVersion(),
+		Method:  r.probeMethod(),
+		Params:  []interface{}{},
+		ID:      1,
+	})
+	if err != nil {
+		r.breakers.record(ProviderKey(provider), ErrorClassUnknown)
+		return fmt.Errorf("failed to marshal probe request: %w", err)
+	}
+
+	// Any answer that is not a transport failure means the provider is reachable again
+	probeCtx, cancel := withAttemptTimeout(ctx, timeout)
+	defer cancel()
+	_, err = r.send(probeCtx, provider, probeBody)
+	class := ClassifyError(err)
+	r.breakers.record(ProviderKey(provider), class)
+	if tripsCircuit(class) {
+		return &ProviderError{
+			Class: ErrorClassCircuitOpen,
+			Err:   fmt.Errorf("circuit open for provider %s: probe failed: %w", provider.Name, err),
+		}
+	}
+	return nil
+}
+
+// probeMethod returns the configured probe method, or the one of the protocol the runner speaks
+func (r *RequestsRunner) probeMethod() string {
+	if r.breakers.config.ProbeMethod != "" {
+		return r.breakers.config.ProbeMethod
+	}
+	return r.protocol.ProbeMethod()
+}
+
+// recordOutcome updates the circuit of the provider with the outcome of a request
+func (r *RequestsRunner) recordOutcome(provider rpcprovider.RpcProvider, err error) {
+	if r.breakers.config.Disabled {
+		return
+	}
+	r.breakers.record(ProviderKey(provider), ClassifyError(err))
+}
+
+// CircuitStates returns the circuit breaker state of every provider seen so far, keyed by ProviderKey
+func (r *RequestsRunner) CircuitStates() map[string]CircuitSnapshot {
+	return r.breakers.snapshot()
+}
+
+// SaveCircuitStates writes the circuit breaker states to a JSON file
+func (r *RequestsRunner) SaveCircuitStates(path string) error {
+	data, err := json.MarshalIndent(r.breakers.snapshot(), "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal circuit states: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write circuit states file: %w", err)
+	}
+	return nil
+}
+
+// LoadCircuitStates restores circuit breaker states from a JSON file written by SaveCircuitStates
+func (r *RequestsRunner) LoadCircuitStates(path string) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read circuit states file: %w", err)
+	}
+	var circuits map[string]CircuitSnapshot
+	if err := json.Unmarshal(data, &circuits); err != nil {
+		return fmt.Errorf("failed to parse circuit states file: %w", err)
+	}
+	r.breakers.restore(circuits)
+	return nil
+}
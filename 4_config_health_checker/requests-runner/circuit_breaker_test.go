@@ -0,0 +1,198 @@
+package requestsrunner_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/friofry/config-health-checker/chainprotocol"
+	requestsrunner "github.com/friofry/config-health-checker/requests-runner"
+	"github.com/friofry/config-health-checker/rpcprovider"
+)
+
+// switchableServer answers with 503 while down and with a valid result otherwise, recording called methods
+type switchableServer struct {
+	*httptest.Server
+	down    atomic.Bool
+	mu      sync.Mutex
+	methods []string
+}
+
+func newSwitchableServer() *switchableServer {
+	s := &switchableServer{}
+	s.Server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Method string `json:"method"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		s.mu.Lock()
+		s.methods = append(s.methods, req.Method)
+		s.mu.Unlock()
+
+		if s.down.Load() {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x1"}`))
+	}))
+	return s
+}
+
+func (s *switchableServer) calledMethods() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]string(nil), s.methods...)
+}
+
+func (s *switchableServer) reset() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.methods = nil
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	server := newSwitchableServer()
+	defer server.Close()
+	server.down.Store(true)
+
+	runner := requestsrunner.NewRequestsRunnerWithOptions(requestsrunner.RunnerOptions{
+		RetryPolicy: requestsrunner.RetryPolicy{MaxAttempts: 1},
+		CircuitBreaker: requestsrunner.CircuitBreakerConfig{
+			FailureThreshold: 2,
+			OpenDuration:     50 * time.Millisecond,
+			ProbeMethod:      "web3_clientVersion",
+		},
+	})
+	provider := rpcprovider.RpcProvider{Name: "flaky", URL: server.URL, AuthType: rpcprovider.NoAuth}
+	call := func() requestsrunner.ProviderResult {
+		return runner.CallEVMMethod(context.Background(), provider, "eth_blockNumber", nil, time.Second)
+	}
+
+	// Consecutive failures open the circuit
+	assert.Equal(t, requestsrunner.ErrorClassServerError, call().ErrorClass())
+	assert.Equal(t, requestsrunner.CircuitClosed, runner.CircuitStates()[requestsrunner.ProviderKey(provider)].State)
+	assert.Equal(t, requestsrunner.ErrorClassServerError, call().ErrorClass())
+	assert.Equal(t, requestsrunner.CircuitOpen, runner.CircuitStates()[requestsrunner.ProviderKey(provider)].State)
+
+	// An open circuit fails fast without contacting the provider
+	server.reset()
+	assert.Equal(t, requestsrunner.ErrorClassCircuitOpen, call().ErrorClass())
+	assert.Empty(t, server.calledMethods())
+
+	// After the open duration only the probe is sent while the provider is still down
+	time.Sleep(60 * time.Millisecond)
+	assert.Equal(t, requestsrunner.ErrorClassCircuitOpen, call().ErrorClass())
+	assert.Equal(t, []string{"web3_clientVersion"}, server.calledMethods())
+	assert.Equal(t, requestsrunner.CircuitOpen, runner.CircuitStates()[requestsrunner.ProviderKey(provider)].State)
+
+	// A successful probe closes the circuit and the request goes through
+	server.down.Store(false)
+	server.reset()
+	time.Sleep(60 * time.Millisecond)
+	result := call()
+	assert.True(t, result.Success)
+	assert.Equal(t, []string{"web3_clientVersion", "eth_blockNumber"}, server.calledMethods())
+	state := runner.CircuitStates()[requestsrunner.ProviderKey(provider)]
+	assert.Equal(t, requestsrunner.CircuitClosed, state.State)
+	assert.Equal(t, 0, state.ConsecutiveFailures)
+}
+
+func TestCircuitBreakerProbesWithProtocolMethod(t *testing.T) {
+	server := newSwitchableServer()
+	defer server.Close()
+	server.down.Store(true)
+
+	runner := requestsrunner.NewRequestsRunnerWithOptions(requestsrunner.RunnerOptions{
+		RetryPolicy:    requestsrunner.RetryPolicy{MaxAttempts: 1},
+		CircuitBreaker: requestsrunner.CircuitBreakerConfig{FailureThreshold: 1, OpenDuration: 50 * time.Millisecond},
+	})
+	solana := runner.ForProtocol(chainprotocol.Solana)
+	provider := rpcprovider.RpcProvider{Name: "solana", URL: server.URL, AuthType: rpcprovider.NoAuth}
+
+	solana.CallEVMMethod(context.Background(), provider, "getSlot", nil, time.Second)
+	assert.Equal(t, requestsrunner.CircuitOpen, runner.CircuitStates()[requestsrunner.ProviderKey(provider)].State)
+
+	server.reset()
+	time.Sleep(60 * time.Millisecond)
+	solana.CallEVMMethod(context.Background(), provider, "getSlot", nil, time.Second)
+	assert.Equal(t, []string{"getHealth"}, server.calledMethods())
+}
+
+func TestCircuitBreakerIgnoresRPCErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"jsonrpc":"2.0","id":1,"error":{"code":-32601,"message":"method not found"}}`))
+	}))
+	defer server.Close()
+
+	runner := requestsrunner.NewRequestsRunnerWithOptions(requestsrunner.RunnerOptions{
+		CircuitBreaker: requestsrunner.CircuitBreakerConfig{FailureThreshold: 1, OpenDuration: time.Minute},
+	})
+	provider := rpcprovider.RpcProvider{Name: "reachable", URL: server.URL, AuthType: rpcprovider.NoAuth}
+	for i := 0; i < 3; i++ {
+		result := runner.CallEVMMethod(context.Background(), provider, "eth_blockNumber", nil, time.Second)
+		assert.Equal(t, requestsrunner.ErrorClassRPCError, result.ErrorClass())
+	}
+	assert.Equal(t, requestsrunner.CircuitClosed, runner.CircuitStates()[requestsrunner.ProviderKey(provider)].State)
+}
+
+func TestCircuitBreakerPerChain(t *testing.T) {
+	// Reference providers of different chains share their name, e.g. "infura"
+	down := newSwitchableServer()
+	defer down.Close()
+	down.down.Store(true)
+	up := newSwitchableServer()
+	defer up.Close()
+
+	runner := requestsrunner.NewRequestsRunnerWithOptions(requestsrunner.RunnerOptions{
+		RetryPolicy:    requestsrunner.RetryPolicy{MaxAttempts: 1},
+		CircuitBreaker: requestsrunner.CircuitBreakerConfig{FailureThreshold: 1, OpenDuration: time.Minute},
+	})
+	mainnet := rpcprovider.RpcProvider{Name: "infura", URL: down.URL + "/mainnet", AuthType: rpcprovider.NoAuth}
+	sepolia := rpcprovider.RpcProvider{Name: "infura", URL: up.URL + "/sepolia", AuthType: rpcprovider.NoAuth}
+
+	runner.CallEVMMethod(context.Background(), mainnet, "eth_blockNumber", nil, time.Second)
+	result := runner.CallEVMMethod(context.Background(), sepolia, "eth_blockNumber", nil, time.Second)
+	assert.True(t, result.Success)
+
+	states := runner.CircuitStates()
+	assert.Len(t, states, 2)
+	assert.Equal(t, requestsrunner.CircuitOpen, states[requestsrunner.ProviderKey(mainnet)].State)
+	assert.Equal(t, requestsrunner.CircuitClosed, states[requestsrunner.ProviderKey(sepolia)].State)
+}
+
+func TestCircuitStatesPersistence(t *testing.T) {
+	server := newSwitchableServer()
+	defer server.Close()
+	server.down.Store(true)
+
+	opts := requestsrunner.RunnerOptions{
+		CircuitBreaker: requestsrunner.CircuitBreakerConfig{FailureThreshold: 1, OpenDuration: time.Minute},
+	}
+	runner := requestsrunner.NewRequestsRunnerWithOptions(opts)
+	provider := rpcprovider.RpcProvider{Name: "down", URL: server.URL, AuthType: rpcprovider.NoAuth}
+	runner.CallEVMMethod(context.Background(), provider, "eth_blockNumber", nil, time.Second)
+	require.Equal(t, requestsrunner.CircuitOpen, runner.CircuitStates()[requestsrunner.ProviderKey(provider)].State)
+
+	path := filepath.Join(t.TempDir(), "circuits.json")
+	require.NoError(t, runner.SaveCircuitStates(path))
+
+	restored := requestsrunner.NewRequestsRunnerWithOptions(opts)
+	require.NoError(t, restored.LoadCircuitStates(path))
+	assert.Equal(t, requestsrunner.CircuitOpen, restored.CircuitStates()[requestsrunner.ProviderKey(provider)].State)
+
+	server.reset()
+	result := restored.CallEVMMethod(context.Background(), provider, "eth_blockNumber", nil, time.Second)
+	assert.Equal(t, requestsrunner.ErrorClassCircuitOpen, result.ErrorClass())
+	assert.Empty(t, server.calledMethods())
+}
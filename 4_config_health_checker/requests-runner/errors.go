@@ -0,0 +1,198 @@
+package requestsrunner
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// ErrorClass categorizes provider failures so that policies and metrics can react to each class differently
+type ErrorClass string
+
+const (
+	ErrorClassNone              ErrorClass = ""                   // No error
+	ErrorClassTimeout           ErrorClass = "timeout"            // Request deadline exceeded
+	ErrorClassCanceled          ErrorClass = "canceled"           // Request canceled by the caller
+	ErrorClassDNS               ErrorClass = "dns"                // Host name could not be resolved
+	ErrorClassTLS               ErrorClass = "tls"                // TLS handshake or certificate verification failed
+	ErrorClassConnectionRefused ErrorClass = "connection_refused" // Provider refused the TCP connection
+	ErrorClassNetwork           ErrorClass = "network"            // Any other transport-level failure
+	ErrorClassRateLimited       ErrorClass = "http_429"           // Provider throttled the request with HTTP 429
+	ErrorClassServerError       ErrorClass = "http_5xx"           // Provider answered with an HTTP 5xx status
+	ErrorClassHTTPStatus        ErrorClass = "http_status"        // Provider answered with another non-200 status
+	ErrorClassRPCError          ErrorClass = "rpc_error"          // Provider answered with a JSON-RPC error object
+	ErrorClassMalformedJSON     ErrorClass = "malformed_json"     // Response could not be parsed
+	ErrorClassResultMismatch    ErrorClass = "result_mismatch"    // Result differs from the reference provider
+	ErrorClassReferenceFailure  ErrorClass = "reference_failure"  // Reference provider failed, result cannot be validated
+	ErrorClassCircuitOpen       ErrorClass = "circuit_open"       // Request not sent because the provider circuit is open
+	ErrorClassUnknown           ErrorClass = "unknown"            // Unclassified error
+)
+
+// knownErrorClasses lists every class produced by the runner and the checker
+var knownErrorClasses = map[ErrorClass]bool{
+	ErrorClassTimeout:           true,
+	ErrorClassCanceled:          true,
+	ErrorClassDNS:               true,
+	ErrorClassTLS:               true,
+	ErrorClassConnectionRefused: true,
+	ErrorClassNetwork:           true,
+	ErrorClassRateLimited:       true,
+	ErrorClassServerError:       true,
+	ErrorClassHTTPStatus:        true,
+	ErrorClassRPCError:          true,
+	ErrorClassMalformedJSON:     true,
+	ErrorClassResultMismatch:    true,
+	ErrorClassReferenceFailure:  true,
+	ErrorClassCircuitOpen:       true,
+	ErrorClassUnknown:           true,
+}
+
+// IsValid reports whether the class is one of the known error classes
+func (c ErrorClass) IsValid() bool {
+	return knownErrorClasses[c]
+}
+
+// ProviderError is a classified provider failure with the structured data of its class
+type ProviderError struct {
+	Class      ErrorClass      // Error category
+	StatusCode int             // HTTP status code (HTTP classes)
+	RetryAfter time.Duration   // Parsed Retry-After header, zero if absent (HTTP classes)
+	RPCCode    int             // JSON-RPC error code (ErrorClassRPCError)
+	RPCMessage string          // JSON-RPC error message (ErrorClassRPCError)
+	RPCData    json.RawMessage // JSON-RPC error data, if any (ErrorClassRPCError)
+	Err        error           // Underlying error
+}
+
+// Error implements the error interface
+func (e *ProviderError) Error() string {
+	switch {
+	case e.Err != nil:
+		return e.Err.Error()
+	case e.Class == ErrorClassRPCError:
+		return fmt.Sprintf("JSON-RPC error: %s (code %d)", e.RPCMessage, e.RPCCode)
+	case e.StatusCode != 0:
+		return fmt.Sprintf("unexpected status code: %d", e.StatusCode)
+	default:
+		return string(e.Class)
+	}
+}
+
+// Unwrap returns the underlying error
+func (e *ProviderError) Unwrap() error {
+	return e.Err
+}
+
+// ClassifyError returns the class of err.
+// Errors that are not a ProviderError are classified by inspecting the error chain.
+func ClassifyError(err error) ErrorClass {
+	if err == nil {
+		return ErrorClassNone
+	}
+
+	var providerErr *ProviderError
+	if errors.As(err, &providerErr) {
+		return providerErr.Class
+	}
+
+	return classifyTransportError(err)
+}
+
+// ErrorClass returns the class of the result error, ErrorClassNone for successful results
+func (r ProviderResult) ErrorClass() ErrorClass {
+	return ClassifyError(r.Error)
+}
+
+// ProviderError returns the classified error of the result, if any
+func (r ProviderResult) ProviderError() (*ProviderError, bool) {
+	var providerErr *ProviderError
+	if errors.As(r.Error, &providerErr) {
+		return providerErr, true
+	}
+	return nil, false
+}
+
+// newTransportError wraps a failed HTTP round trip into a classified error
+func newTransportError(err error) *ProviderError {
+	return &ProviderError{
+		Class: classifyTransportError(err),
+		Err:   fmt.Errorf("request failed: %w", err),
+	}
+}
+
+// newHTTPStatusError creates a classified error for a non-200 HTTP response
+func newHTTPStatusError(resp *http.Response) *ProviderError {
+	class := ErrorClassHTTPStatus
+	switch {
+	case resp.StatusCode == http.StatusTooManyRequests:
+		class = ErrorClassRateLimited
+	case resp.StatusCode >= 500:
+		class = ErrorClassServerError
+	}
+
+	return &ProviderError{
+		Class:      class,
+		StatusCode: resp.StatusCode,
+		RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+	}
+}
+
+// classifyTransportError maps network and context errors to an error class
+func classifyTransportError(err error) ErrorClass {
+	var (
+		dnsErr       *net.DNSError
+		netErr       net.Error
+		certErr      *tls.CertificateVerificationError
+		recordErr    tls.RecordHeaderError
+		alertErr     tls.AlertError
+		authorityErr x509.UnknownAuthorityError
+		hostnameErr  x509.HostnameError
+		invalidErr   x509.CertificateInvalidError
+	)
+
+	switch {
+	case errors.Is(err, context.DeadlineExceeded):
+		return ErrorClassTimeout
+	case errors.Is(err, context.Canceled):
+		return ErrorClassCanceled
+	case errors.As(err, &dnsErr):
+		return ErrorClassDNS
+	case errors.As(err, &certErr), errors.As(err, &recordErr), errors.As(err, &alertErr),
+		errors.As(err, &authorityErr), errors.As(err, &hostnameErr), errors.As(err, &invalidErr):
+		return ErrorClassTLS
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return ErrorClassConnectionRefused
+	case errors.As(err, &netErr) && netErr.Timeout():
+		return ErrorClassTimeout
+	case errors.As(err, &netErr):
+		return ErrorClassNetwork
+	default:
+		return ErrorClassUnknown
+	}
+}
+
+// parseRetryAfter parses a Retry-After header given either in seconds or as an HTTP date
+func parseRetryAfter(value string, now time.Time) time.Duration {
+	if value == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(value); err == nil {
+		if seconds < 0 {
+			return 0
+		}
+		return time.Duration(seconds) * time.Second
+	}
+	if date, err := http.ParseTime(value); err == nil {
+		if wait := date.Sub(now); wait > 0 {
+			return wait
+		}
+	}
+	return 0
+}
@@ -0,0 +1,164 @@
+package requestsrunner_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	requestsrunner "github.com/friofry/config-health-checker/requests-runner"
+	"github.com/friofry/config-health-checker/rpcprovider"
+)
+
+func TestCallEVMMethodErrorClassification(t *testing.T) {
+	tests := []struct {
+		name         string
+		handler      func(http.ResponseWriter, *http.Request)
+		wantClass    requestsrunner.ErrorClass
+		wantStatus   int
+		wantRetry    time.Duration
+		wantRPCCode  int
+		wantRPCMsg   string
+		wantRPCData  string
+		wantResponse string
+	}{
+		{
+			name: "Rate limited with Retry-After",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Retry-After", "7")
+				w.WriteHeader(http.StatusTooManyRequests)
+				w.Write([]byte(`{"message":"slow down"}`))
+			},
+			wantClass:    requestsrunner.ErrorClassRateLimited,
+			wantStatus:   http.StatusTooManyRequests,
+			wantRetry:    7 * time.Second,
+			wantResponse: `{"message":"slow down"}`,
+		},
+		{
+			name: "Server error",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusBadGateway)
+				w.Write([]byte(`upstream unavailable`))
+			},
+			wantClass:    requestsrunner.ErrorClassServerError,
+			wantStatus:   http.StatusBadGateway,
+			wantResponse: `upstream unavailable`,
+		},
+		{
+			name: "Other HTTP status",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusNotFound)
+			},
+			wantClass:  requestsrunner.ErrorClassHTTPStatus,
+			wantStatus: http.StatusNotFound,
+		},
+		{
+			name: "JSON-RPC error",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte(`{"jsonrpc":"2.0","id":1,"error":{"code":-32005,"message":"limit exceeded","data":{"see":"docs"}}}`))
+			},
+			wantClass:   requestsrunner.ErrorClassRPCError,
+			wantRPCCode: -32005,
+			wantRPCMsg:  "limit exceeded",
+			wantRPCData: `{"see":"docs"}`,
+		},
+		{
+			name: "Malformed JSON",
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte(`<html>`))
+			},
+			wantClass:    requestsrunner.ErrorClassMalformedJSON,
+			wantResponse: `<html>`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(tt.handler))
+			defer server.Close()
+
+			provider := rpcprovider.RpcProvider{Name: "test", URL: server.URL, AuthType: rpcprovider.NoAuth}
+			result := requestsrunner.NewRequestsRunner().CallEVMMethod(context.Background(), provider, "eth_blockNumber", nil, 1*time.Second)
+
+			assert.False(t, result.Success)
+			assert.Equal(t, tt.wantClass, result.ErrorClass())
+
+			providerErr, ok := result.ProviderError()
+			require.True(t, ok, "expected a classified provider error")
+			assert.Equal(t, tt.wantStatus, providerErr.StatusCode)
+			assert.Equal(t, tt.wantRetry, providerErr.RetryAfter)
+			assert.Equal(t, tt.wantRPCCode, providerErr.RPCCode)
+			assert.Equal(t, tt.wantRPCMsg, providerErr.RPCMessage)
+			if tt.wantRPCData != "" {
+				assert.JSONEq(t, tt.wantRPCData, string(providerErr.RPCData))
+			}
+			if tt.wantResponse != "" {
+				assert.Equal(t, tt.wantResponse, string(result.Response))
+			}
+		})
+	}
+}
+
+func TestCallEVMMethodTransportErrorClassification(t *testing.T) {
+	t.Run("Connection refused", func(t *testing.T) {
+		server := httptest.NewServer(http.NotFoundHandler())
+		url := server.URL
+		server.Close()
+
+		provider := rpcprovider.RpcProvider{Name: "test", URL: url, AuthType: rpcprovider.NoAuth}
+		result := requestsrunner.NewRequestsRunner().CallEVMMethod(context.Background(), provider, "eth_blockNumber", nil, 1*time.Second)
+		assert.Equal(t, requestsrunner.ErrorClassConnectionRefused, result.ErrorClass())
+	})
+
+	t.Run("TLS verification failure", func(t *testing.T) {
+		server := httptest.NewTLSServer(http.NotFoundHandler())
+		defer server.Close()
+
+		provider := rpcprovider.RpcProvider{Name: "test", URL: server.URL, AuthType: rpcprovider.NoAuth}
+		result := requestsrunner.NewRequestsRunner().CallEVMMethod(context.Background(), provider, "eth_blockNumber", nil, 1*time.Second)
+		assert.Equal(t, requestsrunner.ErrorClassTLS, result.ErrorClass())
+	})
+
+	t.Run("Timeout", func(t *testing.T) {
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			time.Sleep(200 * time.Millisecond)
+		}))
+		defer server.Close()
+
+		provider := rpcprovider.RpcProvider{Name: "test", URL: server.URL, AuthType: rpcprovider.NoAuth}
+		result := requestsrunner.NewRequestsRunner().CallEVMMethod(context.Background(), provider, "eth_blockNumber", nil, 20*time.Millisecond)
+		assert.Equal(t, requestsrunner.ErrorClassTimeout, result.ErrorClass())
+	})
+}
+
+func TestClassifyError(t *testing.T) {
+	assert.Equal(t, requestsrunner.ErrorClassNone, requestsrunner.ClassifyError(nil))
+	assert.Equal(t, requestsrunner.ErrorClassTimeout, requestsrunner.ClassifyError(context.DeadlineExceeded))
+	assert.Equal(t, requestsrunner.ErrorClassCanceled, requestsrunner.ClassifyError(context.Canceled))
+	assert.Equal(t, requestsrunner.ErrorClassUnknown, requestsrunner.ClassifyError(errors.New("boom")))
+
+	wrapped := fmt.Errorf("wrapped: %w", &requestsrunner.ProviderError{Class: requestsrunner.ErrorClassResultMismatch})
+	assert.Equal(t, requestsrunner.ErrorClassResultMismatch, requestsrunner.ClassifyError(wrapped))
+}
+
+func TestCallEVMMethodTransportErrorRedactsToken(t *testing.T) {
+	server := httptest.NewServer(http.NotFoundHandler())
+	url := server.URL
+	server.Close()
+
+	provider := rpcprovider.RpcProvider{Name: "test", URL: url + "/v3", AuthType: rpcprovider.TokenAuth, AuthToken: "secret-token"}
+	result := requestsrunner.NewRequestsRunner().CallEVMMethod(context.Background(), provider, "eth_blockNumber", nil, 1*time.Second)
+
+	require.Error(t, result.Error)
+	assert.Equal(t, requestsrunner.ErrorClassConnectionRefused, result.ErrorClass())
+	assert.NotContains(t, result.Error.Error(), "secret-token")
+	assert.Contains(t, result.Error.Error(), rpcprovider.RedactedValue)
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"time"
 
+	"github.com/friofry/config-health-checker/chainprotocol"
 	"github.com/friofry/config-health-checker/rpcprovider"
 )
 
@@ -17,3 +18,34 @@ type EVMMethodCaller interface {
 		timeout time.Duration,
 	) ProviderResult
 }
+
+// BatchEVMMethodCaller extends EVMMethodCaller with JSON-RPC batch requests
+type BatchEVMMethodCaller interface {
+	EVMMethodCaller
+	CallEVMMethodBatch(
+		ctx context.Context,
+		provider rpcprovider.RpcProvider,
+		calls []MethodCall,
+		timeout time.Duration,
+	) BatchResult
+}
+
+// ProtocolCaller is implemented by callers that can speak the JSON-RPC dialect of non-EVM chains
+type ProtocolCaller interface {
+	ForProtocol(protocol chainprotocol.Protocol) EVMMethodCaller
+}
+
+// DeadlineCaller is implemented by callers that retry, so a call may take longer than its per-attempt timeout
+type DeadlineCaller interface {
+	CallDeadline(timeout time.Duration) time.Duration
+}
+
+// callDeadline returns the time a call of caller with the given per-attempt timeout may take
+func callDeadline(caller EVMMethodCaller, timeout time.Duration) time.Duration {
+	if deadlineCaller, ok := caller.(DeadlineCaller); ok {
+		if deadline := deadlineCaller.CallDeadline(timeout); deadline > timeout {
+			return deadline
+		}
+	}
+	return timeout
+}
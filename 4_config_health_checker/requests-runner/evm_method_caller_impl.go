@@ -4,20 +4,101 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"time"
 
+	"github.com/friofry/config-health-checker/chainprotocol"
 	rpcprovider "github.com/friofry/config-health-checker/rpcprovider"
 )
 
 // RequestsRunner implements EVMMethodCaller interface
-type RequestsRunner struct{}
+type RequestsRunner struct {
+	retryPolicy RetryPolicy
+	throttles   *throttleTracker
+	breakers    *circuitBreakers
+	batches     *batchSupport
+	protocol    chainprotocol.Protocol // JSON-RPC dialect of requests and probes
+}
+
+// RunnerOptions configures a RequestsRunner
+type RunnerOptions struct {
+	RetryPolicy    RetryPolicy
+	CircuitBreaker CircuitBreakerConfig
+}
 
-// NewRequestsRunner creates a new instance of RequestsRunner
+// DefaultRunnerOptions returns the options used by NewRequestsRunner
+func DefaultRunnerOptions() RunnerOptions {
+	return RunnerOptions{
+		RetryPolicy:    DefaultRetryPolicy(),
+		CircuitBreaker: DefaultCircuitBreakerConfig(),
+	}
+}
+
+// NewRequestsRunner creates a new instance of RequestsRunner with the default options
 func NewRequestsRunner() *RequestsRunner {
-	return &RequestsRunner{}
+	return NewRequestsRunnerWithOptions(DefaultRunnerOptions())
+}
+
+// NewRequestsRunnerWithRetryPolicy creates a new instance of RequestsRunner with the given retry policy
+func NewRequestsRunnerWithRetryPolicy(policy RetryPolicy) *RequestsRunner {
+	opts := DefaultRunnerOptions()
+	opts.RetryPolicy = policy
+	return NewRequestsRunnerWithOptions(opts)
+}
+
+// NewRequestsRunnerWithOptions creates a new instance of RequestsRunner with the given options
+func NewRequestsRunnerWithOptions(opts RunnerOptions) *RequestsRunner {
+	policy := opts.RetryPolicy
+	if policy.MaxAttempts < 1 {
+		policy.MaxAttempts = 1
+	}
+	if policy.RetryOn == nil {
+		policy.RetryOn = DefaultRetryPolicy().RetryOn
+	}
+
+	breaker := opts.CircuitBreaker
+	if breaker.FailureThreshold < 1 {
+		breaker.FailureThreshold = 1
+	}
+
+	return &RequestsRunner{
+		retryPolicy: policy,
+		throttles:   newThrottleTracker(),
+		breakers:    newCircuitBreakers(breaker),
+		batches:     newBatchSupport(),
+		protocol:    chainprotocol.EVM,
+	}
+}
+
+// ForProtocol returns a runner that speaks the JSON-RPC dialect of the given protocol.
+// The returned runner shares rate-limit, circuit breaker and batch support state with r.
+func (r *RequestsRunner) ForProtocol(protocol chainprotocol.Protocol) EVMMethodCaller {
+	runner := *r
+	runner.protocol = protocol.OrDefault()
+	return &runner
+}
+
+// jsonRPCRequest represents a single JSON-RPC request object
+type jsonRPCRequest struct {
+	JSONRPC string        `json:"jsonrpc"`
+	Method  string        `json:"method"`
+	Params  []interface{} `json:"params"`
+	ID      int           `json:"id"`
+}
+
+// jsonRPCResponse represents a single JSON-RPC 2.0 response object
+type jsonRPCResponse struct {
+	ID     json.RawMessage `json:"id"`
+	Result interface{}     `json:"result"`
+	Error  *struct {
+		Code    int             `json:"code"`
+		Message string          `json:"message"`
+		Data    json.RawMessage `json:"data"`
+	} `json:"error"`
 }
 
 // CallEVMMethod makes an HTTP POST request to an RPC provider for a specific EVM method
@@ -31,15 +112,13 @@ func (r *RequestsRunner) CallEVMMethod(
 ) ProviderResult {
 	startTime := time.Now()
 
-	// Create JSON-RPC 2.0 request body
-	requestBody := map[string]interface{}{
-		"jsonrpc": "2.0",
-		"method":  method,
-		"params":  params,
-		"id":      1,
-	}
-
-	jsonBody, err := json.Marshal(requestBody)
+	// Create JSON-RPC request body
+	jsonBody, err := json.Marshal(jsonRPCRequest{
+		JSONRPC: r.protocol.JSONRPCVersion(),
+		Method:  method,
+		Params:  params,
+		ID:      1,
+	})
 	if err != nil {
 		return ProviderResult{
 			Success:     false,
@@ -48,82 +127,140 @@ func (r *RequestsRunner) CallEVMMethod(
 		}
 	}
 
-	// Create HTTP client with timeout from context
-	client := &http.Client{}
-	req, err := http.NewRequest("POST", provider.URL, bytes.NewBuffer(jsonBody))
-	if err != nil {
+	ctx, cancel := r.retryPolicy.withDeadline(ctx, timeout)
+	defer cancel()
+
+	if err := r.guard(ctx, provider, timeout); err != nil {
 		return ProviderResult{
 			Success:     false,
-			Error:       fmt.Errorf("failed to create request: %w", err),
+			Error:       err,
 			ElapsedTime: time.Since(startTime),
 		}
 	}
 
+	var result ProviderResult
+	attempts, _ := r.withRetry(ctx, provider, timeout, func(ctx context.Context) error {
+		body, err := r.send(ctx, provider, jsonBody)
+		if err != nil {
+			result = ProviderResult{
+				Success:  false,
+				Error:    err,
+				Response: body,
+			}
+			return err
+		}
+
+		result = parseResponse(body)
+		return result.Error
+	})
+
+	r.recordOutcome(provider, result.Error)
+
+	result.ElapsedTime = time.Since(startTime)
+	result.Attempts = attempts
+	return result
+}
+
+// send sends a JSON payload to the provider and returns the raw response.
+// Providers with ws:// or wss:// URLs are reached over a websocket, all others with an HTTP POST.
+func (r *RequestsRunner) send(
+	ctx context.Context,
+	provider rpcprovider.RpcProvider,
+	payload []byte,
+) ([]byte, error) {
+	if provider.IsWebSocket() {
+		return sendWebSocket(ctx, provider, payload)
+	}
+	return r.post(ctx, provider, payload)
+}
+
+// post sends a JSON payload to the provider with an HTTP POST and returns the raw response body.
+// Failures are returned as *ProviderError; the body of non-200 responses is returned along with the error.
+func (r *RequestsRunner) post(
+	ctx context.Context,
+	provider rpcprovider.RpcProvider,
+	payload []byte,
+) ([]byte, error) {
+	// Create HTTP client with timeout from context
+	client := &http.Client{}
+	requestURL, err := provider.RequestURL()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request URL: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", requestURL, bytes.NewBuffer(payload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+
 	// Set headers
 	req.Header.Set("Content-Type", "application/json")
+	for name, value := range provider.Headers {
+		req.Header.Set(name, value)
+	}
 
 	// Set authentication based on provider type
 	switch provider.AuthType {
 	case rpcprovider.BasicAuth:
 		req.SetBasicAuth(provider.AuthLogin, provider.AuthPassword)
-	case rpcprovider.TokenAuth:
-		req.URL.Path += fmt.Sprintf("/%s", provider.AuthToken)
+	case rpcprovider.HeaderAuth:
+		req.Header.Set(provider.AuthHeader, provider.AuthToken)
 	}
 
 	// Make the request
 	resp, err := client.Do(req)
 	if err != nil {
-		return ProviderResult{
-			Success:     false,
-			Error:       fmt.Errorf("request failed: %w", err),
-			ElapsedTime: time.Since(startTime),
+		// The request URL may carry the provider token
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			urlErr.URL = provider.RedactedURL()
 		}
+		return nil, newTransportError(err)
 	}
 	defer resp.Body.Close()
 
-	// Check response status code
-	if resp.StatusCode != http.StatusOK {
-		return ProviderResult{
-			Success:     false,
-			Error:       fmt.Errorf("unexpected status code: %d", resp.StatusCode),
-			ElapsedTime: time.Since(startTime),
-		}
-	}
-
-	// Read and parse response
+	// Read response
 	body, err := io.ReadAll(resp.Body)
 	if err != nil {
-		return ProviderResult{
-			Success:     false,
-			Error:       fmt.Errorf("failed to read response: %w", err),
-			ElapsedTime: time.Since(startTime),
+		return nil, &ProviderError{
+			Class: classifyTransportError(err),
+			Err:   fmt.Errorf("failed to read response: %w", err),
 		}
 	}
 
-	// Parse JSON-RPC response
-	var jsonResponse struct {
-		Result interface{} `json:"result"`
-		Error  struct {
-			Code    int    `json:"code"`
-			Message string `json:"message"`
-		} `json:"error"`
+	// Check response status code
+	if resp.StatusCode != http.StatusOK {
+		return body, newHTTPStatusError(resp)
 	}
 
+	return body, nil
+}
+
+// parseResponse converts a single JSON-RPC response object into a ProviderResult.
+// ElapsedTime is left for the caller to fill in.
+func parseResponse(body []byte) ProviderResult {
+	var jsonResponse jsonRPCResponse
 	if err := json.Unmarshal(body, &jsonResponse); err != nil {
 		return ProviderResult{
-			Success:     false,
-			Error:       fmt.Errorf("failed to parse JSON response: %w", err),
-			ElapsedTime: time.Since(startTime),
+			Success: false,
+			Error: &ProviderError{
+				Class: ErrorClassMalformedJSON,
+				Err:   fmt.Errorf("failed to parse JSON response: %w", err),
+			},
+			Response: body,
 		}
 	}
 
 	// Check for JSON-RPC error
-	if jsonResponse.Error.Code != 0 {
+	if jsonResponse.Error != nil {
 		return ProviderResult{
-			Success:     false,
-			Error:       fmt.Errorf("JSON-RPC error: %s (code %d)", jsonResponse.Error.Message, jsonResponse.Error.Code),
-			Response:    body,
-			ElapsedTime: time.Since(startTime),
+			Success: false,
+			Error: &ProviderError{
+				Class:      ErrorClassRPCError,
+				RPCCode:    jsonResponse.Error.Code,
+				RPCMessage: jsonResponse.Error.Message,
+				RPCData:    jsonResponse.Error.Data,
+			},
+			Response: body,
 		}
 	}
 
@@ -131,10 +268,9 @@ func (r *RequestsRunner) CallEVMMethod(
 	resultStr := fmt.Sprintf("%v", jsonResponse.Result)
 
 	return ProviderResult{
-		Success:     true,
-		Error:       nil,
-		Result:      resultStr,
-		Response:    body,
-		ElapsedTime: time.Since(startTime),
+		Success:  true,
+		Error:    nil,
+		Result:   resultStr,
+		Response: body,
 	}
 }
@@ -2,7 +2,7 @@ package requestsrunner
 
 import (
 	"context"
-	"fmt"
+	"log/slog"
 	"sync"
 	"time"
 
@@ -16,8 +16,32 @@ type ProviderResult struct {
 	Response    []byte        // Response from the provider
 	Result      string        // Result from the provider (if successful)
 	ElapsedTime time.Duration // Duration taken to perform the request
+	Attempts    []Attempt     // Every attempt made for the request, including retries
 }
 
+// LogValue implements slog.LogValuer. The raw response is reduced to its size to keep logs short.
+func (r ProviderResult) LogValue() slog.Value {
+	attrs := []slog.Attr{
+		slog.Bool("success", r.Success),
+		slog.Duration("elapsed", r.ElapsedTime),
+		slog.Int("attempts", len(r.Attempts)),
+		slog.Int("responseBytes", len(r.Response)),
+	}
+	if r.Result != "" {
+		attrs = append(attrs, slog.String("result", r.Result))
+	}
+	if r.Error != nil {
+		attrs = append(attrs,
+			slog.String("error", r.Error.Error()),
+			slog.String("errorClass", string(r.ErrorClass())),
+		)
+	}
+	return slog.GroupValue(attrs...)
+}
+
+// cancelGrace is how long a check cut off by the context may take to return its own result
+const cancelGrace = 100 * time.Millisecond
+
 // RequestFunc defines the type of function used to check a provider.
 type RequestFunc func(ctx context.Context, provider rpcprovider.RpcProvider) ProviderResult
 
@@ -44,6 +68,8 @@ func ParallelCheckProviders(ctx context.Context, providers []rpcprovider.RpcProv
 		go func(p rpcprovider.RpcProvider) {
 			defer wg.Done()
 
+			startTime := time.Now()
+
 			// Create a temporary channel to receive checker result
 			tempChan := make(chan ProviderResult, 1)
 
@@ -63,11 +89,10 @@ func ParallelCheckProviders(ctx context.Context, providers []rpcprovider.RpcProv
 				}{name: p.Name, result: res}
 			case <-ctx.Done():
 				// Context canceled or timed out
-				fmt.Println("Context canceled", p.Name)
 				resultsChan <- struct {
 					name   string
 					result ProviderResult
-				}{name: p.Name, result: ProviderResult{Success: false, Error: ctx.Err()}}
+				}{name: p.Name, result: canceledResult(ctx, p, tempChan, startTime)}
 			}
 		}(provider)
 	}
@@ -86,6 +111,31 @@ func ParallelCheckProviders(ctx context.Context, providers []rpcprovider.RpcProv
 	return results
 }
 
+// canceledResult returns the result of a check cut off by the context. The checker shares the context,
+// so its own result, with the attempts it made, is used if it returns within cancelGrace.
+func canceledResult(ctx context.Context, provider rpcprovider.RpcProvider, results <-chan ProviderResult, startTime time.Time) ProviderResult {
+	timer := time.NewTimer(cancelGrace)
+	defer timer.Stop()
+	select {
+	case res := <-results:
+		return res
+	case <-timer.C:
+	}
+
+	err := &ProviderError{Class: ClassifyError(ctx.Err()), Err: ctx.Err()}
+	slog.Warn("provider check cut off", "provider", provider.Name, "error", err)
+	return ProviderResult{
+		Success: false,
+		Error:   err,
+		Attempts: []Attempt{{
+			Number:     1,
+			Latency:    time.Since(startTime),
+			Error:      err,
+			ErrorClass: err.Class,
+		}},
+	}
+}
+
 // ParallelCallEVMMethods executes EVM methods in parallel across multiple providers
 func ParallelCallEVMMethods(
 	ctx context.Context,
@@ -100,6 +150,6 @@ func ParallelCallEVMMethods(
 		return caller.CallEVMMethod(ctx, provider, method, params, timeout)
 	}
 
-	// Use ParallelCheckProviders to execute the calls in parallel
-	return ParallelCheckProviders(ctx, providers, timeout, checker)
+	// timeout bounds every attempt, the caller's deadline covers retries and backoffs
+	return ParallelCheckProviders(ctx, providers, callDeadline(caller, timeout), checker)
 }
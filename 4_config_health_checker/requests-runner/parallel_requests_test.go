@@ -6,12 +6,14 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/suite"
 
+	"github.com/friofry/config-health-checker/chainprotocol"
 	requestsrunner "github.com/friofry/config-health-checker/requests-runner"
 	"github.com/friofry/config-health-checker/rpcprovider"
 )
@@ -57,14 +59,14 @@ func createChecker(failProviders map[string]error, delay time.Duration) requests
 			result = requestsrunner.ProviderResult{
 				Success:     false,
 				Error:       err,
-				Response:    "",
+				Response:    nil,
 				ElapsedTime: delay,
 			}
 		} else {
 			result = requestsrunner.ProviderResult{
 				Success:     true,
 				Error:       nil,
-				Response:    "OK",
+				Response:    []byte("OK"),
 				ElapsedTime: delay,
 			}
 		}
@@ -76,7 +78,7 @@ func createChecker(failProviders map[string]error, delay time.Duration) requests
 			return requestsrunner.ProviderResult{
 				Success:     false,
 				Error:       ctx.Err(),
-				Response:    "",
+				Response:    nil,
 				ElapsedTime: 0,
 			}
 		}
@@ -137,9 +139,9 @@ func (suite *ParallelCheckProvidersTestSuite) TestParallelCheckProviders() {
 			delay:   10 * time.Millisecond,
 			timeout: 1 * time.Second,
 			expectedResults: map[string]requestsrunner.ProviderResult{
-				"Provider1": {Success: true, Error: nil, Response: "OK", ElapsedTime: 10 * time.Millisecond},
-				"Provider2": {Success: true, Error: nil, Response: "OK", ElapsedTime: 10 * time.Millisecond},
-				"Provider3": {Success: true, Error: nil, Response: "OK", ElapsedTime: 10 * time.Millisecond},
+				"Provider1": {Success: true, Error: nil, Response: []byte("OK"), ElapsedTime: 10 * time.Millisecond},
+				"Provider2": {Success: true, Error: nil, Response: []byte("OK"), ElapsedTime: 10 * time.Millisecond},
+				"Provider3": {Success: true, Error: nil, Response: []byte("OK"), ElapsedTime: 10 * time.Millisecond},
 			},
 		},
 		{
@@ -151,9 +153,9 @@ func (suite *ParallelCheckProvidersTestSuite) TestParallelCheckProviders() {
 			delay:   10 * time.Millisecond,
 			timeout: 1 * time.Second,
 			expectedResults: map[string]requestsrunner.ProviderResult{
-				"Provider1": {Success: true, Error: nil, Response: "OK", ElapsedTime: 10 * time.Millisecond},
-				"Provider2": {Success: false, Error: errors.New("connection timeout"), Response: "", ElapsedTime: 10 * time.Millisecond},
-				"Provider3": {Success: true, Error: nil, Response: "OK", ElapsedTime: 10 * time.Millisecond},
+				"Provider1": {Success: true, Error: nil, Response: []byte("OK"), ElapsedTime: 10 * time.Millisecond},
+				"Provider2": {Success: false, Error: errors.New("connection timeout"), Response: nil, ElapsedTime: 10 * time.Millisecond},
+				"Provider3": {Success: true, Error: nil, Response: []byte("OK"), ElapsedTime: 10 * time.Millisecond},
 			},
 		},
 		{
@@ -165,9 +167,9 @@ func (suite *ParallelCheckProvidersTestSuite) TestParallelCheckProviders() {
 			delay:   2 * time.Second,
 			timeout: 50 * time.Millisecond,
 			expectedResults: map[string]requestsrunner.ProviderResult{
-				"Provider1": {Success: false, Error: errors.New("context deadline exceeded"), Response: "", ElapsedTime: 0},
-				"Provider2": {Success: false, Error: errors.New("context deadline exceeded"), Response: "", ElapsedTime: 0},
-				"Provider3": {Success: false, Error: errors.New("context deadline exceeded"), Response: "", ElapsedTime: 0},
+				"Provider1": {Success: false, Error: errors.New("context deadline exceeded"), Response: nil, ElapsedTime: 0},
+				"Provider2": {Success: false, Error: errors.New("context deadline exceeded"), Response: nil, ElapsedTime: 0},
+				"Provider3": {Success: false, Error: errors.New("context deadline exceeded"), Response: nil, ElapsedTime: 0},
 			},
 		},
 		{
@@ -179,9 +181,9 @@ func (suite *ParallelCheckProvidersTestSuite) TestParallelCheckProviders() {
 			delay:   20 * time.Millisecond,
 			timeout: 2 * time.Second,
 			expectedResults: map[string]requestsrunner.ProviderResult{
-				"Provider1": {Success: true, Error: nil, Response: "OK", ElapsedTime: 20 * time.Millisecond},
-				"Provider2": {Success: false, Error: errors.New("authentication failed"), Response: "", ElapsedTime: 20 * time.Millisecond},
-				"Provider3": {Success: true, Error: nil, Response: "OK", ElapsedTime: 20 * time.Millisecond},
+				"Provider1": {Success: true, Error: nil, Response: []byte("OK"), ElapsedTime: 20 * time.Millisecond},
+				"Provider2": {Success: false, Error: errors.New("authentication failed"), Response: nil, ElapsedTime: 20 * time.Millisecond},
+				"Provider3": {Success: true, Error: nil, Response: []byte("OK"), ElapsedTime: 20 * time.Millisecond},
 			},
 		},
 		{
@@ -208,7 +210,7 @@ func (suite *ParallelCheckProvidersTestSuite) TestParallelCheckProviders() {
 			delay:   5 * time.Millisecond,
 			timeout: 1 * time.Second,
 			expectedResults: map[string]requestsrunner.ProviderResult{
-				"Provider1": {Success: false, Error: errors.New("unknown authentication type"), Response: "", ElapsedTime: 5 * time.Millisecond},
+				"Provider1": {Success: false, Error: errors.New("unknown authentication type"), Response: nil, ElapsedTime: 5 * time.Millisecond},
 			},
 		},
 	}
@@ -262,7 +264,7 @@ func TestParallelCallEVMMethods(t *testing.T) {
 			result, exists := results[provider.Name]
 			assert.True(t, exists)
 			assert.True(t, result.Success)
-			assert.Equal(t, "0x1", result.Response)
+			assert.Equal(t, "0x1", result.Result)
 			assert.Nil(t, result.Error)
 		}
 	})
@@ -301,9 +303,39 @@ func TestParallelCallEVMMethods(t *testing.T) {
 			assert.True(t, exists)
 			assert.False(t, result.Success, "Expected timeout failure for provider %s", provider.Name)
 			assert.Contains(t, result.Error.Error(), "context deadline exceeded", "Expected timeout error for provider %s", provider.Name)
+			assert.Equal(t, requestsrunner.ErrorClassTimeout, result.ErrorClass())
+			assert.NotEmpty(t, result.Attempts)
 		}
 	})
 
+	// Test retries that take longer than the per-attempt timeout
+	t.Run("RetriesWithinMethodDeadline", func(t *testing.T) {
+		var calls int32
+		flakyServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&calls, 1) == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"jsonrpc":"2.0","result":"0x1"}`))
+		}))
+		defer flakyServer.Close()
+
+		runner := requestsrunner.NewRequestsRunnerWithRetryPolicy(requestsrunner.RetryPolicy{
+			MaxAttempts:    3,
+			BaseBackoff:    150 * time.Millisecond,
+			MaxBackoff:     150 * time.Millisecond,
+			RetryOn:        []requestsrunner.ErrorClass{requestsrunner.ErrorClassServerError},
+			MethodDeadline: time.Second,
+		})
+		flaky := []rpcprovider.RpcProvider{{Name: "Flaky", URL: flakyServer.URL, AuthType: rpcprovider.NoAuth}}
+		results := requestsrunner.ParallelCallEVMMethods(context.Background(), flaky, "eth_blockNumber", nil, 100*time.Millisecond, runner)
+
+		result := results["Flaky"]
+		assert.True(t, result.Success, "unexpected error %v", result.Error)
+		assert.Len(t, result.Attempts, 2)
+	})
+
 	// Test empty providers
 	t.Run("EmptyProviders", func(t *testing.T) {
 		ctx := context.Background()
@@ -346,7 +378,7 @@ func (suite *ParallelCheckProvidersTestSuite) TestParallelCheckProvidersContextC
 		expectedResults[provider.Name] = requestsrunner.ProviderResult{
 			Success:     false,
 			Error:       errors.New("context canceled"),
-			Response:    "",
+			Response:    nil,
 			ElapsedTime: 0,
 		}
 	}
@@ -364,6 +396,7 @@ func TestCallEVMMethod(t *testing.T) {
 	tests := []struct {
 		name         string
 		provider     rpcprovider.RpcProvider
+		urlSuffix    string
 		method       string
 		params       []interface{}
 		handler      func(http.ResponseWriter, *http.Request)
@@ -453,6 +486,53 @@ func TestCallEVMMethod(t *testing.T) {
 			wantSuccess:  true,
 			wantResponse: "0x1",
 		},
+		{
+			name: "Successful TokenAuth request with URL template",
+			provider: rpcprovider.RpcProvider{
+				Name:      "test",
+				URL:       "", // Will be set to test server URL
+				AuthType:  rpcprovider.TokenAuth,
+				AuthToken: "test-token",
+			},
+			urlSuffix: "/rpc?apikey={token}",
+			method:    "eth_chainId",
+			params:    []interface{}{},
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				// Verify token is placed where the template asks for it
+				assert.Equal(t, "/rpc", r.URL.Path)
+				assert.Equal(t, "test-token", r.URL.Query().Get("apikey"))
+
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte(`{"jsonrpc":"2.0","result":"0x1"}`))
+			},
+			wantSuccess:  true,
+			wantResponse: "0x1",
+		},
+		{
+			name: "Successful HeaderAuth request with custom headers",
+			provider: rpcprovider.RpcProvider{
+				Name:       "test",
+				URL:        "", // Will be set to test server URL
+				AuthType:   rpcprovider.HeaderAuth,
+				AuthHeader: "x-api-key",
+				AuthToken:  "test-key",
+				Headers:    map[string]string{"x-client-id": "checker"},
+			},
+			method: "eth_chainId",
+			params: []interface{}{},
+			handler: func(w http.ResponseWriter, r *http.Request) {
+				// Verify token is sent in the configured header, not in the URL path
+				assert.Equal(t, "test-key", r.Header.Get("x-api-key"))
+				assert.Equal(t, "checker", r.Header.Get("x-client-id"))
+				assert.NotContains(t, r.URL.String(), "test-key")
+				assert.Equal(t, "application/json", r.Header.Get("Content-Type"))
+
+				w.WriteHeader(http.StatusOK)
+				w.Write([]byte(`{"jsonrpc":"2.0","result":"0x1"}`))
+			},
+			wantSuccess:  true,
+			wantResponse: "0x1",
+		},
 		{
 			name: "Server error response",
 			provider: rpcprovider.RpcProvider{
@@ -492,7 +572,7 @@ func TestCallEVMMethod(t *testing.T) {
 			defer server.Close()
 
 			// Update provider URL
-			tt.provider.URL = server.URL
+			tt.provider.URL = server.URL + tt.urlSuffix
 
 			// Call the method
 			runner := requestsrunner.NewRequestsRunner()
@@ -501,7 +581,7 @@ func TestCallEVMMethod(t *testing.T) {
 			// Verify results
 			assert.Equal(t, tt.wantSuccess, result.Success)
 			if tt.wantResponse != "" {
-				assert.Equal(t, tt.wantResponse, result.Response)
+				assert.Equal(t, tt.wantResponse, result.Result)
 			}
 			if tt.wantError != "" {
 				assert.Contains(t, result.Error.Error(), tt.wantError)
@@ -509,3 +589,24 @@ func TestCallEVMMethod(t *testing.T) {
 		})
 	}
 }
+
+func TestForProtocol(t *testing.T) {
+	var versions []interface{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req map[string]interface{}
+		assert.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		versions = append(versions, req["jsonrpc"])
+		w.Write([]byte(`{"result":840000,"error":null,"id":1}`))
+	}))
+	defer server.Close()
+
+	provider := rpcprovider.RpcProvider{Name: "bitcoin", URL: server.URL, AuthType: rpcprovider.NoAuth}
+	runner := requestsrunner.NewRequestsRunner()
+
+	result := runner.ForProtocol(chainprotocol.Bitcoin).CallEVMMethod(context.Background(), provider, "getblockcount", nil, time.Second)
+	assert.True(t, result.Success)
+	assert.Equal(t, "840000", result.Result)
+
+	runner.CallEVMMethod(context.Background(), provider, "eth_blockNumber", nil, time.Second)
+	assert.Equal(t, []interface{}{"1.0", "2.0"}, versions, "the original runner keeps speaking JSON-RPC 2.0")
+}
@@ -0,0 +1,161 @@
+package requestsrunner
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	rpcprovider "github.com/friofry/config-health-checker/rpcprovider"
+)
+
+const (
+	defaultMaxAttempts = 3
+	defaultBaseBackoff = 500 * time.Millisecond
+	defaultMaxBackoff  = 5 * time.Second
+)
+
+// RetryPolicy configures how RequestsRunner retries failed requests
+type RetryPolicy struct {
+	MaxAttempts    int           // Total attempts per request, including the first one
+	BaseBackoff    time.Duration // Wait before the first retry, doubled for every next retry
+	MaxBackoff     time.Duration // Upper bound for a computed backoff
+	RetryOn        []ErrorClass  // Error classes that are retried, nil means HTTP 429 only
+	MethodDeadline time.Duration // Overall deadline for all attempts and backoffs of one method call, zero means the call timeout for every attempt
+}
+
+// Attempt describes a single try of a request
+type Attempt struct {
+	Number     int           // Attempt number, starting from 1
+	Latency    time.Duration // Duration of the attempt, excluding backoff
+	Error      error         // Error of the attempt, nil if it succeeded
+	ErrorClass ErrorClass    // Class of the attempt error
+}
+
+// DefaultRetryPolicy returns the retry policy used by NewRequestsRunner
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		MaxAttempts: defaultMaxAttempts,
+		BaseBackoff: defaultBaseBackoff,
+		MaxBackoff:  defaultMaxBackoff,
+		RetryOn:     []ErrorClass{ErrorClassRateLimited},
+	}
+}
+
+// shouldRetry reports whether errors of the class are retried
+func (p RetryPolicy) shouldRetry(class ErrorClass) bool {
+	for _, retryable := range p.RetryOn {
+		if retryable == class {
+			return true
+		}
+	}
+	return false
+}
+
+// backoff returns the wait before the given retry (1 for the first retry)
+func (p RetryPolicy) backoff(retry int) time.Duration {
+	wait := p.BaseBackoff
+	for i := 1; i < retry && wait < p.MaxBackoff; i++ {
+		wait *= 2
+	}
+	if p.MaxBackoff > 0 && wait > p.MaxBackoff {
+		wait = p.MaxBackoff
+	}
+	return wait
+}
+
+// withDeadline bounds ctx by the method deadline of the policy, which covers all attempts of a call.
+// Without a method deadline, every attempt may take the call timeout.
+func (p RetryPolicy) withDeadline(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	deadline := p.callDeadline(timeout)
+	if deadline <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, deadline)
+}
+
+// callDeadline returns the time all attempts of a call may take: the method deadline,
+// or the call timeout for every attempt without one
+func (p RetryPolicy) callDeadline(timeout time.Duration) time.Duration {
+	if p.MethodDeadline > 0 {
+		return p.MethodDeadline
+	}
+	if p.MaxAttempts > 0 {
+		return timeout * time.Duration(p.MaxAttempts)
+	}
+	return timeout
+}
+
+// CallDeadline returns the time a call with the given per-attempt timeout may take, including retries and backoffs.
+// Implements the DeadlineCaller interface
+func (r *RequestsRunner) CallDeadline(timeout time.Duration) time.Duration {
+	return r.retryPolicy.callDeadline(timeout)
+}
+
+// withAttemptTimeout bounds a single attempt by the call timeout
+func withAttemptTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+	return context.WithTimeout(ctx, timeout)
+}
+
+// withRetry runs attempt until it succeeds, fails with an error that should not be retried,
+// runs out of attempts, or the next wait would not fit in the context deadline.
+// Every attempt gets its own timeout, so that a timed out attempt can be retried within the method deadline.
+// Every attempt is returned; throttled attempts are also recorded for the provider.
+func (r *RequestsRunner) withRetry(
+	ctx context.Context,
+	provider rpcprovider.RpcProvider,
+	timeout time.Duration,
+	attempt func(ctx context.Context) error,
+) ([]Attempt, error) {
+	var attempts []Attempt
+	for try := 1; ; try++ {
+		startTime := time.Now()
+		attemptCtx, cancel := withAttemptTimeout(ctx, timeout)
+		err := attempt(attemptCtx)
+		cancel()
+		class := ClassifyError(err)
+		attempts = append(attempts, Attempt{
+			Number:     try,
+			Latency:    time.Since(startTime),
+			Error:      err,
+			ErrorClass: class,
+		})
+		if err == nil {
+			return attempts, nil
+		}
+
+		var (
+			retryAfter  time.Duration
+			providerErr *ProviderError
+		)
+		if errors.As(err, &providerErr) {
+			retryAfter = providerErr.RetryAfter
+		}
+		if class == ErrorClassRateLimited {
+			r.throttles.record(ProviderKey(provider), retryAfter)
+		}
+
+		if !r.retryPolicy.shouldRetry(class) || try >= r.retryPolicy.MaxAttempts {
+			return attempts, err
+		}
+
+		// Honor Retry-After when the provider asks to wait longer than our own backoff
+		wait := r.retryPolicy.backoff(try)
+		if retryAfter > wait {
+			wait = retryAfter
+		}
+		if deadline, ok := ctx.Deadline(); ok && time.Now().Add(wait).After(deadline) {
+			return attempts, err
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return attempts, err
+		}
+	}
+}
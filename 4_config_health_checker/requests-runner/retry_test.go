@@ -0,0 +1,229 @@
+package requestsrunner_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	requestsrunner "github.com/friofry/config-health-checker/requests-runner"
+	"github.com/friofry/config-health-checker/rpcprovider"
+)
+
+// newThrottlingServer returns a server that answers 429 for the first throttled requests and succeeds afterwards
+func newThrottlingServer(throttled int32, retryAfter string, calls *int32) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(calls, 1) <= throttled {
+			if retryAfter != "" {
+				w.Header().Set("Retry-After", retryAfter)
+			}
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x1"}`))
+	}))
+}
+
+func TestCallEVMMethodRateLimitRetry(t *testing.T) {
+	policy := requestsrunner.RetryPolicy{
+		MaxAttempts: 3,
+		BaseBackoff: 5 * time.Millisecond,
+		MaxBackoff:  20 * time.Millisecond,
+	}
+
+	t.Run("Recovers after throttling", func(t *testing.T) {
+		var calls int32
+		server := newThrottlingServer(2, "0", &calls)
+		defer server.Close()
+
+		runner := requestsrunner.NewRequestsRunnerWithRetryPolicy(policy)
+		provider := rpcprovider.RpcProvider{Name: "throttled", URL: server.URL, AuthType: rpcprovider.NoAuth}
+		result := runner.CallEVMMethod(context.Background(), provider, "eth_blockNumber", nil, time.Second)
+
+		assert.True(t, result.Success)
+		assert.Equal(t, "0x1", result.Result)
+		assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+		assert.Equal(t, 2, runner.ThrottleStats()[requestsrunner.ProviderKey(provider)].Events)
+	})
+
+	t.Run("Gives up after max attempts", func(t *testing.T) {
+		var calls int32
+		server := newThrottlingServer(100, "", &calls)
+		defer server.Close()
+
+		runner := requestsrunner.NewRequestsRunnerWithRetryPolicy(policy)
+		provider := rpcprovider.RpcProvider{Name: "throttled", URL: server.URL, AuthType: rpcprovider.NoAuth}
+		result := runner.CallEVMMethod(context.Background(), provider, "eth_blockNumber", nil, time.Second)
+
+		assert.False(t, result.Success)
+		assert.Equal(t, requestsrunner.ErrorClassRateLimited, result.ErrorClass())
+		assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+		assert.Equal(t, 3, runner.ThrottleStats()[requestsrunner.ProviderKey(provider)].Events)
+	})
+
+	t.Run("Retry-After beyond budget is not waited for", func(t *testing.T) {
+		var calls int32
+		server := newThrottlingServer(100, "30", &calls)
+		defer server.Close()
+
+		runner := requestsrunner.NewRequestsRunnerWithRetryPolicy(policy)
+		provider := rpcprovider.RpcProvider{Name: "throttled", URL: server.URL, AuthType: rpcprovider.NoAuth}
+		result := runner.CallEVMMethod(context.Background(), provider, "eth_blockNumber", nil, 200*time.Millisecond)
+
+		assert.False(t, result.Success)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+		assert.Less(t, result.ElapsedTime, 200*time.Millisecond)
+		stats := runner.ThrottleStats()[requestsrunner.ProviderKey(provider)]
+		assert.Equal(t, 1, stats.Events)
+		assert.Equal(t, 30*time.Second, stats.LastRetryAfter)
+	})
+
+	t.Run("Retry-After longer than the attempt timeout is honored in parallel calls", func(t *testing.T) {
+		var calls int32
+		server := newThrottlingServer(1, "1", &calls)
+		defer server.Close()
+
+		runner := requestsrunner.NewRequestsRunnerWithRetryPolicy(requestsrunner.RetryPolicy{
+			MaxAttempts:    2,
+			BaseBackoff:    5 * time.Millisecond,
+			MaxBackoff:     20 * time.Millisecond,
+			MethodDeadline: 3 * time.Second,
+		})
+		provider := rpcprovider.RpcProvider{Name: "throttled", URL: server.URL, AuthType: rpcprovider.NoAuth}
+		results := requestsrunner.ParallelCallEVMMethods(context.Background(), []rpcprovider.RpcProvider{provider},
+			"eth_blockNumber", nil, 200*time.Millisecond, runner)
+
+		result := results[provider.Name]
+		assert.True(t, result.Success)
+		assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+		assert.GreaterOrEqual(t, result.ElapsedTime, time.Second)
+	})
+
+	t.Run("Throttling is tracked per chain", func(t *testing.T) {
+		var calls int32
+		server := newThrottlingServer(1, "0", &calls)
+		defer server.Close()
+
+		runner := requestsrunner.NewRequestsRunnerWithRetryPolicy(policy)
+		mainnet := rpcprovider.RpcProvider{Name: "infura", URL: server.URL + "/mainnet", AuthType: rpcprovider.NoAuth}
+		sepolia := rpcprovider.RpcProvider{Name: "infura", URL: server.URL + "/sepolia", AuthType: rpcprovider.NoAuth}
+		runner.CallEVMMethod(context.Background(), mainnet, "eth_blockNumber", nil, time.Second)
+		runner.CallEVMMethod(context.Background(), sepolia, "eth_blockNumber", nil, time.Second)
+
+		stats := runner.ThrottleStats()
+		assert.Equal(t, 1, stats[requestsrunner.ProviderKey(mainnet)].Events)
+		assert.NotContains(t, stats, requestsrunner.ProviderKey(sepolia))
+	})
+
+	t.Run("Other errors are not retried", func(t *testing.T) {
+		var calls int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&calls, 1)
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer server.Close()
+
+		runner := requestsrunner.NewRequestsRunnerWithRetryPolicy(policy)
+		provider := rpcprovider.RpcProvider{Name: "broken", URL: server.URL, AuthType: rpcprovider.NoAuth}
+		result := runner.CallEVMMethod(context.Background(), provider, "eth_blockNumber", nil, time.Second)
+
+		assert.False(t, result.Success)
+		assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+		assert.Empty(t, runner.ThrottleStats())
+	})
+}
+
+func TestCallEVMMethodRetryPolicy(t *testing.T) {
+	t.Run("Configured classes are retried and attempts recorded", func(t *testing.T) {
+		var calls int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&calls, 1) == 1 {
+				w.WriteHeader(http.StatusServiceUnavailable)
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x1"}`))
+		}))
+		defer server.Close()
+
+		runner := requestsrunner.NewRequestsRunnerWithRetryPolicy(requestsrunner.RetryPolicy{
+			MaxAttempts: 3,
+			BaseBackoff: time.Millisecond,
+			MaxBackoff:  time.Millisecond,
+			RetryOn:     []requestsrunner.ErrorClass{requestsrunner.ErrorClassServerError},
+		})
+		provider := rpcprovider.RpcProvider{Name: "flaky", URL: server.URL, AuthType: rpcprovider.NoAuth}
+		result := runner.CallEVMMethod(context.Background(), provider, "eth_blockNumber", nil, time.Second)
+
+		assert.True(t, result.Success)
+		if assert.Len(t, result.Attempts, 2) {
+			assert.Equal(t, 1, result.Attempts[0].Number)
+			assert.Equal(t, requestsrunner.ErrorClassServerError, result.Attempts[0].ErrorClass)
+			assert.Error(t, result.Attempts[0].Error)
+			assert.Greater(t, result.Attempts[0].Latency, time.Duration(0))
+			assert.Equal(t, 2, result.Attempts[1].Number)
+			assert.NoError(t, result.Attempts[1].Error)
+		}
+	})
+
+	t.Run("Timed out attempts are retried", func(t *testing.T) {
+		var calls int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if atomic.AddInt32(&calls, 1) == 1 {
+				select {
+				case <-r.Context().Done():
+				case <-time.After(time.Second):
+				}
+				return
+			}
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte(`{"jsonrpc":"2.0","id":1,"result":"0x1"}`))
+		}))
+		defer server.Close()
+
+		runner := requestsrunner.NewRequestsRunnerWithRetryPolicy(requestsrunner.RetryPolicy{
+			MaxAttempts:    3,
+			BaseBackoff:    time.Millisecond,
+			MaxBackoff:     time.Millisecond,
+			RetryOn:        []requestsrunner.ErrorClass{requestsrunner.ErrorClassTimeout},
+			MethodDeadline: time.Second,
+		})
+		provider := rpcprovider.RpcProvider{Name: "slow", URL: server.URL, AuthType: rpcprovider.NoAuth}
+		result := runner.CallEVMMethod(context.Background(), provider, "eth_blockNumber", nil, 50*time.Millisecond)
+
+		assert.True(t, result.Success)
+		if assert.Len(t, result.Attempts, 2) {
+			assert.Equal(t, requestsrunner.ErrorClassTimeout, result.Attempts[0].ErrorClass)
+			assert.NoError(t, result.Attempts[1].Error)
+		}
+	})
+
+	t.Run("Method deadline bounds all attempts", func(t *testing.T) {
+		var calls int32
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&calls, 1)
+			w.WriteHeader(http.StatusBadGateway)
+		}))
+		defer server.Close()
+
+		runner := requestsrunner.NewRequestsRunnerWithRetryPolicy(requestsrunner.RetryPolicy{
+			MaxAttempts:    10,
+			BaseBackoff:    40 * time.Millisecond,
+			MaxBackoff:     40 * time.Millisecond,
+			RetryOn:        []requestsrunner.ErrorClass{requestsrunner.ErrorClassServerError},
+			MethodDeadline: 100 * time.Millisecond,
+		})
+		provider := rpcprovider.RpcProvider{Name: "down", URL: server.URL, AuthType: rpcprovider.NoAuth}
+		result := runner.CallEVMMethod(context.Background(), provider, "eth_blockNumber", nil, time.Second)
+
+		assert.False(t, result.Success)
+		assert.Less(t, result.ElapsedTime, 150*time.Millisecond)
+		assert.Less(t, atomic.LoadInt32(&calls), int32(10))
+		assert.Len(t, result.Attempts, int(atomic.LoadInt32(&calls)))
+	})
+}
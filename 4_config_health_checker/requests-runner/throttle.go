@@ -0,0 +1,54 @@
+package requestsrunner
+
+import (
+	"sync"
+	"time"
+)
+
+// ThrottleStats contains rate-limit events observed for a provider
+type ThrottleStats struct {
+	Events         int           `json:"events"`         // Number of HTTP 429 responses received
+	LastEvent      time.Time     `json:"lastEvent"`      // Time of the last HTTP 429 response
+	LastRetryAfter time.Duration `json:"lastRetryAfter"` // Retry-After requested by the last HTTP 429 response
+}
+
+// throttleTracker records rate-limit events per provider, keyed by ProviderKey
+type throttleTracker struct {
+	mu    sync.Mutex
+	stats map[string]ThrottleStats
+}
+
+func newThrottleTracker() *throttleTracker {
+	return &throttleTracker{
+		stats: make(map[string]ThrottleStats),
+	}
+}
+
+// record registers a rate-limit event for the provider
+func (t *throttleTracker) record(provider string, retryAfter time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	stats := t.stats[provider]
+	stats.Events++
+	stats.LastEvent = time.Now()
+	stats.LastRetryAfter = retryAfter
+	t.stats[provider] = stats
+}
+
+// snapshot returns a copy of the recorded stats
+func (t *throttleTracker) snapshot() map[string]ThrottleStats {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	snapshot := make(map[string]ThrottleStats, len(t.stats))
+	for name, stats := range t.stats {
+		snapshot[name] = stats
+	}
+	return snapshot
+}
+
+// ThrottleStats returns rate-limit events observed so far, keyed by ProviderKey
+func (r *RequestsRunner) ThrottleStats() map[string]ThrottleStats {
+	return r.throttles.snapshot()
+}
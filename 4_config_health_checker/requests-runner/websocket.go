@@ -0,0 +1,284 @@
+package requestsrunner
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	rpcprovider "github.com/friofry/config-health-checker/rpcprovider"
+	"golang.org/x/net/websocket"
+)
+
+const (
+	newHeadsSubscription    = "newHeads"
+	subscriptionNotifyEvent = "eth_subscription"
+)
+
+// ErrSubscriptionsNotSupported is returned when a subscription is requested for a provider without a websocket URL
+var ErrSubscriptionsNotSupported = errors.New("subscriptions require a ws:// or wss:// provider URL")
+
+// HeadsSubscriber defines the interface for checking newHeads subscriptions of websocket providers
+type HeadsSubscriber interface {
+	SubscribeNewHeads(
+		ctx context.Context,
+		provider rpcprovider.RpcProvider,
+		timeout time.Duration,
+	) HeadResult
+}
+
+// HeadResult contains the first head received from a newHeads subscription
+type HeadResult struct {
+	Success     bool            // Indicates if a head arrived in time
+	Error       error           // Error if the subscription failed
+	Number      string          // Block number of the head, hex encoded
+	Header      json.RawMessage // Raw head as sent by the provider
+	ElapsedTime time.Duration   // Time from the confirmed subscription until the first head
+}
+
+// wsMessage contains the fields needed to tell responses and subscription notifications apart
+type wsMessage struct {
+	Method string `json:"method"`
+	Params struct {
+		Subscription string          `json:"subscription"`
+		Result       json.RawMessage `json:"result"`
+	} `json:"params"`
+}
+
+// wsConn is a websocket connection that is closed when its context is done
+type wsConn struct {
+	*websocket.Conn
+	ctx  context.Context
+	stop func() bool
+}
+
+// dialWebSocket opens a websocket connection to the provider, sending its auth and custom headers with the handshake
+func dialWebSocket(ctx context.Context, provider rpcprovider.RpcProvider) (*wsConn, error) {
+	requestURL, err := provider.RequestURL()
+	if err != nil {
+		return nil, fmt.Errorf("failed to build request URL: %w", err)
+	}
+	origin := "http" + strings.TrimPrefix(strings.ToLower(requestURL), "ws")
+	config, err := websocket.NewConfig(requestURL, origin)
+	if err != nil {
+		return nil, fmt.Errorf("invalid websocket URL %s: %w", provider.RedactedURL(), err)
+	}
+
+	for name, value := range provider.Headers {
+		config.Header.Set(name, value)
+	}
+	switch provider.AuthType {
+	case rpcprovider.BasicAuth:
+		credentials := base64.StdEncoding.EncodeToString([]byte(provider.AuthLogin + ":" + provider.AuthPassword))
+		config.Header.Set("Authorization", "Basic "+credentials)
+	case rpcprovider.HeaderAuth:
+		config.Header.Set(provider.AuthHeader, provider.AuthToken)
+	}
+
+	host := config.Location.Host
+	if config.Location.Port() == "" {
+		port := "80"
+		if config.Location.Scheme == "wss" {
+			port = "443"
+		}
+		host = net.JoinHostPort(config.Location.Hostname(), port)
+	}
+
+	var netConn net.Conn
+	if config.Location.Scheme == "wss" {
+		dialer := &tls.Dialer{Config: &tls.Config{ServerName: config.Location.Hostname()}}
+		netConn, err = dialer.DialContext(ctx, "tcp", host)
+	} else {
+		dialer := &net.Dialer{}
+		netConn, err = dialer.DialContext(ctx, "tcp", host)
+	}
+	if err != nil {
+		return nil, newTransportError(err)
+	}
+
+	// Bound the handshake and all reads by the context
+	if deadline, ok := ctx.Deadline(); ok {
+		netConn.SetDeadline(deadline)
+	}
+	stop := context.AfterFunc(ctx, func() { netConn.Close() })
+
+	conn, err := websocket.NewClient(config, netConn)
+	if err != nil {
+		stop()
+		netConn.Close()
+		return nil, webSocketError(ctx, err)
+	}
+	return &wsConn{Conn: conn, ctx: ctx, stop: stop}, nil
+}
+
+// close closes the connection
+func (c *wsConn) close() {
+	c.stop()
+	c.Conn.Close()
+}
+
+// write sends a text message
+func (c *wsConn) write(payload []byte) error {
+	if err := websocket.Message.Send(c.Conn, string(payload)); err != nil {
+		return webSocketError(c.ctx, err)
+	}
+	return nil
+}
+
+// read receives the next message
+func (c *wsConn) read() ([]byte, error) {
+	var msg []byte
+	if err := websocket.Message.Receive(c.Conn, &msg); err != nil {
+		return nil, webSocketError(c.ctx, err)
+	}
+	return msg, nil
+}
+
+// webSocketError classifies a websocket failure, preferring the context error when the context is done
+func webSocketError(ctx context.Context, err error) error {
+	if ctx.Err() != nil {
+		return newTransportError(ctx.Err())
+	}
+	if errors.Is(err, websocket.ErrBadStatus) {
+		return &ProviderError{
+			Class: ErrorClassHTTPStatus,
+			Err:   fmt.Errorf("websocket handshake failed: %w", err),
+		}
+	}
+	return newTransportError(err)
+}
+
+// sendWebSocket sends a JSON payload over a new websocket connection and returns the response.
+// Subscription notifications received before the response are skipped.
+func sendWebSocket(ctx context.Context, provider rpcprovider.RpcProvider, payload []byte) ([]byte, error) {
+	conn, err := dialWebSocket(ctx, provider)
+	if err != nil {
+		return nil, err
+	}
+	defer conn.close()
+
+	if err := conn.write(payload); err != nil {
+		return nil, err
+	}
+	for {
+		msg, err := conn.read()
+		if err != nil {
+			return nil, err
+		}
+		// Batch responses are arrays, single responses carry an id
+		if trimmed := bytes.TrimSpace(msg); len(trimmed) > 0 && trimmed[0] == '[' {
+			return msg, nil
+		}
+		var message wsMessage
+		if err := json.Unmarshal(msg, &message); err != nil || message.Method != subscriptionNotifyEvent {
+			return msg, nil
+		}
+	}
+}
+
+// SubscribeNewHeads subscribes to newHeads on a websocket provider and waits for the first head
+func (r *RequestsRunner) SubscribeNewHeads(
+	ctx context.Context,
+	provider rpcprovider.RpcProvider,
+	timeout time.Duration,
+) HeadResult {
+	if !provider.IsWebSocket() {
+		return HeadResult{Error: ErrSubscriptionsNotSupported}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	if err := r.guard(ctx, provider, timeout); err != nil {
+		return HeadResult{Error: err}
+	}
+	result := subscribeNewHeads(ctx, provider)
+	r.recordOutcome(provider, result.Error)
+	return result
+}
+
+// subscribeNewHeads performs the newHeads subscription and waits for the first head
+func subscribeNewHeads(ctx context.Context, provider rpcprovider.RpcProvider) HeadResult {
+	conn, err := dialWebSocket(ctx, provider)
+	if err != nil {
+		return HeadResult{Error: err}
+	}
+	defer conn.close()
+
+	subscribe, err := json.Marshal(jsonRPCRequest{
+		JSONRPC: "2.0",
+		Method:  "eth_subscribe",
+		Params:  []interface{}{newHeadsSubscription},
+		ID:      1,
+	})
+	if err != nil {
+		return HeadResult{Error: fmt.Errorf("failed to marshal subscription request: %w", err)}
+	}
+	if err := conn.write(subscribe); err != nil {
+		return HeadResult{Error: err}
+	}
+
+	var subscriptionID string
+	startTime := time.Now()
+	for {
+		msg, err := conn.read()
+		if err != nil {
+			return HeadResult{Error: err}
+		}
+
+		var message wsMessage
+		if err := json.Unmarshal(msg, &message); err != nil {
+			return HeadResult{Error: &ProviderError{
+				Class: ErrorClassMalformedJSON,
+				Err:   fmt.Errorf("failed to parse websocket message: %w", err),
+			}}
+		}
+
+		if message.Method != subscriptionNotifyEvent {
+			// Subscription confirmation
+			response := parseResponse(msg)
+			if response.Error != nil {
+				return HeadResult{Error: response.Error}
+			}
+			subscriptionID = response.Result
+			startTime = time.Now()
+			continue
+		}
+		if subscriptionID == "" || message.Params.Subscription != subscriptionID {
+			continue
+		}
+
+		var head struct {
+			Number string `json:"number"`
+		}
+		if err := json.Unmarshal(message.Params.Result, &head); err != nil || head.Number == "" {
+			return HeadResult{Error: &ProviderError{
+				Class: ErrorClassMalformedJSON,
+				Err:   errors.New("newHeads notification without block number"),
+			}, Header: message.Params.Result}
+		}
+
+		// Best effort, the connection is closed anyway
+		if unsubscribe, err := json.Marshal(jsonRPCRequest{
+			JSONRPC: "2.0",
+			Method:  "eth_unsubscribe",
+			Params:  []interface{}{subscriptionID},
+			ID:      2,
+		}); err == nil {
+			conn.write(unsubscribe)
+		}
+
+		return HeadResult{
+			Success:     true,
+			Number:      head.Number,
+			Header:      message.Params.Result,
+			ElapsedTime: time.Since(startTime),
+		}
+	}
+}
@@ -0,0 +1,117 @@
+package requestsrunner_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/net/websocket"
+
+	requestsrunner "github.com/friofry/config-health-checker/requests-runner"
+	"github.com/friofry/config-health-checker/rpcprovider"
+)
+
+// newWebSocketServer starts a websocket server and returns a ws:// URL for it
+func newWebSocketServer(t *testing.T, handler func(ws *websocket.Conn)) string {
+	server := httptest.NewServer(websocket.Handler(handler))
+	t.Cleanup(server.Close)
+	return "ws" + strings.TrimPrefix(server.URL, "http")
+}
+
+func TestCallEVMMethodWebSocket(t *testing.T) {
+	url := newWebSocketServer(t, func(ws *websocket.Conn) {
+		assert.Equal(t, "test-key", ws.Request().Header.Get("x-api-key"))
+
+		var req map[string]interface{}
+		require.NoError(t, websocket.JSON.Receive(ws, &req))
+		assert.Equal(t, "eth_blockNumber", req["method"])
+
+		// A stray notification must be skipped
+		websocket.Message.Send(ws, `{"jsonrpc":"2.0","method":"eth_subscription","params":{"subscription":"0x1","result":{}}}`)
+		websocket.Message.Send(ws, `{"jsonrpc":"2.0","id":1,"result":"0x10"}`)
+	})
+
+	provider := rpcprovider.RpcProvider{
+		Name:       "ws",
+		URL:        url,
+		AuthType:   rpcprovider.HeaderAuth,
+		AuthHeader: "x-api-key",
+		AuthToken:  "test-key",
+	}
+	result := requestsrunner.NewRequestsRunner().CallEVMMethod(context.Background(), provider, "eth_blockNumber", nil, time.Second)
+
+	require.NoError(t, result.Error)
+	assert.True(t, result.Success)
+	assert.Equal(t, "0x10", result.Result)
+}
+
+func TestSubscribeNewHeads(t *testing.T) {
+	t.Run("First head arrives", func(t *testing.T) {
+		url := newWebSocketServer(t, func(ws *websocket.Conn) {
+			var req map[string]interface{}
+			require.NoError(t, websocket.JSON.Receive(ws, &req))
+			assert.Equal(t, "eth_subscribe", req["method"])
+			assert.Equal(t, []interface{}{"newHeads"}, req["params"])
+
+			websocket.Message.Send(ws, `{"jsonrpc":"2.0","id":1,"result":"0xabc"}`)
+			// Notifications of other subscriptions are ignored
+			websocket.Message.Send(ws, `{"jsonrpc":"2.0","method":"eth_subscription","params":{"subscription":"0xother","result":{"number":"0x1"}}}`)
+			websocket.Message.Send(ws, `{"jsonrpc":"2.0","method":"eth_subscription","params":{"subscription":"0xabc","result":{"number":"0x64","hash":"0x01"}}}`)
+
+			// Wait for the unsubscribe request
+			var unsubscribe map[string]interface{}
+			websocket.JSON.Receive(ws, &unsubscribe)
+			assert.Equal(t, "eth_unsubscribe", unsubscribe["method"])
+		})
+
+		provider := rpcprovider.RpcProvider{Name: "ws", URL: url, AuthType: rpcprovider.NoAuth}
+		head := requestsrunner.NewRequestsRunner().SubscribeNewHeads(context.Background(), provider, time.Second)
+
+		require.NoError(t, head.Error)
+		assert.True(t, head.Success)
+		assert.Equal(t, "0x64", head.Number)
+		var header map[string]string
+		require.NoError(t, json.Unmarshal(head.Header, &header))
+		assert.Equal(t, "0x01", header["hash"])
+	})
+
+	t.Run("No head before the deadline", func(t *testing.T) {
+		url := newWebSocketServer(t, func(ws *websocket.Conn) {
+			var req map[string]interface{}
+			websocket.JSON.Receive(ws, &req)
+			websocket.Message.Send(ws, `{"jsonrpc":"2.0","id":1,"result":"0xabc"}`)
+			time.Sleep(200 * time.Millisecond)
+		})
+
+		provider := rpcprovider.RpcProvider{Name: "ws", URL: url, AuthType: rpcprovider.NoAuth}
+		head := requestsrunner.NewRequestsRunner().SubscribeNewHeads(context.Background(), provider, 50*time.Millisecond)
+
+		assert.False(t, head.Success)
+		assert.Equal(t, requestsrunner.ErrorClassTimeout, requestsrunner.ClassifyError(head.Error))
+	})
+
+	t.Run("Subscription rejected", func(t *testing.T) {
+		url := newWebSocketServer(t, func(ws *websocket.Conn) {
+			var req map[string]interface{}
+			websocket.JSON.Receive(ws, &req)
+			websocket.Message.Send(ws, `{"jsonrpc":"2.0","id":1,"error":{"code":-32601,"message":"subscriptions not supported"}}`)
+		})
+
+		provider := rpcprovider.RpcProvider{Name: "ws", URL: url, AuthType: rpcprovider.NoAuth}
+		head := requestsrunner.NewRequestsRunner().SubscribeNewHeads(context.Background(), provider, time.Second)
+
+		assert.False(t, head.Success)
+		assert.Equal(t, requestsrunner.ErrorClassRPCError, requestsrunner.ClassifyError(head.Error))
+	})
+
+	t.Run("HTTP provider", func(t *testing.T) {
+		provider := rpcprovider.RpcProvider{Name: "http", URL: "https://rpc.example.io", AuthType: rpcprovider.NoAuth}
+		head := requestsrunner.NewRequestsRunner().SubscribeNewHeads(context.Background(), provider, time.Second)
+		assert.ErrorIs(t, head.Error, requestsrunner.ErrSubscriptionsNotSupported)
+	})
+}
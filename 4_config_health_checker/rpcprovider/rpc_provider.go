@@ -1,22 +1,113 @@
 package rpcprovider
 
+import (
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+const (
+	TokenPlaceholder = "{token}" // Marks where the token of a TokenAuth provider is placed in its URL
+	DefaultWeight    = 1         // Weight of providers without an explicit weight
+)
+
 // RpcProviderAuthType defines various authentication types for RPC providers
 type RpcProviderAuthType string
 
 const (
-	NoAuth    RpcProviderAuthType = "no-auth"    // No authentication
-	BasicAuth RpcProviderAuthType = "basic-auth" // HTTP Header "Authorization: Basic base64(username:password)"
-	TokenAuth RpcProviderAuthType = "token-auth" // URL Token-based authentication "https://api.example.com/YOUR_TOKEN"
+	NoAuth     RpcProviderAuthType = "no-auth"     // No authentication
+	BasicAuth  RpcProviderAuthType = "basic-auth"  // HTTP Header "Authorization: Basic base64(username:password)"
+	TokenAuth  RpcProviderAuthType = "token-auth"  // URL Token-based authentication "https://api.example.com/YOUR_TOKEN" or a URL template with {token}
+	HeaderAuth RpcProviderAuthType = "header-auth" // HTTP Header "<AuthHeader>: <AuthToken>", e.g. "Authorization: Bearer TOKEN" or "x-api-key: KEY"
 )
 
 // RpcProvider represents the configuration of an RPC provider with various options
 type RpcProvider struct {
-	Name         string              `json:"name" validate:"required,min=1"`                                          // Provider name for identification
-	URL          string              `json:"url" validate:"required,url"`                                             // URL of the current provider
-	AuthType     RpcProviderAuthType `json:"authType" validate:"required,oneof=no-auth basic-auth token-auth"`        // Authentication type
-	AuthLogin    string              `json:"authLogin" validate:"required_if=AuthType basic-auth,omitempty,min=1"`    // Login for BasicAuth
-	AuthPassword string              `json:"authPassword" validate:"required_if=AuthType basic-auth,omitempty,min=1"` // Password for BasicAuth
-	AuthToken    string              `json:"authToken" validate:"required_if=AuthType token-auth,omitempty,min=1"`    // Token for TokenAuth
+	Name         string              `json:"name" validate:"required,min=1"`                                                                             // Provider name for identification
+	URL          string              `json:"url" validate:"required,url"`                                                                                // URL of the current provider, may contain {token} placeholders for TokenAuth
+	AuthType     RpcProviderAuthType `json:"authType" validate:"required,oneof=no-auth basic-auth token-auth header-auth"`                               // Authentication type
+	AuthLogin    string              `json:"authLogin" validate:"required_if=AuthType basic-auth,omitempty,min=1"`                                       // Login for BasicAuth
+	AuthPassword string              `json:"authPassword" validate:"required_if=AuthType basic-auth,omitempty,min=1"`                                    // Password for BasicAuth
+	AuthToken    string              `json:"authToken" validate:"required_if=AuthType token-auth,required_if=AuthType header-auth,omitempty,min=1"`      // Token for TokenAuth, header value for HeaderAuth
+	AuthHeader   string              `json:"authHeader,omitempty" validate:"required_if=AuthType header-auth,omitempty,min=1,printascii,excludesall=: "` // Header name for HeaderAuth
+	Headers      map[string]string   `json:"headers,omitempty" validate:"omitempty,dive,keys,required,printascii,excludesall=: ,endkeys,required"`       // Extra HTTP headers sent with every request
+	Enabled      *bool               `json:"enabled,omitempty"`                                                                                          // Disabled providers are neither checked nor served, defaults to true
+	Weight       int                 `json:"weight,omitempty" validate:"gte=0"`                                                                          // Relative share of requests among providers of the same priority, defaults to DefaultWeight
+	Priority     int                 `json:"priority,omitempty" validate:"gte=0"`                                                                        // Providers with lower values are tried first
+	Tags         []string            `json:"tags,omitempty" validate:"omitempty,dive,required"`                                                          // Free-form labels, e.g. "archive" or "free-tier"
+}
+
+// IsEnabled reports whether the provider is enabled, providers without the field are enabled
+func (p RpcProvider) IsEnabled() bool {
+	return p.Enabled == nil || *p.Enabled
+}
+
+// EffectiveWeight returns the weight of the provider, DefaultWeight if it is not set
+func (p RpcProvider) EffectiveWeight() int {
+	if p.Weight == 0 {
+		return DefaultWeight
+	}
+	return p.Weight
+}
+
+// EnabledProviders returns the enabled providers, keeping their order
+func EnabledProviders(providers []RpcProvider) []RpcProvider {
+	enabled := make([]RpcProvider, 0, len(providers))
+	for _, provider := range providers {
+		if provider.IsEnabled() {
+			enabled = append(enabled, provider)
+		}
+	}
+	return enabled
+}
+
+// HTTPProviders returns the providers reached over HTTP, keeping their order.
+// The proxy sends plain HTTP requests, so websocket providers are checked but never served.
+func HTTPProviders(providers []RpcProvider) []RpcProvider {
+	httpProviders := make([]RpcProvider, 0, len(providers))
+	for _, provider := range providers {
+		if !provider.IsWebSocket() {
+			httpProviders = append(httpProviders, provider)
+		}
+	}
+	return httpProviders
+}
+
+// SortByPriority orders providers by ascending priority, keeping the order of providers with equal priority
+func SortByPriority(providers []RpcProvider) {
+	sort.SliceStable(providers, func(i, j int) bool {
+		return providers[i].Priority < providers[j].Priority
+	})
 }
 
-// method unmarshal json and validate field "Enabled" exists
+// RequestURL returns the URL requests to the provider are sent to.
+// For TokenAuth every {token} placeholder in the URL is replaced with the escaped token;
+// without placeholders the token is appended to the URL path.
+func (p RpcProvider) RequestURL() (string, error) {
+	if p.AuthType != TokenAuth {
+		return p.URL, nil
+	}
+
+	if strings.Contains(p.URL, TokenPlaceholder) {
+		base, query, hasQuery := strings.Cut(p.URL, "?")
+		result := strings.ReplaceAll(base, TokenPlaceholder, url.PathEscape(p.AuthToken))
+		if hasQuery {
+			result += "?" + strings.ReplaceAll(query, TokenPlaceholder, url.QueryEscape(p.AuthToken))
+		}
+		return result, nil
+	}
+
+	u, err := url.Parse(p.URL)
+	if err != nil {
+		return "", fmt.Errorf("invalid provider URL: %w", err)
+	}
+	u.Path += "/" + p.AuthToken
+	return u.String(), nil
+}
+
+// IsWebSocket reports whether the provider is reached over a websocket (ws:// or wss:// URL)
+func (p RpcProvider) IsWebSocket() bool {
+	lower := strings.ToLower(p.URL)
+	return strings.HasPrefix(lower, "ws://") || strings.HasPrefix(lower, "wss://")
+}
@@ -0,0 +1,75 @@
+package rpcprovider
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestURL(t *testing.T) {
+	tests := []struct {
+		name     string
+		provider RpcProvider
+		want     string
+	}{
+		{
+			name:     "No auth keeps URL",
+			provider: RpcProvider{URL: "https://rpc.example.io/v1", AuthType: NoAuth},
+			want:     "https://rpc.example.io/v1",
+		},
+		{
+			name:     "Token appended to path",
+			provider: RpcProvider{URL: "https://mainnet.infura.io/v3", AuthType: TokenAuth, AuthToken: "abc"},
+			want:     "https://mainnet.infura.io/v3/abc",
+		},
+		{
+			name:     "Token appended before query",
+			provider: RpcProvider{URL: "https://rpc.example.io/v2?network=main", AuthType: TokenAuth, AuthToken: "abc"},
+			want:     "https://rpc.example.io/v2/abc?network=main",
+		},
+		{
+			name:     "Token in query parameter",
+			provider: RpcProvider{URL: "https://rpc.example.io/rpc?apikey={token}", AuthType: TokenAuth, AuthToken: "a+b/c"},
+			want:     "https://rpc.example.io/rpc?apikey=a%2Bb%2Fc",
+		},
+		{
+			name:     "Token in path and query",
+			provider: RpcProvider{URL: "https://x.io/{token}/rpc?key={token}", AuthType: TokenAuth, AuthToken: "abc"},
+			want:     "https://x.io/abc/rpc?key=abc",
+		},
+		{
+			name:     "Placeholder ignored for other auth types",
+			provider: RpcProvider{URL: "https://x.io/{token}/rpc", AuthType: BasicAuth, AuthLogin: "u", AuthPassword: "p"},
+			want:     "https://x.io/{token}/rpc",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.provider.RequestURL()
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestEnabledProvidersSortByPriority(t *testing.T) {
+	disabled := false
+	providers := []RpcProvider{
+		{Name: "fallback", Priority: 2},
+		{Name: "disabled", Enabled: &disabled},
+		{Name: "first"},
+		{Name: "second"},
+		{Name: "backup", Priority: 1},
+	}
+
+	enabled := EnabledProviders(providers)
+	SortByPriority(enabled)
+
+	var names []string
+	for _, provider := range enabled {
+		names = append(names, provider.Name)
+	}
+	assert.Equal(t, []string{"first", "second", "backup", "fallback"}, names)
+}
@@ -2,8 +2,10 @@ package rpcprovider
 
 import (
 	"encoding/json"
+	"fmt"
 	"os"
 
+	"github.com/friofry/config-health-checker/strictjson"
 	"github.com/go-playground/validator/v10"
 )
 
@@ -13,15 +15,20 @@ type RpcProvidersFile struct {
 }
 
 // ReadRpcProviders reads the list of providers from a JSON file with validation.
+// Secret references in credentials (env:NAME, file:PATH) are resolved.
 func ReadRpcProviders(filename string) ([]RpcProvider, error) {
-	f, err := os.Open(filename)
+	data, err := os.ReadFile(filename)
 	if err != nil {
 		return nil, err
 	}
-	defer f.Close()
 
 	var pf RpcProvidersFile
-	if err := json.NewDecoder(f).Decode(&pf); err != nil {
+	if err := strictjson.Decode(data, &pf); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", filename, err)
+	}
+
+	// Replace secret references with the secrets before validation
+	if err := ResolveProvidersSecrets(pf.Providers); err != nil {
 		return nil, err
 	}
 
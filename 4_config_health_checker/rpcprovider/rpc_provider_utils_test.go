@@ -162,6 +162,20 @@ func (suite *RpcProviderTestSuite) TestWriteRpcProvidersAndReadBack() {
 			AuthType:  TokenAuth,
 			AuthToken: "dummy_token",
 		},
+		{
+			Name:       "TestProvider3",
+			URL:        "https://test3.example.com",
+			AuthType:   HeaderAuth,
+			AuthHeader: "x-api-key",
+			AuthToken:  "dummy_key",
+			Headers:    map[string]string{"x-client": "checker"},
+		},
+		{
+			Name:      "TestProvider4",
+			URL:       "https://test4.example.com/{token}/rpc?key={token}",
+			AuthType:  TokenAuth,
+			AuthToken: "dummy_token",
+		},
 	}
 
 	// Write providers to the file
@@ -176,7 +190,89 @@ func (suite *RpcProviderTestSuite) TestWriteRpcProvidersAndReadBack() {
 	assert.Equal(suite.T(), wantProviders, gotProviders, "Providers read from file do not match written providers")
 }
 
+// TestReadRpcProvidersHeaderAuth checks reading of header-auth providers and custom headers
+func (suite *RpcProviderTestSuite) TestReadRpcProvidersHeaderAuth() {
+	headerAuthJSON := `{
+  "providers": [
+    {
+      "name": "BearerProvider",
+      "url": "https://bearer.example.io",
+      "authType": "header-auth",
+      "authHeader": "Authorization",
+      "authToken": "Bearer secret",
+      "headers": {"x-client-id": "health-checker"}
+    }
+  ]
+}`
+	err := os.WriteFile(suite.tempFile, []byte(headerAuthJSON), 0644)
+	suite.Require().NoError(err, "Failed to write header-auth JSON to temp file")
+
+	providers, err := ReadRpcProviders(suite.tempFile)
+	suite.Require().NoError(err, "ReadRpcProviders() returned an error")
+	suite.Require().Len(providers, 1)
+	suite.Equal(HeaderAuth, providers[0].AuthType)
+	suite.Equal("Authorization", providers[0].AuthHeader)
+	suite.Equal("Bearer secret", providers[0].AuthToken)
+	suite.Equal(map[string]string{"x-client-id": "health-checker"}, providers[0].Headers)
+}
+
+// TestWriteRpcProvidersHeaderAuthValidation checks that invalid header-auth providers are rejected
+func (suite *RpcProviderTestSuite) TestWriteRpcProvidersHeaderAuthValidation() {
+	tests := map[string]RpcProvider{
+		"missing header name": {Name: "p", URL: "https://p.example.com", AuthType: HeaderAuth, AuthToken: "key"},
+		"missing token":       {Name: "p", URL: "https://p.example.com", AuthType: HeaderAuth, AuthHeader: "x-api-key"},
+		"invalid header name": {Name: "p", URL: "https://p.example.com", AuthType: HeaderAuth, AuthHeader: "x api key", AuthToken: "key"},
+		"empty custom header": {Name: "p", URL: "https://p.example.com", AuthType: NoAuth, Headers: map[string]string{"": "value"}},
+		"empty header value":  {Name: "p", URL: "https://p.example.com", AuthType: NoAuth, Headers: map[string]string{"x-id": ""}},
+	}
+	for name, provider := range tests {
+		err := WriteRpcProviders(suite.tempFile, []RpcProvider{provider})
+		suite.Error(err, name)
+	}
+}
+
 // TestWriteRpcProvidersHandlesEmptyList checks that the function correctly handles an empty list of providers
+// TestReadRpcProvidersSelectionFields checks enabled, weight, priority and tags
+func (suite *RpcProviderTestSuite) TestReadRpcProvidersSelectionFields() {
+	content := `{
+  "providers": [
+    {
+      "name": "Primary",
+      "url": "https://primary.example.io",
+      "authType": "no-auth",
+      "weight": 3,
+      "priority": 1,
+      "tags": ["archive", "paid"]
+    },
+    {
+      "name": "Disabled",
+      "url": "https://disabled.example.io",
+      "enabled": false,
+      "authType": "token-auth",
+      "authToken": "env:CHC_TEST_UNSET_DISABLED_TOKEN"
+    }
+  ]
+}`
+	err := os.WriteFile(suite.tempFile, []byte(content), 0644)
+	suite.Require().NoError(err)
+
+	// The secret of the disabled provider is not resolved, so the missing variable is not an error
+	providers, err := ReadRpcProviders(suite.tempFile)
+	suite.Require().NoError(err)
+	suite.Require().Len(providers, 2)
+
+	suite.True(providers[0].IsEnabled(), "providers are enabled by default")
+	suite.Equal(3, providers[0].EffectiveWeight())
+	suite.Equal(1, providers[0].Priority)
+	suite.Equal([]string{"archive", "paid"}, providers[0].Tags)
+	suite.False(providers[1].IsEnabled())
+	suite.Equal(DefaultWeight, providers[1].EffectiveWeight())
+
+	// Negative weights are rejected
+	providers[0].Weight = -1
+	suite.Error(WriteRpcProviders(suite.tempFile, providers))
+}
+
 func (suite *RpcProviderTestSuite) TestWriteRpcProvidersHandlesEmptyList() {
 	// Write an empty list of providers to the file
 	err := WriteRpcProviders(suite.tempFile, []RpcProvider{})
@@ -0,0 +1,229 @@
+package rpcprovider
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"strings"
+)
+
+const (
+	EnvSecretPrefix  = "env:"  // Secret reference to an environment variable, e.g. "env:INFURA_KEY"
+	FileSecretPrefix = "file:" // Secret reference to a file, e.g. "file:/run/secrets/alchemy"
+
+	// RedactedValue replaces credentials in redacted output
+	RedactedValue = "[REDACTED]"
+)
+
+// IsSecretReference reports whether value refers to a secret instead of containing it
+func IsSecretReference(value string) bool {
+	return strings.HasPrefix(value, EnvSecretPrefix) || strings.HasPrefix(value, FileSecretPrefix)
+}
+
+// ResolveSecret returns the secret a reference points to.
+// Values that are not references are returned unchanged.
+// Errors mention the reference only, never the secret value.
+func ResolveSecret(value string) (string, error) {
+	switch {
+	case strings.HasPrefix(value, EnvSecretPrefix):
+		name := strings.TrimPrefix(value, EnvSecretPrefix)
+		secret, ok := os.LookupEnv(name)
+		if !ok {
+			return "", fmt.Errorf("environment variable %s is not set", name)
+		}
+		return secret, nil
+	case strings.HasPrefix(value, FileSecretPrefix):
+		path := strings.TrimPrefix(value, FileSecretPrefix)
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", fmt.Errorf("failed to read secret file %s: %w", path, err)
+		}
+		// Secret files usually end with a newline that is not part of the secret
+		return strings.TrimRight(string(data), "\r\n"), nil
+	default:
+		return value, nil
+	}
+}
+
+// ResolveSecrets replaces secret references in the provider credentials and header values with the secrets
+func (p *RpcProvider) ResolveSecrets() error {
+	fields := []struct {
+		name  string
+		value *string
+	}{
+		{"authLogin", &p.AuthLogin},
+		{"authPassword", &p.AuthPassword},
+		{"authToken", &p.AuthToken},
+	}
+	for _, field := range fields {
+		secret, err := ResolveSecret(*field.value)
+		if err != nil {
+			return fmt.Errorf("provider %s: %s: %w", p.Name, field.name, err)
+		}
+		*field.value = secret
+	}
+
+	if len(p.Headers) > 0 {
+		headers := make(map[string]string, len(p.Headers))
+		for name, value := range p.Headers {
+			secret, err := ResolveSecret(value)
+			if err != nil {
+				return fmt.Errorf("provider %s: header %s: %w", p.Name, name, err)
+			}
+			headers[name] = secret
+		}
+		p.Headers = headers
+	}
+
+	return nil
+}
+
+// ResolveProvidersSecrets resolves secret references of every provider in place
+func ResolveProvidersSecrets(providers []RpcProvider) error {
+	for i := range providers {
+		// Disabled providers are never called, so their secrets need not exist
+		if !providers[i].IsEnabled() {
+			continue
+		}
+		if err := providers[i].ResolveSecrets(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Redacted returns a copy of the provider with credentials, URL passwords and header values replaced by RedactedValue
+func (p RpcProvider) Redacted() RpcProvider {
+	redact := func(value string) string {
+		if value == "" {
+			return ""
+		}
+		return RedactedValue
+	}
+
+	redacted := p
+	redacted.URL = SanitizeURL(p.URL)
+	redacted.AuthPassword = redact(p.AuthPassword)
+	redacted.AuthToken = redact(p.AuthToken)
+	if len(p.Headers) > 0 {
+		redacted.Headers = make(map[string]string, len(p.Headers))
+		for name, value := range p.Headers {
+			redacted.Headers[name] = redact(value)
+		}
+	}
+	return redacted
+}
+
+// String returns the redacted provider as JSON, so printing a provider never reveals its credentials
+func (p RpcProvider) String() string {
+	data, err := json.Marshal(p.Redacted())
+	if err != nil {
+		return fmt.Sprintf("RpcProvider{Name: %s}", p.Name)
+	}
+	return string(data)
+}
+
+// WithoutCredentials returns a copy of the provider with credentials and custom headers removed
+func (p RpcProvider) WithoutCredentials() RpcProvider {
+	stripped := p
+	stripped.URL = SanitizeURL(p.URL)
+	stripped.AuthLogin = ""
+	stripped.AuthPassword = ""
+	stripped.AuthToken = ""
+	stripped.Headers = nil
+	return stripped
+}
+
+// LogValue implements slog.LogValuer so that logged providers never reveal their credentials
+func (p RpcProvider) LogValue() slog.Value {
+	return slog.GroupValue(
+		slog.String("name", p.Name),
+		slog.String("url", SanitizeURL(p.URL)),
+		slog.String("authType", string(p.AuthType)),
+	)
+}
+
+// RedactedURL returns the URL requests to the provider are sent to with credentials replaced by RedactedValue.
+// The token is never searched for in the URL: the path segments and query values it is placed in are masked.
+// It is meant for logs and error messages.
+func (p RpcProvider) RedactedURL() string {
+	u, err := url.Parse(p.URL)
+	if err != nil {
+		return RedactedValue
+	}
+	if p.AuthType == TokenAuth {
+		if strings.Contains(p.URL, TokenPlaceholder) {
+			redactTemplatedPath(u)
+			u.RawQuery = redactTemplatedQuery(u.RawQuery)
+		} else {
+			escapedPath := u.EscapedPath()
+			u.Path += "/" + RedactedValue
+			u.RawPath = escapedPath + "/" + RedactedValue
+		}
+	}
+	return formatRedacted(u)
+}
+
+// redactTemplatedPath masks the path segments containing the token placeholder
+func redactTemplatedPath(u *url.URL) {
+	segments := strings.Split(u.Path, "/")
+	escaped := make([]string, len(segments))
+	for i, segment := range segments {
+		if strings.Contains(segment, TokenPlaceholder) {
+			segments[i] = RedactedValue
+			escaped[i] = RedactedValue
+			continue
+		}
+		escaped[i] = url.PathEscape(segment)
+	}
+	u.Path = strings.Join(segments, "/")
+	u.RawPath = strings.Join(escaped, "/")
+}
+
+// redactTemplatedQuery masks the query values containing the token placeholder, keeping the order of parameters
+func redactTemplatedQuery(rawQuery string) string {
+	if rawQuery == "" {
+		return ""
+	}
+	params := strings.Split(rawQuery, "&")
+	for i, param := range params {
+		key, value, _ := strings.Cut(param, "=")
+		if unescaped, err := url.QueryUnescape(value); err != nil || strings.Contains(unescaped, TokenPlaceholder) {
+			params[i] = key + "=" + RedactedValue
+		}
+	}
+	return strings.Join(params, "&")
+}
+
+// SanitizeURL masks the password of URL user info with RedactedValue.
+// URLs that cannot be parsed are replaced with RedactedValue entirely.
+func SanitizeURL(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return RedactedValue
+	}
+	if u.User == nil {
+		return raw
+	}
+	if _, ok := u.User.Password(); !ok {
+		return raw
+	}
+	return formatRedacted(u)
+}
+
+// formatRedacted formats the URL with the password of its user info replaced by RedactedValue.
+// Unlike url.URL.Redacted, the mask is the same as everywhere else and is not escaped.
+func formatRedacted(u *url.URL) string {
+	if u.User == nil {
+		return u.String()
+	}
+	if _, ok := u.User.Password(); !ok {
+		return u.String()
+	}
+	withoutUser := *u
+	withoutUser.User = nil
+	rest := strings.TrimPrefix(withoutUser.String(), u.Scheme+"://")
+	return u.Scheme + "://" + url.User(u.User.Username()).String() + ":" + RedactedValue + "@" + rest
+}
@@ -6,12 +6,17 @@ import (
 	"fmt"
 	"math/big"
 	"os"
+
+	"github.com/friofry/config-health-checker/chainprotocol"
+	"github.com/friofry/config-health-checker/strictjson"
 )
 
 // EVMMethodTestConfig contains configuration for testing an EVM method
 type EVMMethodTestConfig struct {
 	Method      string
 	Params      []interface{}
+	Protocol    chainprotocol.Protocol // Protocol of the chains the method is run against
+	ResultPath  string                 // Dot-separated path to the number inside the result, e.g. "value"
 	CompareFunc func(reference, result *big.Int) bool
 }
 
@@ -20,10 +25,13 @@ type EVMMethodTestJSON struct {
 	Method        string        `json:"method"`
 	Params        []interface{} `json:"params"`
 	MaxDifference string        `json:"maxDifference"`
+	Protocol      string        `json:"protocol,omitempty"`
+	ResultPath    string        `json:"resultPath,omitempty"`
 }
 
-// ReadConfig reads and parses the EVM method test configuration from a JSON file
-func ReadConfig(path string) ([]EVMMethodTestConfig, error) {
+// ReadConfig reads and parses the EVM method test configuration from a JSON file,
+// handling unknown fields as mode says
+func ReadConfig(path string, mode strictjson.Mode) ([]EVMMethodTestConfig, error) {
 	// Read file
 	data, err := os.ReadFile(path)
 	if err != nil {
@@ -32,7 +40,7 @@ func ReadConfig(path string) ([]EVMMethodTestConfig, error) {
 
 	// Parse JSON
 	var testConfigs []EVMMethodTestJSON
-	if err := json.Unmarshal(data, &testConfigs); err != nil {
+	if err := strictjson.DecodeMode(data, &testConfigs, mode); err != nil {
 		return nil, fmt.Errorf("failed to parse JSON: %w", err)
 	}
 
@@ -45,6 +53,11 @@ func ReadConfig(path string) ([]EVMMethodTestConfig, error) {
 			return nil, fmt.Errorf("invalid maxDifference value: %s", cfg.MaxDifference)
 		}
 
+		protocol, err := chainprotocol.Parse(cfg.Protocol)
+		if err != nil {
+			return nil, fmt.Errorf("method %s: %w", cfg.Method, err)
+		}
+
 		// Create comparison function
 		compareFunc := func(reference, result *big.Int) bool {
 			diff := new(big.Int).Abs(new(big.Int).Sub(reference, result))
@@ -54,6 +67,8 @@ func ReadConfig(path string) ([]EVMMethodTestConfig, error) {
 		configs = append(configs, EVMMethodTestConfig{
 			Method:      cfg.Method,
 			Params:      cfg.Params,
+			Protocol:    protocol,
+			ResultPath:  cfg.ResultPath,
 			CompareFunc: compareFunc,
 		})
 	}
@@ -3,9 +3,13 @@ package rpctestsconfig
 import (
 	"math/big"
 	"os"
+	"path/filepath"
 	"testing"
 
 	"github.com/stretchr/testify/require"
+
+	"github.com/friofry/config-health-checker/chainprotocol"
+	"github.com/friofry/config-health-checker/strictjson"
 )
 
 func TestReadConfig(t *testing.T) {
@@ -32,7 +36,7 @@ func TestReadConfig(t *testing.T) {
 	tmpFile.Close()
 
 	// Test reading config
-	configs, err := ReadConfig(tmpFile.Name())
+	configs, err := ReadConfig(tmpFile.Name(), strictjson.ModeStrict)
 	require.NoError(t, err)
 	require.Len(t, configs, 2)
 
@@ -67,6 +71,23 @@ func TestReadConfig(t *testing.T) {
 	))
 }
 
+func TestReadConfigProtocol(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test_methods.json")
+	content := `[{"method": "getBalance", "params": ["address"], "maxDifference": "0", "protocol": "solana", "resultPath": "value"}]`
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+
+	configs, err := ReadConfig(path, strictjson.ModeStrict)
+	require.NoError(t, err)
+	require.Len(t, configs, 1)
+	require.Equal(t, chainprotocol.Solana, configs[0].Protocol)
+	require.Equal(t, "value", configs[0].ResultPath)
+
+	content = `[{"method": "getinfo", "params": [], "maxDifference": "0", "protocol": "cosmos"}]`
+	require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	_, err = ReadConfig(path, strictjson.ModeStrict)
+	require.Error(t, err)
+}
+
 func TestValidateConfig(t *testing.T) {
 	tests := []struct {
 		name    string
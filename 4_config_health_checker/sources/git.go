@@ -0,0 +1,105 @@
+package sources
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// GitSource fetches a file from a Git repository with the git command, using the commit to skip unchanged files.
+// Only the requested ref is fetched, without history, into a local repository kept between fetches.
+type GitSource struct {
+	Repo string // Repository URL, may contain credentials
+	Path string // Path of the file in the repository
+	Ref  string // Branch, tag or commit, HEAD if empty
+	Dir  string // Local repository the ref is fetched into
+}
+
+// ParseGitLocation parses a git+<repository>//<path>?ref=<ref> location,
+// e.g. git+https://github.com/org/providers.git//chains/default.json?ref=main
+func ParseGitLocation(location string) (*GitSource, error) {
+	rest := strings.TrimPrefix(location, "git+")
+	ref := ""
+	if i := strings.LastIndex(rest, "?ref="); i >= 0 {
+		rest, ref = rest[:i], rest[i+len("?ref="):]
+	}
+
+	schemeEnd := strings.Index(rest, "://")
+	if schemeEnd < 0 {
+		return nil, errors.New("expected git+<repository URL>//<path>")
+	}
+	pathStart := strings.Index(rest[schemeEnd+3:], "//")
+	if pathStart < 0 {
+		return nil, errors.New("expected //<path> after the repository URL")
+	}
+	pathStart += schemeEnd + 3
+
+	filePath := path.Clean(rest[pathStart+2:])
+	if filePath == "." || strings.HasPrefix(filePath, "../") {
+		return nil, errors.New("invalid path in repository")
+	}
+	return &GitSource{Repo: rest[:pathStart], Path: filePath, Ref: ref}, nil
+}
+
+// Fetch implements Source, the version is the fetched commit
+func (s *GitSource) Fetch(ctx context.Context, version string) ([]byte, string, error) {
+	if _, err := os.Stat(filepath.Join(s.Dir, ".git")); err != nil {
+		if err := os.MkdirAll(s.Dir, 0700); err != nil {
+			return nil, "", fmt.Errorf("failed to create git directory: %w", err)
+		}
+		if _, err := s.git(ctx, "init", "--quiet"); err != nil {
+			return nil, "", err
+		}
+	}
+
+	ref := s.Ref
+	if ref == "" {
+		ref = "HEAD"
+	}
+	// The repository URL is passed on every fetch, so that its credentials are not stored in the git config
+	if _, err := s.git(ctx, "fetch", "--quiet", "--depth", "1", "--no-tags", s.Repo, ref); err != nil {
+		return nil, "", err
+	}
+	commit, err := s.git(ctx, "rev-parse", "FETCH_HEAD")
+	if err != nil {
+		return nil, "", err
+	}
+	commitID := strings.TrimSpace(string(commit))
+	if commitID == version {
+		return nil, "", ErrNotModified
+	}
+
+	data, err := s.git(ctx, "show", "FETCH_HEAD:"+s.Path)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(data) > maxFileSize {
+		return nil, "", fmt.Errorf("file %s exceeds %d bytes", s.Path, maxFileSize)
+	}
+	return data, commitID, nil
+}
+
+// git runs a git command in the local repository and returns its output.
+// Errors include the message of git with credentials of URLs removed.
+func (s *GitSource) git(ctx context.Context, args ...string) ([]byte, error) {
+	cmd := exec.CommandContext(ctx, "git", append([]string{"-C", s.Dir}, args...)...)
+	// Never wait for credentials on a terminal
+	cmd.Env = append(os.Environ(), "GIT_TERMINAL_PROMPT=0")
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		message := strings.TrimSpace(stderr.String())
+		if message == "" {
+			message = err.Error()
+		}
+		return nil, fmt.Errorf("git %s failed: %s", args[0], redactText(message))
+	}
+	return stdout.Bytes(), nil
+}
@@ -0,0 +1,75 @@
+package sources
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestParseGitLocation(t *testing.T) {
+	source, err := ParseGitLocation("git+https://token@github.com/org/providers.git//chains/default.json?ref=main")
+	require.NoError(t, err)
+	assert.Equal(t, "https://token@github.com/org/providers.git", source.Repo)
+	assert.Equal(t, "chains/default.json", source.Path)
+	assert.Equal(t, "main", source.Ref)
+
+	for _, location := range []string{
+		"git+https://github.com/org/providers.git",
+		"git+github.com/org/providers.git//default.json",
+		"git+https://github.com/org/providers.git//../default.json",
+	} {
+		_, err := ParseGitLocation(location)
+		assert.Error(t, err, location)
+	}
+}
+
+// commitFile writes a file to a repository and commits it
+func commitFile(t *testing.T, repo, name, content string) {
+	require.NoError(t, os.WriteFile(filepath.Join(repo, name), []byte(content), 0644))
+	for _, args := range [][]string{{"add", name}, {"commit", "--quiet", "-m", "update " + name}} {
+		cmd := exec.Command("git", append([]string{"-C", repo}, args...)...)
+		cmd.Env = append(os.Environ(),
+			"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com")
+		output, err := cmd.CombinedOutput()
+		require.NoError(t, err, string(output))
+	}
+}
+
+func TestGitSource(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is not installed")
+	}
+	repo := t.TempDir()
+	output, err := exec.Command("git", "init", "--quiet", "--initial-branch=main", repo).CombinedOutput()
+	require.NoError(t, err, string(output))
+	require.NoError(t, os.MkdirAll(filepath.Join(repo, "chains"), 0755))
+	commitFile(t, repo, "chains/default.json", `{"chains":[]}`)
+
+	source, err := ParseGitLocation("git+file://" + repo + "//chains/default.json?ref=main")
+	require.NoError(t, err)
+	source.Dir = filepath.Join(t.TempDir(), "clone")
+
+	data, version, err := source.Fetch(context.Background(), "")
+	require.NoError(t, err)
+	assert.Equal(t, `{"chains":[]}`, string(data))
+	assert.Len(t, version, 40)
+
+	_, _, err = source.Fetch(context.Background(), version)
+	assert.ErrorIs(t, err, ErrNotModified)
+
+	commitFile(t, repo, "chains/default.json", `{"chains":null}`)
+	data, newVersion, err := source.Fetch(context.Background(), version)
+	require.NoError(t, err)
+	assert.Equal(t, `{"chains":null}`, string(data))
+	assert.NotEqual(t, version, newVersion)
+
+	source.Path = "chains/missing.json"
+	_, _, err = source.Fetch(context.Background(), "")
+	assert.ErrorContains(t, err, "git show failed")
+}
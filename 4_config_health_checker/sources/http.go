@@ -0,0 +1,68 @@
+package sources
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+)
+
+// HTTPSource fetches a file over HTTPS, using its ETag to skip unchanged files
+type HTTPSource struct {
+	URL     string            // File URL, may contain credentials
+	Headers map[string]string // Additional request headers, e.g. authorization
+	Client  *http.Client      // HTTP client, http.DefaultClient if nil; fetches are bounded by the context
+}
+
+// Fetch implements Source, the version is the ETag of the file
+func (s *HTTPSource) Fetch(ctx context.Context, version string) ([]byte, string, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.URL, nil)
+	if err != nil {
+		return nil, "", fmt.Errorf("invalid URL %s", Redact(s.URL))
+	}
+	for name, value := range s.Headers {
+		req.Header.Set(name, value)
+	}
+	if version != "" {
+		req.Header.Set("If-None-Match", version)
+	}
+	return get(s.Client, req)
+}
+
+// get sends a request and returns the body and ETag of the response.
+// Only the host of the URL is included in errors, since URLs may contain credentials.
+func get(client *http.Client, req *http.Request) ([]byte, string, error) {
+	if client == nil {
+		client = http.DefaultClient
+	}
+	host := req.URL.Scheme + "://" + req.URL.Host
+
+	resp, err := client.Do(req)
+	if err != nil {
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return nil, "", fmt.Errorf("request to %s failed: %w", host, err)
+	}
+	defer resp.Body.Close()
+
+	switch {
+	case resp.StatusCode == http.StatusNotModified:
+		return nil, "", ErrNotModified
+	case resp.StatusCode < 200 || resp.StatusCode >= 300:
+		io.Copy(io.Discard, io.LimitReader(resp.Body, 64*1024))
+		return nil, "", fmt.Errorf("%s answered with status %d", host, resp.StatusCode)
+	}
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxFileSize+1))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read response of %s: %w", host, err)
+	}
+	if len(data) > maxFileSize {
+		return nil, "", fmt.Errorf("file at %s exceeds %d bytes", host, maxFileSize)
+	}
+	return data, resp.Header.Get("ETag"), nil
+}
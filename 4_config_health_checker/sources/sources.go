@@ -0,0 +1,242 @@
+package sources
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/friofry/config-health-checker/configreader"
+	"github.com/friofry/config-health-checker/rpcprovider"
+)
+
+// Default settings of remote sources
+const (
+	DefaultCacheDir = "sources_cache"
+	DefaultTimeout  = 30 * time.Second
+)
+
+// maxFileSize limits the size of fetched files
+const maxFileSize = 16 << 20
+
+// ErrNotModified is returned by Source.Fetch when the file still has the given version
+var ErrNotModified = errors.New("not modified")
+
+// Source fetches a remote file
+type Source interface {
+	// Fetch returns the content and the version of the file, e.g. its ETag.
+	// It returns ErrNotModified if the file still has the given version.
+	Fetch(ctx context.Context, version string) ([]byte, string, error)
+}
+
+// Fetcher makes provider files available as local files. Remote files are fetched into a cache directory;
+// if a fetch fails, the last good copy is used.
+type Fetcher struct {
+	mu        sync.Mutex // Serialises fetches, which share the cache directory
+	cacheDir  string
+	timeout   time.Duration
+	newSource func(location string) (Source, error) // Returns nil for local files
+	logger    *slog.Logger
+}
+
+// NewFetcher creates a fetcher creating the sources of remote locations with newSource,
+// which returns nil for local files. Zero values mean DefaultCacheDir and DefaultTimeout.
+func NewFetcher(cacheDir string, timeout time.Duration, newSource func(location string) (Source, error)) *Fetcher {
+	if cacheDir == "" {
+		cacheDir = DefaultCacheDir
+	}
+	if timeout <= 0 {
+		timeout = DefaultTimeout
+	}
+	return &Fetcher{cacheDir: cacheDir, timeout: timeout, newSource: newSource, logger: slog.Default()}
+}
+
+// FromConfig creates a fetcher for the locations supported by the sources section of the configuration.
+// Secret references in HTTP headers and S3 credentials are resolved.
+func FromConfig(cfg configreader.CheckerConfig) (*Fetcher, error) {
+	sourcesCfg := cfg.Sources
+	headers := make(map[string]string, len(sourcesCfg.HTTPHeaders))
+	for name, value := range sourcesCfg.HTTPHeaders {
+		resolved, err := rpcprovider.ResolveSecret(value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to resolve sources header %s: %w", name, err)
+		}
+		headers[name] = resolved
+	}
+	accessKeyID, err := rpcprovider.ResolveSecret(sourcesCfg.S3.AccessKeyID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve s3 access_key_id: %w", err)
+	}
+	secretAccessKey, err := rpcprovider.ResolveSecret(sourcesCfg.S3.SecretAccessKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve s3 secret_access_key: %w", err)
+	}
+
+	cacheDir := sourcesCfg.CacheDir
+	if cacheDir == "" {
+		cacheDir = DefaultCacheDir
+	}
+	newSource := func(location string) (Source, error) {
+		switch {
+		case strings.HasPrefix(location, "https://"):
+			return &HTTPSource{URL: location, Headers: headers}, nil
+		case strings.HasPrefix(location, "s3://"):
+			source, err := ParseS3Location(location)
+			if err != nil {
+				return nil, err
+			}
+			source.Endpoint = sourcesCfg.S3.Endpoint
+			source.Region = sourcesCfg.S3.Region
+			source.AccessKeyID = accessKeyID
+			source.SecretAccessKey = secretAccessKey
+			return source, nil
+		case strings.HasPrefix(location, "git+"):
+			source, err := ParseGitLocation(location)
+			if err != nil {
+				return nil, err
+			}
+			source.Dir = filepath.Join(cacheDir, "git", cacheKey(source.Repo))
+			return source, nil
+		case strings.HasPrefix(location, "http://"):
+			return nil, errors.New("plain http is not supported for provider files, use https")
+		default:
+			return nil, nil
+		}
+	}
+	return NewFetcher(cacheDir, time.Duration(sourcesCfg.TimeoutSeconds)*time.Second, newSource), nil
+}
+
+// LocalConfig returns a copy of the configuration with the provider paths replaced by local files
+func (f *Fetcher) LocalConfig(ctx context.Context, cfg configreader.CheckerConfig) (configreader.CheckerConfig, error) {
+	var err error
+	if cfg.DefaultProvidersPath, err = f.Local(ctx, cfg.DefaultProvidersPath); err != nil {
+		return cfg, fmt.Errorf("default providers: %w", err)
+	}
+	if cfg.ReferenceProvidersPath, err = f.Local(ctx, cfg.ReferenceProvidersPath); err != nil {
+		return cfg, fmt.Errorf("reference providers: %w", err)
+	}
+	return cfg, nil
+}
+
+// Local returns the path of a local copy of the file at location. Local paths are returned as they are.
+// Remote files are fetched, falling back to the last good copy if the fetch fails or returns invalid JSON.
+func (f *Fetcher) Local(ctx context.Context, location string) (string, error) {
+	source, err := f.newSource(location)
+	if err != nil {
+		return "", fmt.Errorf("invalid location %s: %w", Redact(location), err)
+	}
+	if source == nil {
+		return location, nil
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	cachePath := filepath.Join(f.cacheDir, cacheKey(location)+".json")
+	versionPath := cachePath + ".version"
+	_, statErr := os.Stat(cachePath)
+	cached := statErr == nil
+	version := ""
+	if cached {
+		if data, err := os.ReadFile(versionPath); err == nil {
+			version = string(data)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, f.timeout)
+	defer cancel()
+	data, newVersion, err := source.Fetch(ctx, version)
+	switch {
+	case errors.Is(err, ErrNotModified) && cached:
+		return cachePath, nil
+	case errors.Is(err, ErrNotModified):
+		err = errors.New("source answered not modified without a cached copy")
+	case err == nil && !json.Valid(data):
+		err = errors.New("fetched file is not valid JSON")
+	}
+	if err == nil {
+		err = f.store(cachePath, versionPath, data, newVersion)
+	}
+	if err != nil {
+		if !cached {
+			return "", fmt.Errorf("failed to fetch %s: %w", Redact(location), err)
+		}
+		f.logger.Warn("failed to fetch provider file, using the last good copy",
+			"location", Redact(location), "error", err)
+	}
+	return cachePath, nil
+}
+
+// store replaces the cached copy and its version. Copies contain credentials, so they are private.
+func (f *Fetcher) store(cachePath, versionPath string, data []byte, version string) error {
+	if err := os.MkdirAll(f.cacheDir, 0700); err != nil {
+		return fmt.Errorf("failed to create cache directory: %w", err)
+	}
+	if err := writeFile(cachePath, data); err != nil {
+		return err
+	}
+	if version == "" {
+		os.Remove(versionPath)
+		return nil
+	}
+	return writeFile(versionPath, []byte(version))
+}
+
+// writeFile writes a temporary file and renames it, so that readers never see a partial file
+func writeFile(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".tmp*")
+	if err != nil {
+		return fmt.Errorf("failed to create cache file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write cache file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write cache file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return fmt.Errorf("failed to replace cache file: %w", err)
+	}
+	return nil
+}
+
+// cacheKey names the cached copy of a location
+func cacheKey(location string) string {
+	sum := sha256.Sum256([]byte(location))
+	return hex.EncodeToString(sum[:8])
+}
+
+// userinfoPattern matches credentials embedded in URLs
+var userinfoPattern = regexp.MustCompile(`://[^/@\s]+@`)
+
+// Redact removes credentials and query parameters from a location, so that it can be logged
+func Redact(location string) string {
+	u, err := url.Parse(strings.TrimPrefix(location, "git+"))
+	if err != nil || u.Scheme == "" {
+		return userinfoPattern.ReplaceAllString(location, "://")
+	}
+	u.User = nil
+	u.RawQuery = ""
+	u.Fragment = ""
+	if strings.HasPrefix(location, "git+") {
+		return "git+" + u.String()
+	}
+	return u.String()
+}
+
+// redactText removes credentials of URLs contained in a text, e.g. an error message of git
+func redactText(text string) string {
+	return userinfoPattern.ReplaceAllString(text, "://")
+}
@@ -0,0 +1,332 @@
+package strictjson
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"strings"
+)
+
+// Mode controls how unknown fields in configuration files are handled
+type Mode string
+
+const (
+	ModeStrict Mode = "strict" // Unknown fields are errors
+	ModeWarn   Mode = "warn"   // Unknown fields are logged as warnings, meant for migrating existing files
+)
+
+// ParseMode converts a configuration value into a Mode, empty means ModeStrict
+func ParseMode(value string) (Mode, error) {
+	switch mode := Mode(value); mode {
+	case "":
+		return ModeStrict, nil
+	case ModeStrict, ModeWarn:
+		return mode, nil
+	default:
+		return "", fmt.Errorf("unknown fields mode must be %q or %q, got %q", ModeStrict, ModeWarn, value)
+	}
+}
+
+// UnknownField describes a field of a JSON document that the target type does not define
+type UnknownField struct {
+	Key        string // Key as written in the document
+	Path       string // Path of the object holding the key, e.g. "chains[0].providers[1]"
+	Owner      string // Value of the "name" field of the innermost named object holding the key, if any
+	Suggestion string // Known field the key probably meant, if any
+	Line       int    // 1-based line of the key
+	Column     int    // 1-based column of the key
+}
+
+// String describes the unknown field with its position
+func (f UnknownField) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "line %d, column %d: unknown field %q", f.Line, f.Column, f.Key)
+	if f.Path != "" {
+		fmt.Fprintf(&b, " in %s", f.Path)
+	}
+	if f.Owner != "" {
+		fmt.Fprintf(&b, " (%q)", f.Owner)
+	}
+	if f.Suggestion != "" {
+		fmt.Fprintf(&b, ", did you mean %q?", f.Suggestion)
+	}
+	return b.String()
+}
+
+// UnknownFieldsError is returned in ModeStrict when a document contains unknown fields
+type UnknownFieldsError struct {
+	Fields []UnknownField
+}
+
+// Error lists all unknown fields
+func (e *UnknownFieldsError) Error() string {
+	descriptions := make([]string, len(e.Fields))
+	for i, field := range e.Fields {
+		descriptions[i] = field.String()
+	}
+	return strings.Join(descriptions, "; ")
+}
+
+// SyntaxError is a JSON syntax or type error with its position in the document
+type SyntaxError struct {
+	Line   int   // 1-based line of the error
+	Column int   // 1-based column of the error
+	Err    error // Underlying encoding/json error
+}
+
+// Error describes the error with its position
+func (e *SyntaxError) Error() string {
+	return fmt.Sprintf("line %d, column %d: %v", e.Line, e.Column, e.Err)
+}
+
+// Unwrap returns the underlying encoding/json error
+func (e *SyntaxError) Unwrap() error {
+	return e.Err
+}
+
+// Decode is DecodeMode in ModeStrict
+func Decode(data []byte, v interface{}) error {
+	return DecodeMode(data, v, ModeStrict)
+}
+
+// DecodeMode decodes the JSON document data into v.
+// Fields unknown to v are errors in ModeStrict and logged warnings in ModeWarn;
+// unlike encoding/json, keys that differ from a field name in case only are unknown too.
+// Syntax and type errors carry their line and column.
+func DecodeMode(data []byte, v interface{}, mode Mode) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	if mode != ModeWarn {
+		decoder.DisallowUnknownFields()
+	}
+
+	// Report unknown fields with positions before encoding/json reports the first one without
+	if unknown := FindUnknownFields(data, reflect.TypeOf(v)); len(unknown) > 0 {
+		if mode != ModeWarn {
+			return &UnknownFieldsError{Fields: unknown}
+		}
+		for _, field := range unknown {
+			slog.Warn("ignoring unknown field", "field", field.String())
+		}
+	}
+
+	if err := decoder.Decode(v); err != nil {
+		return withPosition(data, err)
+	}
+	if decoder.More() {
+		line, column := position(data, decoder.InputOffset())
+		return &SyntaxError{Line: line, Column: column, Err: errors.New("unexpected data after top-level value")}
+	}
+	return nil
+}
+
+// withPosition adds the line and column to encoding/json errors that carry an offset
+func withPosition(data []byte, err error) error {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case errors.As(err, &syntaxErr):
+		// The offset counts the bytes read, including the offending one
+		line, column := position(data, max(syntaxErr.Offset-1, 0))
+		return &SyntaxError{Line: line, Column: column, Err: err}
+	case errors.As(err, &typeErr):
+		line, column := position(data, typeErr.Offset)
+		return &SyntaxError{Line: line, Column: column, Err: err}
+	default:
+		return err
+	}
+}
+
+// position converts a byte offset into a 1-based line and column
+func position(data []byte, offset int64) (int, int) {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	before := data[:offset]
+	line := bytes.Count(before, []byte("\n")) + 1
+	column := int(offset) - bytes.LastIndexByte(before, '\n')
+	return line, column
+}
+
+// FindUnknownFields returns the fields of the JSON document that typ does not define.
+// Documents that are not valid JSON yield the unknown fields found before the first error.
+func FindUnknownFields(data []byte, typ reflect.Type) []UnknownField {
+	w := &walker{data: data, decoder: json.NewDecoder(bytes.NewReader(data))}
+	w.decoder.UseNumber()
+	w.value(typ, "")
+	return w.unknown
+}
+
+var (
+	unmarshalerType = reflect.TypeOf((*json.Unmarshaler)(nil)).Elem()
+	rawMessageType  = reflect.TypeOf(json.RawMessage{})
+)
+
+// walker walks a JSON document along the type it is decoded into
+type walker struct {
+	data    []byte
+	decoder *json.Decoder
+	unknown []UnknownField
+	err     error
+}
+
+// value walks the next value of the document, which is decoded into typ.
+// It returns the value if it is a string, so that objects can pick up their "name".
+func (w *walker) value(typ reflect.Type, path string) string {
+	if w.err != nil {
+		return ""
+	}
+	for typ != nil && typ.Kind() == reflect.Pointer {
+		typ = typ.Elem()
+	}
+	// Types with their own decoding accept anything
+	if typ == nil || typ == rawMessageType || typ.Kind() == reflect.Interface ||
+		typ.Implements(unmarshalerType) || reflect.PointerTo(typ).Implements(unmarshalerType) {
+		typ = nil
+	}
+
+	token, err := w.decoder.Token()
+	if err != nil {
+		w.err = err
+		return ""
+	}
+
+	switch token {
+	case json.Delim('{'):
+		w.object(typ, path)
+	case json.Delim('['):
+		var elem reflect.Type
+		if typ != nil && (typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array) {
+			elem = typ.Elem()
+		}
+		for i := 0; w.err == nil && w.decoder.More(); i++ {
+			w.value(elem, fmt.Sprintf("%s[%d]", path, i))
+		}
+		w.closing()
+	default:
+		if s, ok := token.(string); ok {
+			return s
+		}
+	}
+	return ""
+}
+
+// object walks the members of an object whose opening brace has been read
+func (w *walker) object(typ reflect.Type, path string) {
+	var fields map[string]reflect.Type
+	var elem reflect.Type
+	checked := false
+	if typ != nil {
+		switch typ.Kind() {
+		case reflect.Struct:
+			fields = structFields(typ)
+			checked = true
+		case reflect.Map:
+			elem = typ.Elem()
+		}
+	}
+
+	first := len(w.unknown)
+	name := ""
+	for w.err == nil && w.decoder.More() {
+		token, err := w.decoder.Token()
+		if err != nil {
+			w.err = err
+			return
+		}
+		key := token.(string)
+		keyEnd := w.decoder.InputOffset()
+
+		fieldType, known := fields[key]
+		if !checked {
+			fieldType, known = elem, true
+		}
+		if !known {
+			keyStart := bytes.LastIndexByte(w.data[:keyEnd-1], '"')
+			line, column := position(w.data, int64(keyStart))
+			w.unknown = append(w.unknown, UnknownField{
+				Key:        key,
+				Path:       path,
+				Suggestion: suggest(key, fields),
+				Line:       line,
+				Column:     column,
+			})
+		}
+
+		childPath := key
+		if path != "" {
+			childPath = path + "." + key
+		}
+		if s := w.value(fieldType, childPath); key == "name" {
+			name = s
+		}
+	}
+	w.closing()
+
+	// Name the innermost named object holding each unknown field
+	for i := first; name != "" && i < len(w.unknown); i++ {
+		if w.unknown[i].Owner == "" {
+			w.unknown[i].Owner = name
+		}
+	}
+}
+
+// closing reads the closing delimiter of an object or array
+func (w *walker) closing() {
+	if w.err != nil {
+		return
+	}
+	if _, err := w.decoder.Token(); err != nil {
+		w.err = err
+	}
+}
+
+// structFields returns the JSON names of the fields of a struct, including promoted fields of embedded structs
+func structFields(typ reflect.Type) map[string]reflect.Type {
+	fields := make(map[string]reflect.Type)
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, _, _ := strings.Cut(tag, ",")
+
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Pointer {
+			fieldType = fieldType.Elem()
+		}
+		if field.Anonymous && name == "" && fieldType.Kind() == reflect.Struct {
+			for promoted, promotedType := range structFields(fieldType) {
+				if _, exists := fields[promoted]; !exists {
+					fields[promoted] = promotedType
+				}
+			}
+			continue
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		fields[name] = field.Type
+	}
+	return fields
+}
+
+// suggest returns the known field a key probably meant, comparing names without case, '_' and '-'
+func suggest(key string, fields map[string]reflect.Type) string {
+	normalize := func(s string) string {
+		return strings.NewReplacer("_", "", "-", "").Replace(strings.ToLower(s))
+	}
+	normalized := normalize(key)
+	for name := range fields {
+		if normalize(name) == normalized {
+			return name
+		}
+	}
+	return ""
+}
@@ -0,0 +1,94 @@
+package strictjson
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testProvider struct {
+	Name      string `json:"name"`
+	AuthToken string `json:"authToken"`
+}
+
+type testChain struct {
+	Name      string            `json:"name"`
+	Providers []testProvider    `json:"providers"`
+	Headers   map[string]string `json:"headers"`
+}
+
+type testConfig struct {
+	IntervalSeconds int         `json:"interval_seconds"`
+	Chains          []testChain `json:"chains"`
+}
+
+func TestDecodeUnknownFields(t *testing.T) {
+	data := []byte(`{
+  "intervalSeconds": 30,
+  "chains": [
+    {
+      "name": "ethereum",
+      "headers": {"x-custom": "kept"},
+      "providers": [
+        {"authtoken": "secret", "name": "infura"}
+      ]
+    }
+  ]
+}`)
+
+	var config testConfig
+	err := DecodeMode(data, &config, ModeStrict)
+	var unknownErr *UnknownFieldsError
+	require.True(t, errors.As(err, &unknownErr), "got %v", err)
+	require.Len(t, unknownErr.Fields, 2)
+
+	assert.Equal(t, UnknownField{
+		Key:        "intervalSeconds",
+		Suggestion: "interval_seconds",
+		Line:       2,
+		Column:     3,
+	}, unknownErr.Fields[0])
+	assert.Equal(t, UnknownField{
+		Key:        "authtoken",
+		Path:       "chains[0].providers[0]",
+		Owner:      "infura",
+		Suggestion: "authToken",
+		Line:       8,
+		Column:     10,
+	}, unknownErr.Fields[1])
+	assert.Contains(t, err.Error(), `line 8, column 10: unknown field "authtoken" in chains[0].providers[0] ("infura"), did you mean "authToken"?`)
+	assert.NotContains(t, err.Error(), "secret")
+
+	// Warn mode decodes the known fields
+	require.NoError(t, DecodeMode(data, &config, ModeWarn))
+	assert.Equal(t, "infura", config.Chains[0].Providers[0].Name)
+	assert.Equal(t, "kept", config.Chains[0].Headers["x-custom"])
+}
+
+func TestDecodeSyntaxErrorPosition(t *testing.T) {
+	var config testConfig
+	err := DecodeMode([]byte("{\n  \"interval_seconds\": \"30\"\n}"), &config, ModeStrict)
+	var syntaxErr *SyntaxError
+	require.True(t, errors.As(err, &syntaxErr), "got %v", err)
+	assert.Equal(t, 2, syntaxErr.Line)
+
+	err = DecodeMode([]byte("{\n  \"chains\": [,]\n}"), &config, ModeStrict)
+	require.True(t, errors.As(err, &syntaxErr), "got %v", err)
+	assert.Equal(t, 2, syntaxErr.Line)
+	assert.Equal(t, 14, syntaxErr.Column)
+}
+
+func TestParseMode(t *testing.T) {
+	mode, err := ParseMode("")
+	require.NoError(t, err)
+	assert.Equal(t, ModeStrict, mode)
+
+	mode, err = ParseMode("warn")
+	require.NoError(t, err)
+	assert.Equal(t, ModeWarn, mode)
+
+	_, err = ParseMode("ignore")
+	assert.Error(t, err)
+}
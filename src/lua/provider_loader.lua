@@ -10,11 +10,18 @@ local function read_config_from_url(url)
 
     local httpc = http.new()
 
+    local headers = {
+        ["Content-Type"] = "application/json",
+    }
+    -- Bearer token required by the config health checker to serve provider credentials
+    local token = os.getenv("CONFIG_HEALTH_CHECKER_TOKEN")
+    if token and token ~= "" then
+        headers["Authorization"] = "Bearer " .. token
+    end
+
     local res, err = httpc:request_uri(url, {
         method = "GET",
-        headers = {
-            ["Content-Type"] = "application/json",
-        },
+        headers = headers,
         ssl_verify = false,
     })
 
@@ -93,6 +100,93 @@ function M.reload_providers(premature, url, fallbackLocalConfig)
     ngx.log(ngx.ERR, "Providers reloaded and stored by chain/network")
 end
 
+-- Weight of a provider, providers without a positive weight count as 1
+local function provider_weight(provider)
+    local weight = tonumber(provider.weight)
+    if not weight or weight <= 0 then
+        return 1
+    end
+    return weight
+end
+
+-- Orders providers for a single request: ascending priority, weighted random order among equal priorities.
+-- Disabled providers are dropped.
+function M.order_providers(providers)
+    local groups = {}
+    local priorities = {}
+    for _, provider in ipairs(providers) do
+        if provider.enabled ~= false then
+            local priority = tonumber(provider.priority) or 0
+            if not groups[priority] then
+                groups[priority] = {}
+                table.insert(priorities, priority)
+            end
+            table.insert(groups[priority], provider)
+        end
+    end
+    table.sort(priorities)
+
+    local ordered = {}
+    for _, priority in ipairs(priorities) do
+        local group = groups[priority]
+        -- Weighted random selection without replacement
+        while #group > 0 do
+            local total = 0
+            for _, provider in ipairs(group) do
+                total = total + provider_weight(provider)
+            end
+            local pick = math.random() * total
+            local index = #group
+            for i, provider in ipairs(group) do
+                pick = pick - provider_weight(provider)
+                if pick < 0 then
+                    index = i
+                    break
+                end
+            end
+            table.insert(ordered, table.remove(group, index))
+        end
+    end
+    return ordered
+end
+
+-- Percent-encodes every byte of value but the unreserved characters and those in keep (a Lua pattern class)
+local function escape(value, keep)
+    return (value:gsub("[^%w%-%._~" .. keep .. "]", function(c)
+        return string.format("%%%02X", c:byte())
+    end))
+end
+
+-- Escapes value like Go's url.PathEscape
+function M.path_escape(value)
+    return escape(value, "%$&%+:=@")
+end
+
+-- Escapes value like Go's url.QueryEscape
+function M.query_escape(value)
+    return (escape(value, " "):gsub(" ", "+"))
+end
+
+-- Returns the URL requests to a provider are sent to, like RpcProvider.RequestURL of the config health checker:
+-- for token-auth every {token} placeholder is replaced with the escaped token, plain URLs get the token appended to the path
+function M.request_url(provider)
+    local url = provider.url
+    if provider.authType ~= "token-auth" or not provider.authToken then
+        return url
+    end
+
+    local token = provider.authToken
+    local base, query = url:match("^([^?]*)(.*)$")
+    if url:find("{token}", 1, true) then
+        -- The replacements are escaped for gsub, which treats % specially
+        base = base:gsub("{token}", (M.path_escape(token):gsub("%%", "%%%%")))
+        query = query:gsub("{token}", (M.query_escape(token):gsub("%%", "%%%%")))
+        return base .. query
+    end
+    -- Slashes, commas and semicolons of the token are kept, as in the path of a Go URL
+    return base .. "/" .. escape(token, "%$&%+:=@/,;") .. query
+end
+
 -- Планировщик для вызова reload_providers
 function M.schedule_reload_providers(url, fallbackLocalConfig)
     local ok, err = ngx.timer.at(0, M.reload_providers, url, fallbackLocalConfig)